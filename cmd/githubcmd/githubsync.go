@@ -2,6 +2,7 @@ package githubcmd
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"strconv"
@@ -14,30 +15,47 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/liweiyi88/trendshift-backend/config"
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/github"
-	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/global"
 	"github.com/liweiyi88/trendshift-backend/model/opt"
+	"github.com/liweiyi88/trendshift-backend/tagging"
 	"github.com/spf13/cobra"
 )
 
 var start string
 var end string
 var limit int
+var failureThreshold float64
+var resume bool
+var dryRun bool
+var format string
 
-// If run as cronjob, a suggested command to avoid sending too many requests to GitHub is
-// `sync [repository|developer] --end=-2d --limit=500` and run it hourly.
+// If run as cronjob, a suggested command is `sync [repository|developer]` run hourly, each entity is synced
+// when its priority schedule is due, see `sync plan`. --start and --end select the entities by their last update instead.
 func init() {
+	GitHubSyncCmd.AddCommand(syncPlanCmd)
+
 	GitHubSyncCmd.Flags().StringVarP(&start, "start", "s", "", "--start \"2023-01-06 14:35:00\" ")
 	GitHubSyncCmd.Flags().StringVarP(&end, "end", "e", "", "--end \"2023-10-06 14:35:00\", --end=-2d or --end=2h, `d` for days, `h` for hours ")
 	GitHubSyncCmd.Flags().IntVarP(&limit, "limit", "l", 0, "--limit=100")
+	GitHubSyncCmd.Flags().BoolVar(&resume, "resume", false, "--resume, continue the last interrupted sync instead of starting over")
+	GitHubSyncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "--dry-run, fetch from GitHub and print what would change without writing")
+	GitHubSyncCmd.Flags().StringVar(&format, "format", dryrun.FormatTable, "--format=json, output of --dry-run, table or json")
+	GitHubSyncCmd.Flags().Float64Var(&failureThreshold, "failure-threshold", 0.1, "--failure-threshold=0.1, exit with an error when the ratio of failed items is above it")
 }
 
 var GitHubSyncCmd = &cobra.Command{
 	Use:   "sync [repository|developer]",
 	Short: "Sync the latest repositories or developers details from GitHub",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := dryrun.ValidateFormat(format); err != nil {
+			return err
+		}
+
 		config.Init()
+		cmd.SilenceUsage = true
 
 		action := args[0]
 		ctx, stop := context.WithCancel(context.Background())
@@ -62,7 +80,7 @@ var GitHubSyncCmd = &cobra.Command{
 			if err != nil {
 				slog.Error("failed to parse start time", slog.Any("error", err))
 				sentry.CaptureException(err)
-				return
+				return err
 			}
 		}
 
@@ -70,7 +88,7 @@ var GitHubSyncCmd = &cobra.Command{
 		if err != nil {
 			slog.Error("failed to parse end date time", slog.Any("error", err))
 			sentry.CaptureException(err)
-			return
+			return err
 		}
 
 		appSignal := make(chan os.Signal, 3)
@@ -81,18 +99,72 @@ var GitHubSyncCmd = &cobra.Command{
 			stop()
 		}()
 
-		repositoryRepo := model.NewGhRepositoryRepo(db)
-		developerRepo := model.NewDeveloperRepo(db)
-		handler := github.NewSyncHandler(repositoryRepo, developerRepo, gh)
-		err = handler.Handle(ctx, action, opt.Start(start), opt.End(endDateTime), opt.Limit(limit))
+		handler := newSyncHandler(db, gh)
+
+		if dryRun {
+			report := dryrun.NewReport()
+
+			if err := handler.DryRun(ctx, action, report, opt.Start(start), opt.End(endDateTime), opt.Limit(limit)); err != nil {
+				slog.Error("failed to dry run sync action", slog.Any("error", err))
+				return err
+			}
+
+			return report.Write(cmd.OutOrStdout(), format)
+		}
+
+		summary, err := handler.Handle(ctx, action, resume, opt.Start(start), opt.End(endDateTime), opt.Limit(limit))
 
 		if err != nil {
 			slog.Error("failed to handle sync action", slog.Any("error", err))
 			sentry.CaptureException(err)
+			return err
+		}
+
+		slog.Info(
+			"sync completed",
+			slog.String("action", action),
+			slog.Int64("run_id", summary.RunId),
+			slog.Int("resumed_at", summary.ResumedAt),
+			slog.Int("candidates", summary.Candidates),
+			slog.Int("backing_off", summary.BackingOff),
+			slog.Int("rechecked", summary.Rechecked),
+			slog.Int("synced", summary.Synced),
+			slog.Int("deferred", summary.Deferred),
+			slog.Int("failed", summary.Failed),
+		)
+
+		// Some items always fail, e.g. GitHub times out on huge repositories, only a failure rate above the threshold fails the run.
+		if rate := summary.FailureRate(); rate > failureThreshold {
+			err := fmt.Errorf("%d of %d %s syncs failed, the failure rate %.2f is above the threshold %.2f", summary.Failed, summary.Candidates+summary.Rechecked-summary.BackingOff, action, rate, failureThreshold)
+			sentry.CaptureException(err)
+			return err
 		}
+
+		return nil
 	},
 }
 
+func newSyncHandler(db database.DB, gh *github.Client) *github.SyncHandler {
+	repositories := global.InitRepositories(db)
+	tagger := tagging.NewTagger(tagging.NewClassifier(tagging.DefaultRules), repositories.TagRepo, repositories.GhRepositoryRepo)
+
+	return github.NewSyncHandler(
+		repositories.GhRepositoryRepo,
+		repositories.DeveloperRepo,
+		repositories.RepositorySnapshotRepo,
+		repositories.DeveloperSnapshotRepo,
+		repositories.DeveloperProfileRepo,
+		repositories.RepositoryContributorRepo,
+		repositories.OrganizationRepo,
+		repositories.JobRepo,
+		repositories.SyncFailureRepo,
+		repositories.SyncRunRepo,
+		repositories.EntityStatusRepo,
+		tagger,
+		gh,
+	)
+}
+
 func parseEndDateTimeOption(end string) (string, error) {
 	end = strings.TrimSpace(end)
 
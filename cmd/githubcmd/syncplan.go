@@ -0,0 +1,99 @@
+package githubcmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/config"
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/github"
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/spf13/cobra"
+)
+
+var planLimit int
+var dueOnly bool
+
+func init() {
+	syncPlanCmd.Flags().IntVarP(&planLimit, "limit", "l", 50, "--limit=50, number of entities to print, 0 prints all of them")
+	syncPlanCmd.Flags().BoolVar(&dueOnly, "due", false, "--due, only print the entities the next sync refreshes")
+}
+
+// Print the sync schedule without syncing anything.
+var syncPlanCmd = &cobra.Command{
+	Use:       "plan [repository|developer]",
+	Short:     "Print the priority and next sync of the repositories or developers, nothing is synced",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{model.EntityRepository, model.EntityDeveloper},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config.Init()
+
+		ctx := context.Background()
+		db := database.GetInstance(ctx)
+		defer db.Close()
+
+		handler := newSyncHandler(db, github.NewClient(github.NewTokenPool(config.GitHubTokens)))
+
+		plan, err := handler.Plan(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		tiers := make(map[string]int)
+		due := 0
+
+		for _, candidate := range plan {
+			tiers[candidate.Tier]++
+
+			if candidate.Due(now) {
+				due++
+			}
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tTIER\tSCORE\tTRENDING DAYS\tSTARS\tPAGE VIEWS\tLAST SYNCED\tDUE AT")
+
+		printed := 0
+
+		for _, candidate := range plan {
+			if planLimit > 0 && printed >= planLimit {
+				break
+			}
+
+			if dueOnly && !candidate.Due(now) {
+				continue
+			}
+
+			dueAt := candidate.DueAt.Format(time.DateTime)
+			if candidate.Due(now) {
+				dueAt = "now"
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\t%.2f\t%d\t%d\t%d\t%s\t%s\n",
+				candidate.Id,
+				candidate.Name,
+				candidate.Tier,
+				candidate.Score,
+				candidate.TrendingDays,
+				candidate.Stars,
+				candidate.PageViews,
+				candidate.LastSyncedAt.Format(time.DateTime),
+				dueAt,
+			)
+
+			printed++
+		}
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%d %ss: %d hot, %d warm, %d cold, %d due now\n",
+			len(plan), args[0], tiers[model.SyncTierHot], tiers[model.SyncTierWarm], tiers[model.SyncTierCold], due)
+
+		return nil
+	},
+}
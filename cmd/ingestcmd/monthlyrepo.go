@@ -2,7 +2,6 @@ package ingestcmd
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -50,30 +49,15 @@ var ingestMonthlyRepositoryDataCmd = &cobra.Command{
 		gh := github.NewClient(tokenPool)
 
 		rmr := model.NewRepositoryMonthlyInsightRepo(db)
-		ingestor := ingestion.NewMonthlyRepoDataIngestor(rmr, gh)
+		ingestor := ingestion.NewMonthlyRepoDataIngestor(rmr, model.NewJobRepo(db), gh)
 
 		for {
 			now := time.Now()
 			done, err := ingestor.Ingest(ctx, int(now.Month()), now.Year())
 
+			// Rate limited jobs are deferred by the queue, other errors let the command fail.
 			if err != nil {
-				if errors.Is(err, github.ErrTokenNotAvailable) {
-					earliestResetAt := tokenPool.EarliestReset()
-
-					slog.Warn("no GitHub tokens available, sleeping until earliest reset", slog.Time("reset_at", earliestResetAt))
-					sleepDuration := time.Until(earliestResetAt)
-					if sleepDuration > 0 {
-						slog.Info("sleeping until tokens reset", slog.Duration("sleep", sleepDuration))
-						if err := datetime.SleepWithContext(ctx, sleepDuration); err != nil {
-							return err
-						}
-					}
-				} else if errors.Is(err, github.ErrTooManyRequests) {
-					slog.Warn("fetching repository monthly data with a github token was throttled.")
-				} else {
-					// Unhandled error, return and let command failed
-					return err
-				}
+				return err
 			}
 
 			if done {
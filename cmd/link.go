@@ -12,6 +12,7 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/liweiyi88/trendshift-backend/config"
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/github"
 	"github.com/liweiyi88/trendshift-backend/global"
 	"github.com/liweiyi88/trendshift-backend/search"
@@ -19,7 +20,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var linkDryRun bool
+var linkFormat string
+
 func init() {
+	linkCmd.Flags().BoolVar(&linkDryRun, "dry-run", false, "--dry-run, fetch from GitHub and print what would change without writing")
+	linkCmd.Flags().StringVar(&linkFormat, "format", dryrun.FormatTable, "--format=json, output of --dry-run, table or json")
 	rootCmd.AddCommand(linkCmd)
 }
 
@@ -28,6 +34,11 @@ var linkCmd = &cobra.Command{
 	Short: "Link trending repositories or developers with GitHub repositories or developers",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := dryrun.ValidateFormat(linkFormat); err != nil {
+			slog.Error("invalid format", slog.Any("error", err))
+			return
+		}
+
 		config.Init()
 
 		action := args[0]
@@ -57,14 +68,38 @@ var linkCmd = &cobra.Command{
 			stop()
 		}()
 
-		slog.Info("linking...")
-
 		repositories := global.InitRepositories(db)
 		search := search.NewSearch()
 		githubFetcher := trending.NewGithubFetcher(gh, search, *repositories)
 
 		var err error
 
+		if linkDryRun {
+			report := dryrun.NewReport()
+
+			switch action {
+			case "repository":
+				err = githubFetcher.PreviewRepositories(ctx, report)
+			case "developer":
+				err = githubFetcher.PreviewDevelopers(ctx, report)
+			default:
+				slog.Error("invalid action, expected repository or developer")
+				return
+			}
+
+			if err == nil {
+				err = report.Write(cmd.OutOrStdout(), linkFormat)
+			}
+
+			if err != nil {
+				slog.Error("failed to dry run link action", slog.Any("error", err))
+			}
+
+			return
+		}
+
+		slog.Info("linking...")
+
 		switch action {
 		case "repository":
 			err = githubFetcher.FetchRepositories(ctx)
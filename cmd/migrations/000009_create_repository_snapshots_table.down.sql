@@ -0,0 +1 @@
+DROP TABLE repository_snapshots;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE repository_snapshots (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `repository_id` INT NOT NULL,
+    `stars` INT NOT NULL,
+    `forks` INT NOT NULL,
+    `created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    KEY `IDX_RSNAPSHOT_REPOSITORY_CREATED` (`repository_id`, `created_at`),
+    KEY `IDX_RSNAPSHOT_CREATED` (`created_at`),
+    CONSTRAINT `FK_QKRWNZLBTSPEXMAD` FOREIGN KEY (`repository_id`) REFERENCES `repositories` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+DROP TABLE developer_snapshots;
\ No newline at end of file
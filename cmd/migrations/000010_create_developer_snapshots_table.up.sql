@@ -0,0 +1,10 @@
+CREATE TABLE developer_snapshots (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `developer_id` INT NOT NULL,
+    `followers` INT NOT NULL,
+    `public_repos` INT NOT NULL,
+    `created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    KEY `IDX_DSNAPSHOT_DEVELOPER_CREATED` (`developer_id`, `created_at`),
+    CONSTRAINT `FK_HVBTMEYQZRLWCKUA` FOREIGN KEY (`developer_id`) REFERENCES `developers` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
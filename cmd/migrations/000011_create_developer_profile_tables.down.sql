@@ -0,0 +1,4 @@
+DROP TABLE developer_contributions;
+DROP TABLE developer_languages;
+DROP TABLE developer_owned_repositories;
+DROP TABLE developer_profiles;
\ No newline at end of file
@@ -0,0 +1,39 @@
+CREATE TABLE developer_profiles (
+    `developer_id` INT NOT NULL,
+    `total_stars` INT NOT NULL,
+    `created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    `updated_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    CONSTRAINT `FK_NDWQJTAOVKGHPLEB` FOREIGN KEY (`developer_id`) REFERENCES `developers` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`developer_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE developer_owned_repositories (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `developer_id` INT NOT NULL,
+    `full_name` varchar(255) NOT NULL,
+    `description` TEXT DEFAULT NULL,
+    `stars` INT NOT NULL,
+    `forks` INT NOT NULL,
+    `language` varchar(255) DEFAULT NULL,
+    KEY `IDX_DOREPO_DEVELOPER` (`developer_id`),
+    KEY `IDX_DOREPO_FULL_NAME` (`full_name`),
+    CONSTRAINT `FK_RZKMCEXUWAQBYSHT` FOREIGN KEY (`developer_id`) REFERENCES `developers` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE developer_languages (
+    `developer_id` INT NOT NULL,
+    `language` varchar(255) NOT NULL,
+    `repositories` INT NOT NULL,
+    `stars` INT NOT NULL,
+    CONSTRAINT `FK_PWYEMHDGUCFXTRLA` FOREIGN KEY (`developer_id`) REFERENCES `developers` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`developer_id`, `language`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE developer_contributions (
+    `developer_id` INT NOT NULL,
+    `year` INT NOT NULL,
+    `contributions` INT NOT NULL,
+    CONSTRAINT `FK_JSLVGBQXEONMUYCW` FOREIGN KEY (`developer_id`) REFERENCES `developers` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`developer_id`, `year`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
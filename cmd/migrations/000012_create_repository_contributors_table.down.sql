@@ -0,0 +1 @@
+DROP TABLE repository_contributors;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE repository_contributors (
+    `repository_id` INT NOT NULL,
+    `developer_id` INT NOT NULL,
+    `contributions` INT NOT NULL,
+    `position` INT NOT NULL,
+    `updated_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    KEY `IDX_RCONTRIBUTOR_DEVELOPER` (`developer_id`),
+    CONSTRAINT `FK_GMXTKWDAQEPZVRJN` FOREIGN KEY (`repository_id`) REFERENCES `repositories` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `FK_YBUCLSHFONWEQIKT` FOREIGN KEY (`developer_id`) REFERENCES `developers` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`repository_id`, `developer_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
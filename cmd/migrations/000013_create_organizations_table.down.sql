@@ -0,0 +1,6 @@
+ALTER TABLE repositories
+DROP FOREIGN KEY `FK_NKOWSQZHRJTAVBLE`,
+DROP KEY `IDX_REPOSITORY_ORGANIZATION`,
+DROP COLUMN `organization_id`;
+
+DROP TABLE organizations;
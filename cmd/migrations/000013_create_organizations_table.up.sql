@@ -0,0 +1,25 @@
+CREATE TABLE organizations (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `gh_id` INT NOT NULL,
+    `login` varchar(255) NOT NULL,
+    `avatar_url` varchar(255) NOT NULL,
+    `name` varchar(255) DEFAULT NULL,
+    `description` TEXT DEFAULT NULL,
+    `blog` varchar(255) DEFAULT NULL,
+    `location` varchar(255) DEFAULT NULL,
+    `email` varchar(255) DEFAULT NULL,
+    `twitter_username` varchar(255) DEFAULT NULL,
+    `is_verified` TINYINT(1) NOT NULL DEFAULT 0,
+    `public_repos` INT NOT NULL,
+    `followers` INT NOT NULL,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE (`gh_id`),
+    UNIQUE (`login`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE repositories
+ADD `organization_id` INT DEFAULT NULL,
+ADD KEY `IDX_REPOSITORY_ORGANIZATION` (`organization_id`),
+ADD CONSTRAINT `FK_NKOWSQZHRJTAVBLE` FOREIGN KEY (`organization_id`) REFERENCES `organizations` (`id`) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE repositories_tags
+DROP COLUMN `source`;
+
+DROP TABLE repository_topics;
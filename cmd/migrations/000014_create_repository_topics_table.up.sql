@@ -0,0 +1,10 @@
+CREATE TABLE repository_topics (
+    `repository_id` INT NOT NULL,
+    `topic` varchar(255) NOT NULL,
+    KEY `IDX_RTOPIC_TOPIC` (`topic`),
+    CONSTRAINT `FK_PDLWUQYRMZKSOTHE` FOREIGN KEY (`repository_id`) REFERENCES `repositories` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`repository_id`, `topic`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE repositories_tags
+ADD `source` ENUM('manual', 'auto') NOT NULL DEFAULT 'manual';
@@ -0,0 +1,6 @@
+DROP TABLE tag_aliases;
+
+ALTER TABLE tags
+DROP FOREIGN KEY `FK_VHZCRJOMQEKWYUAT`,
+DROP KEY `IDX_TAG_PARENT`,
+DROP COLUMN `parent_id`;
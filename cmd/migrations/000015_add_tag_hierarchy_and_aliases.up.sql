@@ -0,0 +1,14 @@
+ALTER TABLE tags
+ADD `parent_id` INT DEFAULT NULL,
+ADD KEY `IDX_TAG_PARENT` (`parent_id`),
+ADD CONSTRAINT `FK_VHZCRJOMQEKWYUAT` FOREIGN KEY (`parent_id`) REFERENCES `tags` (`id`) ON DELETE SET NULL;
+
+CREATE TABLE tag_aliases (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `tag_id` INT NOT NULL,
+    `name` varchar(255) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE (`name`),
+    KEY `IDX_TAG_ALIAS_TAG` (`tag_id`),
+    CONSTRAINT `FK_LQXBNRDEWPZCGAFS` FOREIGN KEY (`tag_id`) REFERENCES `tags` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,4 @@
+ALTER TABLE tags
+DROP COLUMN `description`,
+DROP COLUMN `color`,
+DROP COLUMN `icon`;
@@ -0,0 +1,4 @@
+ALTER TABLE tags
+ADD `description` TEXT DEFAULT NULL,
+ADD `color` varchar(7) DEFAULT NULL,
+ADD `icon` varchar(255) DEFAULT NULL;
@@ -0,0 +1 @@
+DROP TABLE repository_similarities;
\ No newline at end of file
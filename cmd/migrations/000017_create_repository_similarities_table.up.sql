@@ -0,0 +1,14 @@
+CREATE TABLE repository_similarities (
+    `repository_id` INT NOT NULL,
+    `related_repository_id` INT NOT NULL,
+    `score` DOUBLE NOT NULL,
+    `shared_tags` INT NOT NULL DEFAULT 0,
+    `shared_topics` INT NOT NULL DEFAULT 0,
+    `same_language` TINYINT(1) NOT NULL DEFAULT 0,
+    `co_trending` INT NOT NULL DEFAULT 0,
+    `updated_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    KEY `IDX_RSIMILARITY_SCORE` (`repository_id`, `score`),
+    CONSTRAINT `FK_QHZNTRWVKEMAPLXC` FOREIGN KEY (`repository_id`) REFERENCES `repositories` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `FK_JVUDBSYOIGFCNWKE` FOREIGN KEY (`related_repository_id`) REFERENCES `repositories` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`repository_id`, `related_repository_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
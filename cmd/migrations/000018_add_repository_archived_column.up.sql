@@ -0,0 +1,2 @@
+ALTER TABLE repositories
+ADD `archived` TINYINT(1) NOT NULL DEFAULT 0;
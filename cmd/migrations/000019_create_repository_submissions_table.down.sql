@@ -0,0 +1 @@
+DROP TABLE repository_submissions;
\ No newline at end of file
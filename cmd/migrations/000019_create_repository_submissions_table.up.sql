@@ -0,0 +1,18 @@
+CREATE TABLE repository_submissions (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `full_name` VARCHAR(255) NOT NULL,
+    `status` VARCHAR(20) NOT NULL DEFAULT 'pending',
+    `submitted_by` INT DEFAULT NULL,
+    `reviewed_by` INT DEFAULT NULL,
+    `review_note` VARCHAR(255) DEFAULT NULL,
+    `repository_id` INT DEFAULT NULL,
+    `reviewed_at` DATETIME(3) DEFAULT NULL,
+    `created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    `updated_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    KEY `IDX_RSUBMISSION_FULL_NAME_STATUS` (`full_name`, `status`),
+    KEY `IDX_RSUBMISSION_STATUS` (`status`, `created_at`),
+    CONSTRAINT `FK_PWRKXDMUQYBZHLTE` FOREIGN KEY (`submitted_by`) REFERENCES `users` (`id`) ON DELETE SET NULL,
+    CONSTRAINT `FK_NBGQVFJYSWAKCOID` FOREIGN KEY (`reviewed_by`) REFERENCES `users` (`id`) ON DELETE SET NULL,
+    CONSTRAINT `FK_TMELXRCZHUGVYKAP` FOREIGN KEY (`repository_id`) REFERENCES `repositories` (`id`) ON DELETE SET NULL,
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
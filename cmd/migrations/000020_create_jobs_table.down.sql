@@ -0,0 +1 @@
+DROP TABLE jobs;
\ No newline at end of file
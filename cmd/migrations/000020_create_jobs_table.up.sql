@@ -0,0 +1,18 @@
+CREATE TABLE jobs (
+    `id` BIGINT NOT NULL AUTO_INCREMENT,
+    `kind` VARCHAR(50) NOT NULL,
+    `payload` VARCHAR(255) NOT NULL,
+    `status` VARCHAR(20) NOT NULL DEFAULT 'pending',
+    `attempts` INT NOT NULL DEFAULT 0,
+    `max_attempts` INT NOT NULL DEFAULT 5,
+    `last_error` TEXT DEFAULT NULL,
+    `run_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    `locked_at` DATETIME(3) DEFAULT NULL,
+    `created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    `updated_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    -- Only one pending or running job per kind and payload, dead jobs are kept aside.
+    `active_key` VARCHAR(310) GENERATED ALWAYS AS (IF(`status` = 'dead', NULL, CONCAT(`kind`, ':', `payload`))) STORED,
+    UNIQUE KEY `UNIQ_JOBS_ACTIVE_KEY` (`active_key`),
+    KEY `IDX_JOBS_CLAIM` (`kind`, `status`, `run_at`),
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+DROP TABLE sync_failures;
\ No newline at end of file
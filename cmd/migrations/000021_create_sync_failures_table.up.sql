@@ -0,0 +1,10 @@
+CREATE TABLE sync_failures (
+    `entity_type` VARCHAR(20) NOT NULL,
+    `entity_id` INT NOT NULL,
+    `failures` INT NOT NULL DEFAULT 0,
+    `last_error` TEXT DEFAULT NULL,
+    `last_failed_at` DATETIME(3) NOT NULL,
+    `retry_after` DATETIME(3) NOT NULL,
+    KEY `IDX_SYNC_FAILURES_RETRY_AFTER` (`entity_type`, `retry_after`),
+    PRIMARY KEY (`entity_type`, `entity_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
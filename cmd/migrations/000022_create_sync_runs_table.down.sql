@@ -0,0 +1 @@
+DROP TABLE sync_runs;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE sync_runs (
+    `id` BIGINT NOT NULL AUTO_INCREMENT,
+    `action` VARCHAR(20) NOT NULL,
+    `status` VARCHAR(20) NOT NULL,
+    `last_id` INT NOT NULL DEFAULT 0,
+    `started_at` DATETIME(3) NOT NULL,
+    `updated_at` DATETIME(3) NOT NULL,
+    `finished_at` DATETIME(3) DEFAULT NULL,
+    KEY `IDX_SYNC_RUNS_ACTION_STATUS` (`action`, `status`),
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
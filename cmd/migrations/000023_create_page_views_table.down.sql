@@ -0,0 +1,7 @@
+ALTER TABLE trending_developers
+DROP KEY `IDX_TRENDING_DEVELOPER_DATE`;
+
+ALTER TABLE trending_repositories
+DROP KEY `IDX_TRENDING_REPOSITORY_DATE`;
+
+DROP TABLE page_views;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE page_views (
+    `entity_type` VARCHAR(20) NOT NULL,
+    `entity_id` INT NOT NULL,
+    `view_date` DATE NOT NULL,
+    `views` INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`entity_type`, `entity_id`, `view_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE trending_repositories
+ADD KEY `IDX_TRENDING_REPOSITORY_DATE` (`repository_id`, `trend_date`);
+
+ALTER TABLE trending_developers
+ADD KEY `IDX_TRENDING_DEVELOPER_DATE` (`developer_id`, `trend_date`);
@@ -0,0 +1 @@
+DROP TABLE entity_statuses;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE entity_statuses (
+    `entity_type` VARCHAR(20) NOT NULL,
+    `entity_id` INT NOT NULL,
+    `status` VARCHAR(20) NOT NULL,
+    `reason` TEXT DEFAULT NULL,
+    `changed_at` DATETIME(3) NOT NULL,
+    `checks` INT NOT NULL DEFAULT 0,
+    `recheck_at` DATETIME(3) DEFAULT NULL,
+    KEY `IDX_ENTITY_STATUSES_RECHECK_AT` (`entity_type`, `recheck_at`),
+    KEY `IDX_ENTITY_STATUSES_STATUS` (`entity_type`, `status`),
+    PRIMARY KEY (`entity_type`, `entity_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- The reason of the entities skipped so far is unknown, they are rechecked by the next sync.
+INSERT INTO entity_statuses (`entity_type`, `entity_id`, `status`, `reason`, `changed_at`, `checks`, `recheck_at`)
+SELECT 'repository', `id`, 'not_found', 'skipped before statuses were recorded', NOW(3), 0, NOW(3) FROM repositories WHERE `skipped` = true;
+
+INSERT INTO entity_statuses (`entity_type`, `entity_id`, `status`, `reason`, `changed_at`, `checks`, `recheck_at`)
+SELECT 'developer', `id`, 'not_found', 'skipped before statuses were recorded', NOW(3), 0, NOW(3) FROM developers WHERE `skipped` = true;
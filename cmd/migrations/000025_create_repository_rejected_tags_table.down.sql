@@ -0,0 +1 @@
+DROP TABLE repository_rejected_tags;
\ No newline at end of file
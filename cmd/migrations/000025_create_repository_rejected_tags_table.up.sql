@@ -0,0 +1,9 @@
+CREATE TABLE repository_rejected_tags (
+    `repository_id` INT NOT NULL,
+    `tag_id` INT NOT NULL,
+    `rejected_at` DATETIME NOT NULL,
+    KEY `IDX_RREJECTED_TAG` (`tag_id`),
+    CONSTRAINT `FK_RREJECTED_REPOSITORY` FOREIGN KEY (`repository_id`) REFERENCES `repositories` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `FK_RREJECTED_TAG` FOREIGN KEY (`tag_id`) REFERENCES `tags` (`id`) ON DELETE CASCADE,
+    PRIMARY KEY (`repository_id`, `tag_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+ALTER TABLE developer_profiles
+DROP COLUMN `total_repositories`;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE developer_profiles
+ADD `total_repositories` INT NOT NULL DEFAULT 0 AFTER `total_stars`;
@@ -0,0 +1,100 @@
+package queuecmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"github.com/liweiyi88/trendshift-backend/config"
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/spf13/cobra"
+)
+
+var kind string
+var limit int
+
+// Kinds of jobs accepted by the kind flag.
+var kinds = []string{
+	model.JobFetchRepository,
+	model.JobFetchDeveloper,
+	model.JobSyncRepository,
+	model.JobSyncDeveloper,
+	model.JobIngestMonthlyInsight,
+}
+
+func init() {
+	deadCmd.Flags().StringVarP(&kind, "kind", "k", "", "--kind=fetch_repository")
+	deadCmd.Flags().IntVarP(&limit, "limit", "l", 100, "--limit=100")
+}
+
+var deadCmd = &cobra.Command{
+	Use:   "dead",
+	Short: "List the jobs in the dead letter",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if kind != "" && !slices.Contains(kinds, kind) {
+			return fmt.Errorf("invalid kind, expected one of %v", kinds)
+		}
+
+		config.Init()
+
+		ctx := context.Background()
+		db := database.GetInstance(ctx)
+		defer db.Close()
+
+		jobs, err := model.NewJobRepo(db).FindDead(ctx, kind, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, job := range jobs {
+			slog.Info("dead job",
+				slog.Int64("id", job.Id),
+				slog.String("kind", job.Kind),
+				slog.String("payload", job.Payload),
+				slog.Int("attempts", job.Attempts),
+				slog.String("last_error", job.LastError.String),
+				slog.Time("failed_at", job.UpdatedAt))
+		}
+
+		slog.Info(fmt.Sprintf("found %d dead jobs", len(jobs)))
+		return nil
+	},
+}
+
+var retryCmd = &cobra.Command{
+	Use:   "retry [id]",
+	Short: "Move a job from the dead letter back to the queue",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid job id: %s", args[0])
+		}
+
+		config.Init()
+
+		ctx := context.Background()
+		db := database.GetInstance(ctx)
+		defer db.Close()
+
+		if err := model.NewJobRepo(db).Requeue(ctx, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("no dead job with id %d", id)
+			}
+
+			if errors.Is(err, model.ErrJobAlreadyActive) {
+				return fmt.Errorf("job %d is not requeued, %w", id, err)
+			}
+
+			return err
+		}
+
+		slog.Info("job requeued", slog.Int64("id", id))
+		return nil
+	},
+}
@@ -0,0 +1,16 @@
+package queuecmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	QueueCmd.AddCommand(workCmd)
+	QueueCmd.AddCommand(deadCmd)
+	QueueCmd.AddCommand(retryCmd)
+}
+
+var QueueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Run and inspect the GitHub fetch jobs queue",
+}
@@ -0,0 +1,89 @@
+package queuecmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/liweiyi88/trendshift-backend/config"
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/github"
+	"github.com/liweiyi88/trendshift-backend/global"
+	"github.com/liweiyi88/trendshift-backend/ingestion"
+	"github.com/liweiyi88/trendshift-backend/queue"
+	"github.com/liweiyi88/trendshift-backend/search"
+	"github.com/liweiyi88/trendshift-backend/tagging"
+	"github.com/liweiyi88/trendshift-backend/trending"
+	"github.com/spf13/cobra"
+)
+
+var workers int
+
+func init() {
+	workCmd.Flags().IntVarP(&workers, "workers", "w", queue.DefaultOptions.Workers, "--workers=4")
+}
+
+var workCmd = &cobra.Command{
+	Use:   "work",
+	Short: "Run fetch, sync and ingestion jobs until the process is stopped",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config.Init()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		db := database.GetInstance(ctx)
+
+		defer func() {
+			if err := db.Close(); err != nil {
+				slog.Error("failed to close db", slog.Any("error", err))
+				sentry.CaptureException(err)
+			}
+
+			stop()
+			sentry.Flush(2 * time.Second)
+		}()
+
+		gh := github.NewClient(github.NewTokenPool(config.GitHubTokens))
+		repositories := global.InitRepositories(db)
+
+		tagger := tagging.NewTagger(tagging.NewClassifier(tagging.DefaultRules), repositories.TagRepo, repositories.GhRepositoryRepo)
+		syncHandler := github.NewSyncHandler(
+			repositories.GhRepositoryRepo,
+			repositories.DeveloperRepo,
+			repositories.RepositorySnapshotRepo,
+			repositories.DeveloperSnapshotRepo,
+			repositories.DeveloperProfileRepo,
+			repositories.RepositoryContributorRepo,
+			repositories.OrganizationRepo,
+			repositories.JobRepo,
+			repositories.SyncFailureRepo,
+			repositories.SyncRunRepo,
+			repositories.EntityStatusRepo,
+			tagger,
+			gh,
+		)
+
+		options := queue.DefaultOptions
+		options.Workers = workers
+
+		q := queue.New(repositories.JobRepo, options)
+		trending.NewGithubFetcher(gh, search.NewSearch(), *repositories).Register(q)
+		syncHandler.Register(q)
+		ingestion.NewMonthlyRepoDataIngestor(repositories.RepositoryMonthlyInsightRepo, repositories.JobRepo, gh).Register(q)
+
+		slog.Info("working...", slog.Int("workers", options.Workers))
+
+		if err := q.Work(ctx); err != nil {
+			sentry.CaptureException(err)
+			return err
+		}
+
+		stats := q.Stats()
+		slog.Info("worker stopped", slog.Int("done", stats.Done), slog.Int("retried", stats.Retried), slog.Int("dead", stats.Dead))
+
+		return nil
+	},
+}
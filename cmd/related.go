@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"log/slog"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/liweiyi88/trendshift-backend/config"
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/similarity"
+	"github.com/spf13/cobra"
+)
+
+var relatedRange int
+var relatedLimit int
+
+// Suggested to run daily as a cronjob, e.g. `related --range=365 --limit=10`.
+func init() {
+	relatedCmd.Flags().IntVarP(&relatedRange, "range", "r", 365, "--range=365, number of days of trending pages used to compute co-trending")
+	relatedCmd.Flags().IntVarP(&relatedLimit, "limit", "l", 10, "--limit=10, number of related repositories kept per repository")
+	rootCmd.AddCommand(relatedCmd)
+}
+
+var relatedCmd = &cobra.Command{
+	Use:   "related",
+	Short: "Precompute the related repositories of every repository",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config.Init()
+
+		ctx, stop := context.WithCancel(context.Background())
+		db := database.GetInstance(ctx)
+
+		defer func() {
+			err := db.Close()
+
+			if err != nil {
+				slog.Error("failed to close db", slog.Any("error", err))
+				sentry.CaptureException(err)
+			}
+
+			stop()
+			sentry.Flush(2 * time.Second)
+		}()
+
+		appSignal := make(chan os.Signal, 3)
+		signal.Notify(appSignal, os.Interrupt, syscall.SIGTERM)
+
+		go func() {
+			<-appSignal
+			stop()
+		}()
+
+		builder := similarity.NewBuilder(similarity.NewScorer(similarity.DefaultWeights), model.NewRepositorySimilarityRepo(db))
+
+		saved, err := builder.Build(ctx, time.Now().AddDate(0, 0, -relatedRange), relatedLimit)
+		if err != nil {
+			slog.Error("failed to build related repositories", slog.Any("error", err))
+			sentry.CaptureException(err)
+			return
+		}
+
+		slog.Info(fmt.Sprintf("saved %d related repositories", saved))
+	},
+}
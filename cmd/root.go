@@ -6,6 +6,7 @@ import (
 
 	"github.com/liweiyi88/trendshift-backend/cmd/githubcmd"
 	"github.com/liweiyi88/trendshift-backend/cmd/ingestcmd"
+	"github.com/liweiyi88/trendshift-backend/cmd/queuecmd"
 	"github.com/liweiyi88/trendshift-backend/cmd/scrapecmd"
 	"github.com/liweiyi88/trendshift-backend/cmd/searchcmd"
 	"github.com/liweiyi88/trendshift-backend/cmd/usercmd"
@@ -20,6 +21,7 @@ func init() {
 	rootCmd.AddCommand(githubcmd.GitHubSyncCmd)
 	rootCmd.AddCommand(scrapecmd.ScrapeCmd)
 	rootCmd.AddCommand(ingestcmd.IngestCmd)
+	rootCmd.AddCommand(queuecmd.QueueCmd)
 }
 
 func Execute() {
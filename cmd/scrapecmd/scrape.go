@@ -14,6 +14,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/database/mysql"
 	"github.com/liweiyi88/trendshift-backend/config"
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/github"
 	"github.com/liweiyi88/trendshift-backend/global"
 	"github.com/liweiyi88/trendshift-backend/scrape"
@@ -21,12 +22,26 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var dryRun bool
+var format string
+
+func init() {
+	ScrapeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "--dry-run, scrape and fetch from GitHub then print what would change without writing")
+	ScrapeCmd.Flags().StringVar(&format, "format", dryrun.FormatTable, "--format=json, output of --dry-run, table or json")
+}
+
 var ScrapeCmd = &cobra.Command{
 	Use:   "scrape [repository|developer]",
 	Short: "Scrape trending repositories or trending developers form GitHub trending page.",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		action := args[0]
+
+		if err := dryrun.ValidateFormat(format); err != nil {
+			slog.Error("invalid format", slog.Any("error", err))
+			return
+		}
+
 		config.Init()
 
 		search := search.NewSearch()
@@ -58,6 +73,21 @@ var ScrapeCmd = &cobra.Command{
 			stop()
 		}()
 
+		if dryRun {
+			report := dryrun.NewReport()
+
+			err := handler.DryRun(ctx, action, report)
+			if err == nil {
+				err = report.Write(cmd.OutOrStdout(), format)
+			}
+
+			if err != nil {
+				slog.Error("failed to dry run action", slog.Any("error", err))
+			}
+
+			return
+		}
+
 		err := handler.Handle(ctx, action)
 		if err != nil {
 			slog.Error("failed to handle action", slog.Any("error", err))
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"log/slog"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/liweiyi88/trendshift-backend/config"
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/github"
+	"github.com/liweiyi88/trendshift-backend/global"
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/search"
+	"github.com/liweiyi88/trendshift-backend/tracking"
+	"github.com/liweiyi88/trendshift-backend/trending"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(trackCmd)
+}
+
+var trackCmd = &cobra.Command{
+	Use:   "track [owner/name]",
+	Short: "Track a repository which has never been on a trending page, the submission is approved at once",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config.Init()
+
+		fullName, err := model.ParseRepositoryName(args[0])
+		if err != nil {
+			slog.Error("invalid repository name, expected owner/name", slog.Any("error", err))
+			return
+		}
+
+		ctx, stop := context.WithCancel(context.Background())
+		db := database.GetInstance(ctx)
+
+		tokenPool := github.NewTokenPool(config.GitHubTokens)
+		gh := github.NewClient(tokenPool)
+
+		defer func() {
+			err := db.Close()
+
+			if err != nil {
+				slog.Error("failed to close db", slog.Any("error", err))
+				sentry.CaptureException(err)
+			}
+
+			stop()
+			sentry.Flush(2 * time.Second)
+		}()
+
+		appSignal := make(chan os.Signal, 3)
+		signal.Notify(appSignal, os.Interrupt, syscall.SIGTERM)
+
+		go func() {
+			<-appSignal
+			stop()
+		}()
+
+		repositories := global.InitRepositories(db)
+		githubFetcher := trending.NewGithubFetcher(gh, search.NewSearch(), *repositories)
+		tracker := tracking.NewTracker(gh, githubFetcher, *repositories)
+
+		submission, err := tracker.Track(ctx, fullName)
+		if err != nil {
+			slog.Error("failed to track repository", slog.String("repository", fullName), slog.Any("error", err))
+			sentry.CaptureException(err)
+			return
+		}
+
+		slog.Info("repository tracked", slog.String("repository", submission.FullName), slog.Int64("repository_id", submission.RepositoryId.Int64))
+	},
+}
@@ -21,7 +21,7 @@ var username, password, role string
 func init() {
 	UserCmd.Flags().StringVarP(&username, "username", "u", "", "username")
 	UserCmd.Flags().StringVarP(&password, "password", "p", "", "password")
-	UserCmd.Flags().StringVarP(&role, "role", "r", "user", "role")
+	UserCmd.Flags().StringVarP(&role, "role", "r", model.RoleUser, "roles, user or admin, comma separated")
 
 	UserCmd.MarkFlagRequired("username")
 	UserCmd.MarkFlagRequired("password")
@@ -33,6 +33,11 @@ var UserCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		config.Init()
 
+		roles, err := model.ParseRoles(role)
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		ctx, stop := context.WithCancel(context.Background())
 		db := database.GetInstance(ctx)
 
@@ -58,10 +63,10 @@ var UserCmd = &cobra.Command{
 
 		var user model.User
 		user.Username = username
-		user.Role = role
+		user.Role = roles
 		user.SetPassword(password)
 
-		_, err := repositories.UserRepo.Save(ctx, user)
+		_, err = repositories.UserRepo.Save(ctx, user)
 
 		if err != nil {
 			log.Fatalf("failed to save user: %v", err)
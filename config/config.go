@@ -23,6 +23,9 @@ var (
 	AlgoliasearchApiKey  string
 	MeilisearchMasterKey string
 	MeilisearchHost      string
+
+	// CIDRs of the load balancers in front of the API, X-Forwarded-For is only trusted from them.
+	TrustedProxies []string
 )
 
 var githubTokens string
@@ -44,6 +47,10 @@ func Init() {
 	AlgoliasearchAppId = os.Getenv("ALGOLIASEARCH_APPID")
 	AlgoliasearchApiKey = os.Getenv("ALGOLIASEARCH_APIKEY")
 
+	if trustedProxies := os.Getenv("TRUSTED_PROXIES"); trustedProxies != "" {
+		TrustedProxies = strings.Split(trustedProxies, ",")
+	}
+
 	err := sentry.Init(sentry.ClientOptions{
 		Dsn:              os.Getenv("SENTRY_DSN"),
 		AttachStacktrace: true,
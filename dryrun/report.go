@@ -0,0 +1,219 @@
+package dryrun
+
+import (
+	"cmp"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	Insert = "insert"
+	Update = "update"
+	Skip   = "skip"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+// Field is a column of a record as it is saved in the DB, or would be.
+type Field struct {
+	Name  string
+	Value any
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// Change is what a command would do to a record.
+type Change struct {
+	Action string        `json:"action"`
+	Entity string        `json:"entity"`
+	Key    string        `json:"key"` // the name of the record, e.g. the full name of a repository.
+	Fields []FieldChange `json:"fields,omitempty"`
+	Reason string        `json:"reason,omitempty"` // why a record is skipped.
+}
+
+type Summary struct {
+	Inserts int `json:"inserts"`
+	Updates int `json:"updates"`
+	Skips   int `json:"skips"`
+}
+
+// Report collects the changes of a dry run, it is safe for concurrent use.
+type Report struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+func NewReport() *Report {
+	return &Report{
+		changes: make([]Change, 0),
+	}
+}
+
+func ValidateFormat(format string) error {
+	if format != FormatTable && format != FormatJSON {
+		return fmt.Errorf("invalid format, expected table or json, passed %s", format)
+	}
+
+	return nil
+}
+
+// Returns the fields whose values differ, fields are matched by name.
+func Diff(old, new []Field) []FieldChange {
+	olds := make(map[string]any, len(old))
+	for _, field := range old {
+		olds[field.Name] = normalize(field.Value)
+	}
+
+	changes := make([]FieldChange, 0)
+
+	for _, field := range new {
+		value := normalize(field.Value)
+
+		if oldValue, ok := olds[field.Name]; !ok || !reflect.DeepEqual(oldValue, value) {
+			changes = append(changes, FieldChange{Field: field.Name, Old: olds[field.Name], New: value})
+		}
+	}
+
+	return changes
+}
+
+// Normalize DB types so values read from the DB compare equal to the values fetched from GitHub:
+// null types become their value or nil, times are compared to the second in UTC like the DATETIME columns.
+func normalize(value any) any {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return value
+		}
+
+		value = v
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.DateTime)
+	case int64:
+		return int(v)
+	default:
+		return v
+	}
+}
+
+// Report a record which would be inserted.
+func (r *Report) Insert(entity, key string, fields []Field) {
+	r.add(Change{Action: Insert, Entity: entity, Key: key, Fields: Diff(nil, fields)})
+}
+
+// Report a record which would be updated, it is reported as skipped when no field changes.
+func (r *Report) Update(entity, key string, old, new []Field) {
+	changes := Diff(old, new)
+
+	if len(changes) == 0 {
+		r.Skip(entity, key, "unchanged")
+		return
+	}
+
+	r.add(Change{Action: Update, Entity: entity, Key: key, Fields: changes})
+}
+
+func (r *Report) Skip(entity, key, reason string) {
+	r.add(Change{Action: Skip, Entity: entity, Key: key, Reason: reason})
+}
+
+func (r *Report) add(change Change) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes = append(r.changes, change)
+}
+
+// Returns the changes sorted by entity and key, records are reported concurrently.
+func (r *Report) Changes() []Change {
+	r.mu.Lock()
+	changes := slices.Clone(r.changes)
+	r.mu.Unlock()
+
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		return cmp.Or(cmp.Compare(a.Entity, b.Entity), cmp.Compare(a.Key, b.Key))
+	})
+
+	return changes
+}
+
+func (r *Report) Summary() Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var summary Summary
+
+	for _, change := range r.changes {
+		switch change.Action {
+		case Insert:
+			summary.Inserts++
+		case Update:
+			summary.Updates++
+		case Skip:
+			summary.Skips++
+		}
+	}
+
+	return summary
+}
+
+// Write the changes and the summary as a table or as JSON.
+func (r *Report) Write(w io.Writer, format string) error {
+	changes, summary := r.Changes(), r.Summary()
+
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(struct {
+			Changes []Change `json:"changes"`
+			Summary Summary  `json:"summary"`
+		}{changes, summary})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tENTITY\tKEY\tFIELD\tOLD\tNEW")
+
+	for _, change := range changes {
+		if change.Action == Skip {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t(%s)\t\t\n", change.Action, change.Entity, change.Key, change.Reason)
+			continue
+		}
+
+		for _, field := range change.Fields {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", change.Action, change.Entity, change.Key, field.Field, display(field.Old), display(field.New))
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d inserts, %d updates, %d skips\n", summary.Inserts, summary.Updates, summary.Skips)
+	return err
+}
+
+func display(value any) string {
+	if value == nil {
+		return "-"
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,74 @@
+package dryrun
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	old := []Field{
+		{"stars", 10},
+		{"description", dbutils.NewNullString("old")},
+		{"homepage", dbutils.NullString{}},
+		{"created_at", createdAt.In(time.FixedZone("AEST", 10*3600))},
+	}
+
+	new := []Field{
+		{"stars", 12},
+		{"description", dbutils.NewNullString("old")},
+		{"homepage", dbutils.NewNullString("https://example.com")},
+		{"created_at", createdAt},
+	}
+
+	assert.Equal(t, []FieldChange{
+		{Field: "stars", Old: 10, New: 12},
+		{Field: "homepage", Old: nil, New: "https://example.com"},
+	}, Diff(old, new))
+
+	assert.Empty(t, Diff(new, new))
+}
+
+func TestReport(t *testing.T) {
+	report := NewReport()
+
+	report.Update("repository", "b/b", []Field{{"stars", 1}}, []Field{{"stars", 2}})
+	report.Update("repository", "c/c", []Field{{"stars", 1}}, []Field{{"stars", 1}})
+	report.Insert("repository", "a/a", []Field{{"stars", 3}})
+	report.Skip("repository", "d/d", "not found on GitHub")
+
+	assert.Equal(t, Summary{Inserts: 1, Updates: 1, Skips: 2}, report.Summary())
+
+	changes := report.Changes()
+	assert.Equal(t, []string{"a/a", "b/b", "c/c", "d/d"}, []string{changes[0].Key, changes[1].Key, changes[2].Key, changes[3].Key})
+	assert.Equal(t, "unchanged", changes[2].Reason)
+
+	var table bytes.Buffer
+	assert.NoError(t, report.Write(&table, FormatTable))
+	assert.Contains(t, table.String(), "1 inserts, 1 updates, 2 skips")
+	assert.Contains(t, table.String(), "(not found on GitHub)")
+
+	var output bytes.Buffer
+	assert.NoError(t, report.Write(&output, FormatJSON))
+
+	var decoded struct {
+		Changes []Change `json:"changes"`
+		Summary Summary  `json:"summary"`
+	}
+
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &decoded))
+	assert.Len(t, decoded.Changes, 4)
+	assert.Equal(t, report.Summary(), decoded.Summary)
+}
+
+func TestValidateFormat(t *testing.T) {
+	assert.NoError(t, ValidateFormat(FormatTable))
+	assert.NoError(t, ValidateFormat(FormatJSON))
+	assert.Error(t, ValidateFormat("yaml"))
+}
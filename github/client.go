@@ -21,6 +21,13 @@ var ErrNotFound = errors.New("not found on GitHub")
 var ErrAccessBlocked = errors.New("repository access blocked")
 var ErrTooManyRequests = errors.New("too many requests")
 
+// A 403 that is neither a block nor a rate limit, e.g. the token lacks the permission or GitHub refuses a listing.
+var ErrForbidden = errors.New("forbidden by GitHub")
+
+// Blocks wrap ErrAccessBlocked, callers which do not care about the kind of block keep checking ErrAccessBlocked.
+var ErrDmcaBlocked = fmt.Errorf("%w: unavailable for legal reasons", ErrAccessBlocked)
+var ErrSuspended = fmt.Errorf("%w: disabled or suspended", ErrAccessBlocked)
+
 const GraphQLURL = "https://api.github.com/graphql"
 
 type Stargazer struct {
@@ -140,8 +147,10 @@ func checkGitHubResponse(res *http.Response, body []byte, context string) error
 		return nil
 	case http.StatusNotFound:
 		return ErrNotFound
-	case http.StatusUnavailableForLegalReasons, http.StatusForbidden:
-		return ErrAccessBlocked
+	case http.StatusUnavailableForLegalReasons:
+		return ErrDmcaBlocked
+	case http.StatusForbidden:
+		return forbiddenError(res, body)
 	case http.StatusTooManyRequests:
 		return ErrTooManyRequests
 	default:
@@ -149,6 +158,35 @@ func checkGitHubResponse(res *http.Response, body []byte, context string) error
 	}
 }
 
+// GitHub answers 403 for rate limits, blocked repositories and missing permissions alike,
+// tell them apart by the rate limit headers and the error body.
+func forbiddenError(res *http.Response, body []byte) error {
+	if res.Header.Get("X-Ratelimit-Remaining") == "0" || strings.TrimSpace(res.Header.Get("Retry-After")) != "" {
+		return ErrTooManyRequests
+	}
+
+	var githubError struct {
+		Message string `json:"message"`
+		Block   *struct {
+			Reason string `json:"reason"`
+		} `json:"block"`
+	}
+
+	_ = json.Unmarshal(body, &githubError)
+	message := strings.ToLower(githubError.Message)
+
+	switch {
+	case strings.Contains(message, "rate limit"):
+		return ErrTooManyRequests
+	case githubError.Block != nil && githubError.Block.Reason == "dmca":
+		return ErrDmcaBlocked
+	case githubError.Block != nil, strings.Contains(message, "access blocked"), strings.Contains(message, "suspended"):
+		return ErrSuspended
+	default:
+		return fmt.Errorf("%w: %s", ErrForbidden, githubError.Message)
+	}
+}
+
 type Client struct {
 	TokenPool *TokenPool
 }
@@ -210,6 +248,18 @@ func fetch[T any](
 		"after": cursor,
 	}
 
+	body, err := postGraphQL(ctx, query, variables, tokenPool)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slog.Debug("fetching repo monthly data", slog.String("repository", fmt.Sprintf("%s/%s", owner, repo)))
+
+	return extractEdges(body)
+}
+
+// Send a GraphQL query to GitHub and return the raw response body.
+func postGraphQL(ctx context.Context, query string, variables map[string]any, tokenPool *TokenPool) ([]byte, error) {
 	requestData := map[string]any{
 		"query":     query,
 		"variables": variables,
@@ -217,17 +267,17 @@ func fetch[T any](
 
 	bodyBytes, err := json.Marshal(requestData)
 	if err != nil {
-		return nil, nil, fmt.Errorf("[github graphql] failed to marshal request data, %v", requestData)
+		return nil, fmt.Errorf("[github graphql] failed to marshal request data, %v", requestData)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", GraphQLURL, bytes.NewBuffer(bodyBytes))
 	if err != nil {
-		return nil, nil, fmt.Errorf("[github graphql] failed to create new http request, error: %v", err)
+		return nil, fmt.Errorf("[github graphql] failed to create new http request, error: %v", err)
 	}
 
 	token, err := tokenPool.GetToken()
 	if err != nil {
-		return nil, nil, fmt.Errorf("[github graphql] failed to get token from token pool, error: %w", err)
+		return nil, fmt.Errorf("[github graphql] failed to get token from token pool, error: %w", err)
 	}
 
 	// Also allow to send request without token
@@ -241,7 +291,7 @@ func fetch[T any](
 	res, err := client.Do(req)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("[github graphql] failed to send graphql request, error: %v", err)
+		return nil, fmt.Errorf("[github graphql] failed to send graphql request, error: %v", err)
 	}
 
 	defer func() {
@@ -252,23 +302,21 @@ func fetch[T any](
 
 	if strings.TrimSpace(token) != "" {
 		if err := syncRateLimitData(token, tokenPool, res); err != nil {
-			return nil, nil, fmt.Errorf("sync rate limit data error: %w", err)
+			return nil, fmt.Errorf("sync rate limit data error: %w", err)
 		}
 	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read response body, error: %v", err)
+		return nil, fmt.Errorf("failed to read response body, error: %v", err)
 	}
 
 	err = checkGitHubResponse(res, body, "github graphql")
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	slog.Debug("fetching repo monthly data", slog.String("repository", fmt.Sprintf("%s/%s", owner, repo)))
-
-	return extractEdges(body)
+	return body, nil
 }
 
 func (ghClient *Client) GetRepositoryForks(
@@ -787,5 +835,18 @@ func (ghClient *Client) GetRepository(ctx context.Context, fullName string) (mod
 		return ghRepository, fmt.Errorf("failed to decode repository body: %v", err)
 	}
 
+	// The owner type is not part of our API responses, it is decoded on its own.
+	var owner struct {
+		Owner struct {
+			Type string `json:"type"`
+		} `json:"owner"`
+	}
+
+	if err := json.Unmarshal(body, &owner); err != nil {
+		return ghRepository, fmt.Errorf("failed to decode repository owner: %v", err)
+	}
+
+	ghRepository.Owner.Type = owner.Owner.Type
+
 	return ghRepository, checkGitHubResponse(res, body, "repository")
 }
@@ -2,6 +2,7 @@ package github
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/liweiyi88/trendshift-backend/model"
@@ -14,6 +15,37 @@ func TestParseNextLink(t *testing.T) {
 	assert.Empty(t, parseNextLink(""))
 }
 
+func TestCheckGitHubResponse(t *testing.T) {
+	response := func(status int, header http.Header) *http.Response {
+		if header == nil {
+			header = http.Header{}
+		}
+
+		return &http.Response{StatusCode: status, Header: header}
+	}
+
+	assert.Nil(t, checkGitHubResponse(response(http.StatusOK, nil), nil, "test"))
+	assert.ErrorIs(t, checkGitHubResponse(response(http.StatusNotFound, nil), nil, "test"), ErrNotFound)
+	assert.ErrorIs(t, checkGitHubResponse(response(http.StatusTooManyRequests, nil), nil, "test"), ErrTooManyRequests)
+
+	dmca := checkGitHubResponse(response(http.StatusUnavailableForLegalReasons, nil), []byte(`{"message":"Repository access blocked","block":{"reason":"dmca"}}`), "test")
+	assert.ErrorIs(t, dmca, ErrDmcaBlocked)
+	assert.ErrorIs(t, dmca, ErrAccessBlocked)
+
+	blocked := checkGitHubResponse(response(http.StatusForbidden, nil), []byte(`{"message":"Repository access blocked","block":{"reason":"tos"}}`), "test")
+	assert.ErrorIs(t, blocked, ErrSuspended)
+
+	rateLimited := checkGitHubResponse(response(http.StatusForbidden, http.Header{"X-Ratelimit-Remaining": []string{"0"}}), []byte(`{"message":"API rate limit exceeded"}`), "test")
+	assert.ErrorIs(t, rateLimited, ErrTooManyRequests)
+
+	secondary := checkGitHubResponse(response(http.StatusForbidden, nil), []byte(`{"message":"You have exceeded a secondary rate limit"}`), "test")
+	assert.ErrorIs(t, secondary, ErrTooManyRequests)
+
+	forbidden := checkGitHubResponse(response(http.StatusForbidden, nil), []byte(`{"message":"Resource not accessible by integration"}`), "test")
+	assert.ErrorIs(t, forbidden, ErrForbidden)
+	assert.NotErrorIs(t, forbidden, ErrAccessBlocked)
+}
+
 func TestGetDeveloper(t *testing.T) {
 	client := NewClient(NewTokenPool([]string{}, WithAllowEmptytoken(true)))
 
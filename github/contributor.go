@@ -0,0 +1,85 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type Contributor struct {
+	Login         string `json:"login"`
+	GhId          int    `json:"id"`
+	AvatarUrl     string `json:"avatar_url"`
+	UserType      string `json:"type"`
+	Contributions int    `json:"contributions"`
+}
+
+func (c Contributor) IsBot() bool {
+	return c.UserType == "Bot" || strings.HasSuffix(c.Login, "[bot]")
+}
+
+// Get the top contributors of a repository ordered by number of commits.
+func (ghClient *Client) GetContributors(ctx context.Context, fullName string, limit int) ([]Contributor, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/contributors?per_page=%d", fullName, limit)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := ghClient.TokenPool.GetToken()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	// Also allow to send requests without token
+	if strings.TrimSpace(token) != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to send get contributors request %v", err)
+	}
+
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			slog.Error("failed to close response body when fetch contributors", slog.Any("error", err))
+		}
+	}()
+
+	if strings.TrimSpace(token) != "" {
+		if err := syncRateLimitData(token, ghClient.TokenPool, res); err != nil {
+			return nil, fmt.Errorf("[github get contributors] sync rate limit data error: %w", err)
+		}
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	// GitHub returns 204 for an empty repository.
+	if res.StatusCode == http.StatusNoContent {
+		return []Contributor{}, nil
+	}
+
+	if err := checkGitHubResponse(res, body, "contributors"); err != nil {
+		return nil, err
+	}
+
+	var contributors []Contributor
+	if err := json.Unmarshal(body, &contributors); err != nil {
+		return nil, fmt.Errorf("failed to decode contributors body: %v", err)
+	}
+
+	return contributors, nil
+}
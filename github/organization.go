@@ -0,0 +1,92 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+// Fetch and save the organization that owns a repository, it returns a null id when the organization is not found or blocked.
+func (ghClient *Client) SaveOrganization(ctx context.Context, organizationRepo *model.OrganizationRepo, login string) (dbutils.NullInt64, error) {
+	organization, err := ghClient.GetOrganization(ctx, login)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrAccessBlocked) || errors.Is(err, ErrForbidden) {
+			slog.Info("organization not found or access blocked", slog.String("organization", login))
+			return dbutils.NullInt64{}, nil
+		}
+
+		return dbutils.NullInt64{}, fmt.Errorf("failed to get organization details from GitHub: %w", err)
+	}
+
+	id, err := organizationRepo.Save(ctx, organization)
+	if err != nil {
+		return dbutils.NullInt64{}, err
+	}
+
+	return dbutils.NewNullInt64(id), nil
+}
+
+func (ghClient *Client) GetOrganization(ctx context.Context, login string) (model.Organization, error) {
+	url := fmt.Sprintf("%s/%s", "https://api.github.com/orgs", login)
+
+	var organization model.Organization
+
+	token, err := ghClient.TokenPool.GetToken()
+	if err != nil {
+		return organization, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return organization, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	// Also allow to send request without token
+	if strings.TrimSpace(token) != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+
+	if err != nil {
+		return organization, fmt.Errorf("failed to send get organization request %v", err)
+	}
+
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			slog.Error("failed to close response body when fetch organization", slog.Any("error", err))
+		}
+	}()
+
+	if strings.TrimSpace(token) != "" {
+		if err := syncRateLimitData(token, ghClient.TokenPool, res); err != nil {
+			return organization, fmt.Errorf("[github get organization] sync rate limit data error: %w", err)
+		}
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return organization, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if err := checkGitHubResponse(res, body, "organization"); err != nil {
+		return organization, err
+	}
+
+	if err := json.Unmarshal(body, &organization); err != nil {
+		return organization, fmt.Errorf("failed to decode organization body err: %v, received: %s, status code: %s", err, string(body), res.Status)
+	}
+
+	return organization, nil
+}
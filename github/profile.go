@@ -0,0 +1,229 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type ownedRepositoryNode struct {
+	NameWithOwner   string  `json:"nameWithOwner"`
+	Description     *string `json:"description"`
+	StargazerCount  int     `json:"stargazerCount"`
+	ForkCount       int     `json:"forkCount"`
+	PrimaryLanguage *struct {
+		Name string `json:"name"`
+	} `json:"primaryLanguage"`
+}
+
+func (node ownedRepositoryNode) developerRepository() model.DeveloperRepository {
+	repository := model.DeveloperRepository{
+		FullName: node.NameWithOwner,
+		Stars:    node.StargazerCount,
+		Forks:    node.ForkCount,
+	}
+
+	if node.Description != nil {
+		repository.Description.String, repository.Description.Valid = *node.Description, true
+	}
+
+	if node.PrimaryLanguage != nil {
+		repository.Language.String, repository.Language.Valid = node.PrimaryLanguage.Name, true
+	}
+
+	return repository
+}
+
+type ownedRepositoriesResponse struct {
+	Data struct {
+		User *struct {
+			Top struct {
+				TotalCount int                   `json:"totalCount"`
+				Nodes      []ownedRepositoryNode `json:"nodes"`
+			} `json:"top"`
+			Stats struct {
+				Nodes []ownedRepositoryNode `json:"nodes"`
+			} `json:"stats"`
+			ContributionsCollection struct {
+				ContributionYears []int `json:"contributionYears"`
+			} `json:"contributionsCollection"`
+		} `json:"user"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+type yearlyContributionsResponse struct {
+	Data struct {
+		User map[string]struct {
+			ContributionCalendar struct {
+				TotalContributions int `json:"totalContributions"`
+			} `json:"contributionCalendar"`
+		} `json:"user"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+func checkGraphQLErrors(errs []graphQLError, context string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if errs[0].Type == "NOT_FOUND" {
+		return ErrNotFound
+	}
+
+	return fmt.Errorf("[%s] graphql error: %s", context, errs[0].Message)
+}
+
+// Fetch the non-fork repositories owned by a user and the years the user has contributions in, in a single query.
+// Only the most starred repositories are fetched with their details, the stars and languages of up to 100 of them
+// are enough for the totals and spare paginating through the repositories of prolific accounts.
+func (ghClient *Client) GetOwnedRepositories(ctx context.Context, login string) (model.OwnedRepositories, []int, error) {
+	query := `
+query ($login: String!) {
+  user(login: $login) {
+    top: repositories(first: 10, ownerAffiliations: OWNER, isFork: false, orderBy: {field: STARGAZERS, direction: DESC}) {
+      totalCount
+      nodes {
+        nameWithOwner
+        description
+        stargazerCount
+        forkCount
+        primaryLanguage {
+          name
+        }
+      }
+    }
+    stats: repositories(first: 100, ownerAffiliations: OWNER, isFork: false, orderBy: {field: STARGAZERS, direction: DESC}) {
+      nodes {
+        stargazerCount
+        primaryLanguage {
+          name
+        }
+      }
+    }
+    contributionsCollection {
+      contributionYears
+    }
+  }
+}`
+
+	var owned model.OwnedRepositories
+
+	body, err := postGraphQL(ctx, query, map[string]any{"login": login}, ghClient.TokenPool)
+	if err != nil {
+		return owned, nil, err
+	}
+
+	var gqlResp ownedRepositoriesResponse
+	if err := json.Unmarshal(body, &gqlResp); err != nil {
+		return owned, nil, fmt.Errorf("[owned repositories] failed to unmarshal graphql response, error: %v", err)
+	}
+
+	if err := checkGraphQLErrors(gqlResp.Errors, "owned repositories"); err != nil {
+		return owned, nil, err
+	}
+
+	user := gqlResp.Data.User
+	if user == nil {
+		return owned, nil, ErrNotFound
+	}
+
+	owned.Total = user.Top.TotalCount
+	owned.Top = make([]model.DeveloperRepository, 0, len(user.Top.Nodes))
+	owned.Stats = make([]model.DeveloperRepository, 0, len(user.Stats.Nodes))
+
+	for _, node := range user.Top.Nodes {
+		owned.Top = append(owned.Top, node.developerRepository())
+	}
+
+	for _, node := range user.Stats.Nodes {
+		owned.Stats = append(owned.Stats, node.developerRepository())
+	}
+
+	return owned, user.ContributionsCollection.ContributionYears, nil
+}
+
+// Fetch the total contributions of a user for each of the given years in a single query.
+func (ghClient *Client) GetYearlyContributions(ctx context.Context, login string, years []int) ([]model.DeveloperContribution, error) {
+	contributions := make([]model.DeveloperContribution, 0, len(years))
+
+	if len(years) == 0 {
+		return contributions, nil
+	}
+
+	var fields string
+	for _, year := range years {
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(year, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+		fields += fmt.Sprintf(`
+    y%d: contributionsCollection(from: "%s", to: "%s") {
+      contributionCalendar {
+        totalContributions
+      }
+    }`, year, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	query := fmt.Sprintf(`
+query ($login: String!) {
+  user(login: $login) {%s
+  }
+}`, fields)
+
+	body, err := postGraphQL(ctx, query, map[string]any{"login": login}, ghClient.TokenPool)
+	if err != nil {
+		return nil, err
+	}
+
+	var gqlResp yearlyContributionsResponse
+	if err := json.Unmarshal(body, &gqlResp); err != nil {
+		return nil, fmt.Errorf("[contributions] failed to unmarshal graphql response, error: %v", err)
+	}
+
+	if err := checkGraphQLErrors(gqlResp.Errors, "contributions"); err != nil {
+		return nil, err
+	}
+
+	for _, year := range slices.Sorted(slices.Values(years)) {
+		collection, ok := gqlResp.Data.User[fmt.Sprintf("y%d", year)]
+		if !ok {
+			continue
+		}
+
+		contributions = append(contributions, model.DeveloperContribution{
+			Year:          year,
+			Contributions: collection.ContributionCalendar.TotalContributions,
+		})
+	}
+
+	return contributions, nil
+}
+
+// Fetch everything we need to build the profile of a developer.
+func (ghClient *Client) GetDeveloperProfile(ctx context.Context, login string) (model.DeveloperProfile, error) {
+	owned, years, err := ghClient.GetOwnedRepositories(ctx, login)
+	if err != nil {
+		return model.DeveloperProfile{}, fmt.Errorf("failed to get owned repositories of %s: %w", login, err)
+	}
+
+	contributions, err := ghClient.GetYearlyContributions(ctx, login, years)
+	if err != nil {
+		return model.DeveloperProfile{}, fmt.Errorf("failed to get yearly contributions of %s: %w", login, err)
+	}
+
+	profile := model.NewDeveloperProfile(owned, contributions)
+	profile.UpdatedAt = dbutils.NewNullTime(time.Now())
+
+	return profile, nil
+}
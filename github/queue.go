@@ -0,0 +1,27 @@
+package github
+
+import (
+	"errors"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/queue"
+)
+
+// Minimum delay of a job deferred by the GitHub rate limits.
+const rateLimitedJobDelay = 1 * time.Minute
+
+// Defer a queued job which failed because GitHub rate limits us, so it does not use up its attempts.
+// Other errors are returned as they are.
+func (ghClient *Client) DeferRateLimited(err error) error {
+	if !errors.Is(err, ErrTokenNotAvailable) && !errors.Is(err, ErrTooManyRequests) {
+		return err
+	}
+
+	runAt := time.Now().Add(rateLimitedJobDelay)
+
+	if resetAt := ghClient.TokenPool.EarliestReset(); errors.Is(err, ErrTokenNotAvailable) && resetAt.After(runAt) {
+		runAt = resetAt
+	}
+
+	return queue.Defer(err, runAt)
+}
@@ -1,23 +1,48 @@
 package github
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/model/opt"
+	"github.com/liweiyi88/trendshift-backend/queue"
+	"github.com/liweiyi88/trendshift-backend/tagging"
 	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
-	"github.com/liweiyi88/trendshift-backend/utils/sliceutils"
 	"golang.org/x/sync/errgroup"
 )
 
-const chulkSize = 200
+// Sync jobs run concurrently, it keeps us under the GitHub secondary rate limit.
+// A run does not wait for the jobs deferred by a rate limit, they are synced once it resets.
+var syncQueueOptions = queue.Options{
+	Workers:      10,
+	PollInterval: queue.DefaultOptions.PollInterval,
+	LockTimeout:  queue.DefaultOptions.LockTimeout,
+	DrainDue:     true,
+}
+
+const (
+	// Number of top contributors we link to a repository.
+	topContributors = 10
+
+	// Number of candidates loaded and synced at a time, the run is checkpointed after each page.
+	syncPageSize = 500
+
+	// A failed sync is not retried within the run, the sync failures back it off until a later run.
+	syncJobMaxAttempts = 1
+)
 
 func fetchTotalContributors(fullName string) int {
 	url := fmt.Sprintf("https://github.com/%s", fullName)
@@ -49,178 +74,873 @@ func fetchTotalContributors(fullName string) int {
 	return count
 }
 
+// SyncSummary is the outcome of a sync run.
+type SyncSummary struct {
+	RunId      int64
+	ResumedAt  int // the run resumed after this id, 0 for a new run.
+	Candidates int // entities matching the sync options.
+	BackingOff int // candidates left out because they failed recently.
+	Rechecked  int // unavailable entities checked again on GitHub.
+	Synced     int
+	Deferred   int // syncs delayed by a rate limit, they run once it resets.
+	Failed     int // entities which still failed after their last attempt.
+}
+
+// Returns the ratio of the synced entities which failed, between 0 and 1.
+func (s SyncSummary) FailureRate() float64 {
+	attempted := s.Candidates + s.Rechecked - s.BackingOff
+	if attempted <= 0 {
+		return 0
+	}
+
+	return float64(s.Failed) / float64(attempted)
+}
+
 type SyncHandler struct {
-	repositoryRepo *model.GhRepositoryRepo
-	developerRepo  *model.DeveloperRepo
-	client         *Client
+	repositoryRepo         *model.GhRepositoryRepo
+	developerRepo          *model.DeveloperRepo
+	repositorySnapshotRepo *model.RepositorySnapshotRepo
+	developerSnapshotRepo  *model.DeveloperSnapshotRepo
+	developerProfileRepo   *model.DeveloperProfileRepo
+	contributorRepo        *model.RepositoryContributorRepo
+	organizationRepo       *model.OrganizationRepo
+	jobRepo                *model.JobRepo
+	syncFailureRepo        *model.SyncFailureRepo
+	syncRunRepo            *model.SyncRunRepo
+	entityStatusRepo       *model.EntityStatusRepo
+	tagger                 *tagging.Tagger
+	client                 *Client
+
+	// Primary keys of the organizations already synced in this run, keyed by login.
+	organizations sync.Map
 }
 
-func NewSyncHandler(repositoryRepo *model.GhRepositoryRepo, developerRepo *model.DeveloperRepo, client *Client) *SyncHandler {
+func NewSyncHandler(
+	repositoryRepo *model.GhRepositoryRepo,
+	developerRepo *model.DeveloperRepo,
+	repositorySnapshotRepo *model.RepositorySnapshotRepo,
+	developerSnapshotRepo *model.DeveloperSnapshotRepo,
+	developerProfileRepo *model.DeveloperProfileRepo,
+	contributorRepo *model.RepositoryContributorRepo,
+	organizationRepo *model.OrganizationRepo,
+	jobRepo *model.JobRepo,
+	syncFailureRepo *model.SyncFailureRepo,
+	syncRunRepo *model.SyncRunRepo,
+	entityStatusRepo *model.EntityStatusRepo,
+	tagger *tagging.Tagger,
+	client *Client) *SyncHandler {
 	return &SyncHandler{
-		repositoryRepo, developerRepo, client,
+		repositoryRepo:         repositoryRepo,
+		developerRepo:          developerRepo,
+		repositorySnapshotRepo: repositorySnapshotRepo,
+		developerSnapshotRepo:  developerSnapshotRepo,
+		developerProfileRepo:   developerProfileRepo,
+		contributorRepo:        contributorRepo,
+		organizationRepo:       organizationRepo,
+		jobRepo:                jobRepo,
+		syncFailureRepo:        syncFailureRepo,
+		syncRunRepo:            syncRunRepo,
+		entityStatusRepo:       entityStatusRepo,
+		tagger:                 tagger,
+		client:                 client,
+	}
+}
+
+// Save the organization that owns a repository and return its primary key, each organization is fetched once per run.
+func (s *SyncHandler) syncOrganization(ctx context.Context, login string) (dbutils.NullInt64, error) {
+	if id, ok := s.organizations.Load(login); ok {
+		return id.(dbutils.NullInt64), nil
+	}
+
+	organizationId, err := s.client.SaveOrganization(ctx, s.organizationRepo, login)
+	if err != nil {
+		return organizationId, err
 	}
+
+	s.organizations.Store(login, organizationId)
+
+	return organizationId, nil
 }
 
-func (s *SyncHandler) updateRepositories(ctx context.Context, repositories []model.GhRepository) error {
-	group, ctx := errgroup.WithContext(ctx)
+// Link the top contributors of a repository to developers, developers we have not seen yet are fetched and saved.
+func (s *SyncHandler) syncContributors(ctx context.Context, repository model.GhRepository) error {
+	contributors, err := s.client.GetContributors(ctx, repository.FullName, topContributors)
+	if err != nil {
+		// GitHub refuses to list contributors of repositories with a very large history.
+		if errors.Is(err, ErrForbidden) || errors.Is(err, ErrAccessBlocked) {
+			slog.Info("contributors list not available", slog.String("repository", repository.FullName))
+			return nil
+		}
+
+		return fmt.Errorf("failed to get contributors from GitHub, repo: %s, error: %w", repository.FullName, err)
+	}
+
+	logins := make([]string, 0, len(contributors))
+	for _, contributor := range contributors {
+		if !contributor.IsBot() {
+			logins = append(logins, contributor.Login)
+		}
+	}
+
+	developers, err := s.developerRepo.FindDevelopersByUsernames(ctx, logins)
+	if err != nil {
+		return fmt.Errorf("failed to query developers by names: %v", err)
+	}
 
-	// Follow the github best practice to avoid reaching secondary rate limit
-	// see https://docs.github.com/en/rest/guides/best-practices-for-using-the-rest-api?apiVersion=2022-11-28#dealing-with-secondary-rate-limits
-	limiter := time.NewTicker(20 * time.Millisecond)
-	defer limiter.Stop()
+	existing := make(map[string]model.Developer, len(developers))
+	for _, developer := range developers {
+		existing[strings.ToLower(developer.Username)] = developer
+	}
 
-	for _, repository := range repositories {
-		<-limiter.C
+	repositoryContributors := make([]model.RepositoryContributor, 0, len(logins))
 
-		repository := repository
+	for _, contributor := range contributors {
+		if contributor.IsBot() {
+			continue
+		}
 
-		group.Go(func() error {
-			ghRepository, err := s.client.GetRepository(ctx, repository.FullName)
+		developer, ok := existing[strings.ToLower(contributor.Login)]
 
+		if !ok {
+			developer, err = s.client.GetDeveloper(ctx, contributor.Login)
 			if err != nil {
-				if errors.Is(err, ErrNotFound) || errors.Is(err, ErrAccessBlocked) {
-					slog.Info("repository not found or access blocked, mark it as skipped", slog.String("repository", repository.FullName))
-					repository.Skipped = true
-					return s.repositoryRepo.Update(ctx, repository)
+				if errors.Is(err, ErrNotFound) {
+					continue
 				}
-				return fmt.Errorf("failed to get repository details from GitHub: %v", err)
+
+				return fmt.Errorf("failed to get contributor details from GitHub: %w", err)
 			}
 
-			lastCommit, lastUserCommit, err := s.client.GetLastCommit(ctx, repository.FullName)
+			lastInsertId, err := s.developerRepo.Save(ctx, developer)
 			if err != nil {
-				return fmt.Errorf("failed to get last commit from GitHub API, repo: %s, error: %v", repository.FullName, err)
+				return fmt.Errorf("failed to save contributor: %v", err)
 			}
 
-			repository.Skipped = false
-			repository.Description = ghRepository.Description
-			repository.Forks = ghRepository.Forks
-			repository.Stars = ghRepository.Stars
-			repository.Owner = ghRepository.Owner
-			repository.Language = ghRepository.Language // Language can also be updated
-			repository.DefaultBranch = ghRepository.DefaultBranch
-			repository.Homepage = ghRepository.Homepage
-
-			if lastCommit != nil {
-				repository.LastCommitAt = dbutils.NewNullTime(*lastCommit)
-			}
+			developer.Id = int(lastInsertId)
+		}
 
-			if lastUserCommit != nil {
-				repository.LastUserCommitAt = dbutils.NewNullTime(*lastUserCommit)
-			}
+		repositoryContributors = append(repositoryContributors, model.RepositoryContributor{
+			DeveloperId:   developer.Id,
+			Username:      developer.Username,
+			AvatarUrl:     developer.AvatarUrl,
+			Contributions: contributor.Contributions,
+		})
+	}
 
-			repository.License = ghRepository.License
-			repository.CreatedAt = ghRepository.CreatedAt
+	return s.contributorRepo.Save(ctx, repository.Id, repositoryContributors)
+}
 
-			return s.repositoryRepo.Update(ctx, repository)
-		})
+// Returns the repository with its details fetched from GitHub, nothing is saved.
+// When the repository is not found or blocked, unavailableStatus of the error returns its status.
+func (s *SyncHandler) fetchRepository(ctx context.Context, repository model.GhRepository) (model.GhRepository, error) {
+	ghRepository, err := s.client.GetRepository(ctx, repository.FullName)
+
+	if err != nil {
+		return repository, fmt.Errorf("failed to get repository details from GitHub: %w", err)
+	}
+
+	lastCommit, lastUserCommit, err := s.client.GetLastCommit(ctx, repository.FullName)
+	if err != nil {
+		return repository, fmt.Errorf("failed to get last commit from GitHub API, repo: %s, error: %w", repository.FullName, err)
 	}
 
-	return group.Wait()
+	repository.Skipped = false
+	repository.Description = ghRepository.Description
+	repository.Forks = ghRepository.Forks
+	repository.Stars = ghRepository.Stars
+	repository.Owner = ghRepository.Owner
+	repository.Language = ghRepository.Language // Language can also be updated
+	repository.DefaultBranch = ghRepository.DefaultBranch
+	repository.Homepage = ghRepository.Homepage
+	repository.Archived = ghRepository.Archived
+	repository.Topics = ghRepository.Topics
+
+	if lastCommit != nil {
+		repository.LastCommitAt = dbutils.NewNullTime(*lastCommit)
+	}
+
+	if lastUserCommit != nil {
+		repository.LastUserCommitAt = dbutils.NewNullTime(*lastUserCommit)
+	}
+
+	repository.License = ghRepository.License
+	repository.CreatedAt = ghRepository.CreatedAt
+
+	return repository, nil
 }
 
-func (s *SyncHandler) updateDevelopers(ctx context.Context, developers []model.Developer) error {
-	group, ctx := errgroup.WithContext(ctx)
+func (s *SyncHandler) updateRepository(ctx context.Context, repository model.GhRepository) error {
+	wasSkipped := repository.Skipped
 
-	// Follow the github best practice to avoid reaching secondary rate limit
-	// see https://docs.github.com/en/rest/guides/best-practices-for-using-the-rest-api?apiVersion=2022-11-28#dealing-with-secondary-rate-limits
-	limiter := time.NewTicker(20 * time.Millisecond)
-	defer limiter.Stop()
+	repository, err := s.fetchRepository(ctx, repository)
+	if status := unavailableStatus(err); status != "" {
+		return s.markUnavailable(ctx, model.EntityRepository, repository.Id, repository.FullName, status, err)
+	}
 
-	for _, developer := range developers {
-		<-limiter.C
+	if err != nil {
+		return err
+	}
 
-		developer := developer
+	repository.OrganizationId = dbutils.NullInt64{}
 
-		group.Go(func() error {
-			ghDeveloper, err := s.client.GetDeveloper(ctx, developer.Username)
+	if repository.Owner.Type == "Organization" {
+		repository.OrganizationId, err = s.syncOrganization(ctx, repository.Owner.Name)
+		if err != nil {
+			return err
+		}
+	}
 
-			if err != nil {
-				if errors.Is(err, ErrNotFound) || errors.Is(err, ErrAccessBlocked) {
-					slog.Info("developer not found or access blocked, mark it as skipped", slog.String("developer", developer.Username))
-					developer.Skipped = true
-					return s.developerRepo.Update(ctx, developer)
-				}
-				return fmt.Errorf("failed to get developer details from GitHub: %v", err)
-			}
+	if err := s.repositoryRepo.Update(ctx, repository); err != nil {
+		return err
+	}
 
-			developer.Skipped = false
-			developer.AvatarUrl = ghDeveloper.AvatarUrl
-			developer.Name = ghDeveloper.Name
-			developer.Company = ghDeveloper.Company
-			developer.Blog = ghDeveloper.Blog
-			developer.Location = ghDeveloper.Location
-			developer.Email = ghDeveloper.Email
-			developer.Bio = ghDeveloper.Bio
-			developer.TwitterUsername = ghDeveloper.TwitterUsername
-			developer.PublicRepos = ghDeveloper.PublicRepos
-			developer.PublicGists = ghDeveloper.PublicGists
-			developer.Followers = ghDeveloper.Followers
-			developer.Following = ghDeveloper.Following
-			developer.CreatedAt = ghDeveloper.CreatedAt
-
-			return s.developerRepo.Update(ctx, developer)
-		})
+	if wasSkipped {
+		if err := s.activate(ctx, model.EntityRepository, repository.Id, repository.FullName); err != nil {
+			return err
+		}
+	}
+
+	// Keep the counters history so we can rank repositories by growth.
+	if err := s.repositorySnapshotRepo.Save(ctx, repository); err != nil {
+		return err
 	}
 
-	return group.Wait()
+	if err := s.repositoryRepo.SaveTopics(ctx, repository); err != nil {
+		return err
+	}
+
+	if err := s.tagger.Tag(ctx, repository); err != nil {
+		return err
+	}
+
+	return s.syncContributors(ctx, repository)
 }
 
-func (s *SyncHandler) syncRepositories(ctx context.Context, opts ...any) error {
-	repositories, err := s.repositoryRepo.FindAll(
-		ctx,
-		opts...,
+// Returns the developer with its details fetched from GitHub, nothing is saved.
+// When the developer is not found or blocked, unavailableStatus of the error returns its status.
+func (s *SyncHandler) fetchDeveloper(ctx context.Context, developer model.Developer) (model.Developer, error) {
+	ghDeveloper, err := s.client.GetDeveloper(ctx, developer.Username)
+
+	if err != nil {
+		return developer, fmt.Errorf("failed to get developer details from GitHub: %w", err)
+	}
+
+	developer.Skipped = false
+	developer.AvatarUrl = ghDeveloper.AvatarUrl
+	developer.Name = ghDeveloper.Name
+	developer.Company = ghDeveloper.Company
+	developer.Blog = ghDeveloper.Blog
+	developer.Location = ghDeveloper.Location
+	developer.Email = ghDeveloper.Email
+	developer.Bio = ghDeveloper.Bio
+	developer.TwitterUsername = ghDeveloper.TwitterUsername
+	developer.PublicRepos = ghDeveloper.PublicRepos
+	developer.PublicGists = ghDeveloper.PublicGists
+	developer.Followers = ghDeveloper.Followers
+	developer.Following = ghDeveloper.Following
+	developer.CreatedAt = ghDeveloper.CreatedAt
+
+	return developer, nil
+}
+
+func (s *SyncHandler) updateDeveloper(ctx context.Context, developer model.Developer) error {
+	wasSkipped := developer.Skipped
+
+	developer, err := s.fetchDeveloper(ctx, developer)
+	if status := unavailableStatus(err); status != "" {
+		return s.markUnavailable(ctx, model.EntityDeveloper, developer.Id, developer.Username, status, err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.developerRepo.Update(ctx, developer); err != nil {
+		return err
+	}
+
+	if wasSkipped {
+		if err := s.activate(ctx, model.EntityDeveloper, developer.Id, developer.Username); err != nil {
+			return err
+		}
+	}
+
+	// Keep the counters history so we can draw the followers growth.
+	if err := s.developerSnapshotRepo.Save(ctx, developer); err != nil {
+		return err
+	}
+
+	profile, err := s.client.GetDeveloperProfile(ctx, developer.Username)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			slog.Info("developer profile not found, it might be an organization", slog.String("developer", developer.Username))
+			return nil
+		}
+
+		return fmt.Errorf("failed to get developer profile from GitHub: %w", err)
+	}
+
+	return s.developerProfileRepo.Save(ctx, developer.Id, profile)
+}
+
+// Returns the status of an entity GitHub answered err for, it is empty when err does not tell the entity is unavailable.
+// Rate limits and other 403s are not statuses, the entity is retried like after any other failure.
+func unavailableStatus(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return model.StatusNotFound
+	case errors.Is(err, ErrDmcaBlocked):
+		return model.StatusDmcaBlocked
+	case errors.Is(err, ErrAccessBlocked):
+		return model.StatusSuspended
+	default:
+		return ""
+	}
+}
+
+// Hide an entity which is not available on GitHub, it is rechecked on a decaying schedule.
+func (s *SyncHandler) markUnavailable(ctx context.Context, entityType string, id int, name, status string, err error) error {
+	entityStatus, markErr := s.entityStatusRepo.MarkUnavailable(ctx, entityType, id, status, err.Error())
+	if markErr != nil {
+		return markErr
+	}
+
+	slog.Info(
+		"entity unavailable on GitHub, mark it as skipped",
+		slog.String("type", entityType),
+		slog.String("name", name),
+		slog.String("status", status),
+		slog.Int("checks", entityStatus.Checks),
+		slog.Time("recheck_at", entityStatus.RecheckAt.Time),
 	)
 
+	return nil
+}
+
+// Make a skipped entity active again after it synced, it is saved as not skipped by its update.
+func (s *SyncHandler) activate(ctx context.Context, entityType string, id int, name string) error {
+	if err := s.entityStatusRepo.Activate(ctx, entityType, id); err != nil {
+		return err
+	}
+
+	slog.Info("entity available on GitHub again, mark it as active", slog.String("type", entityType), slog.String("name", name))
+	return nil
+}
+
+// A page of candidates after afterId in id order, lastId is the last id scanned, candidates or not.
+type candidatePage func(ctx context.Context, afterId int, size int) (ids []int, lastId int, err error)
+
+// Candidates updated within the start and end options.
+func windowPage(findIds func(ctx context.Context, afterId int, size int, opts ...any) ([]int, error), opts ...any) candidatePage {
+	return func(ctx context.Context, afterId int, size int) ([]int, int, error) {
+		ids, err := findIds(ctx, afterId, size, opts...)
+		if err != nil || len(ids) == 0 {
+			return ids, afterId, err
+		}
+
+		return ids, ids[len(ids)-1], nil
+	}
+}
+
+// Candidates due according to their priority schedule.
+func schedulePage(findCandidates func(ctx context.Context, afterId int, size int, since time.Time) ([]model.SyncCandidate, error), now time.Time) candidatePage {
+	return func(ctx context.Context, afterId int, size int) ([]int, int, error) {
+		candidates, err := findCandidates(ctx, afterId, size, now.Add(-model.SyncSignalWindow))
+		if err != nil || len(candidates) == 0 {
+			return nil, afterId, err
+		}
+
+		ids := make([]int, 0, len(candidates))
+
+		for _, candidate := range candidates {
+			candidate.Schedule()
+
+			if candidate.Due(now) {
+				ids = append(ids, candidate.Id)
+			}
+		}
+
+		return ids, candidates[len(candidates)-1].Id, nil
+	}
+}
+
+// The start and end options select the candidates by their last update, without them the priority schedule does.
+func scheduled(opts ...any) bool {
+	options := opt.ExtractOptions(opts...)
+	return options.Start == "" && options.End == ""
+}
+
+// Sync the candidate repositories page by page in id order.
+func (s *SyncHandler) syncRepositories(ctx context.Context, resume bool, opts ...any) (SyncSummary, error) {
+	page := windowPage(s.repositoryRepo.FindIdsAfter, opts...)
+	if scheduled(opts...) {
+		page = schedulePage(s.repositoryRepo.FindSyncCandidatesAfter, time.Now())
+	}
+
+	summary, err := s.run(ctx, model.EntityRepository, model.JobSyncRepository, s.HandleRepositoryJob, page, resume, opts...)
+	if err != nil {
+		return summary, fmt.Errorf("could not sync repositories: %w", err)
+	}
+
+	return summary, nil
+}
+
+// Sync the candidate developers page by page in id order.
+func (s *SyncHandler) syncDevelopers(ctx context.Context, resume bool, opts ...any) (SyncSummary, error) {
+	page := windowPage(s.developerRepo.FindIdsAfter, opts...)
+	if scheduled(opts...) {
+		page = schedulePage(s.developerRepo.FindSyncCandidatesAfter, time.Now())
+	}
+
+	summary, err := s.run(ctx, model.EntityDeveloper, model.JobSyncDeveloper, s.HandleDeveloperJob, page, resume, opts...)
 	if err != nil {
-		return fmt.Errorf("failed to find repositories: %v", err)
+		return summary, fmt.Errorf("could not sync developers: %w", err)
 	}
 
-	chulks := sliceutils.Chunk(repositories, chulkSize)
+	return summary, nil
+}
 
-	for _, chulk := range chulks {
-		err := s.updateRepositories(ctx, chulk)
+// Returns the sync schedule of every repository or developer, the entities due first.
+func (s *SyncHandler) Plan(ctx context.Context, action string) ([]model.SyncCandidate, error) {
+	var findCandidates func(ctx context.Context, afterId int, size int, since time.Time) ([]model.SyncCandidate, error)
 
+	switch action {
+	case model.EntityRepository:
+		findCandidates = s.repositoryRepo.FindSyncCandidatesAfter
+	case model.EntityDeveloper:
+		findCandidates = s.developerRepo.FindSyncCandidatesAfter
+	default:
+		return nil, errors.New("invalid sync action")
+	}
+
+	since := time.Now().Add(-model.SyncSignalWindow)
+	plan := make([]model.SyncCandidate, 0)
+
+	for lastId := 0; ; {
+		candidates, err := findCandidates(ctx, lastId, syncPageSize, since)
 		if err != nil {
-			return fmt.Errorf("could not sync repositories: %v", err)
+			return nil, err
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		for _, candidate := range candidates {
+			candidate.Schedule()
+			plan = append(plan, candidate)
 		}
 
-		slog.Info(fmt.Sprintf("completed batch update for %d repositories", len(chulk)))
+		lastId = candidates[len(candidates)-1].Id
 	}
 
-	slog.Info("repositories update completed.")
+	slices.SortStableFunc(plan, func(a, b model.SyncCandidate) int {
+		if c := a.DueAt.Compare(b.DueAt); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	return plan, nil
+}
+
+// Fetch the candidates from GitHub and report how their records would change, nothing is written.
+// The candidates are selected like a sync run selects them, a failing candidate is reported as skipped.
+func (s *SyncHandler) DryRun(ctx context.Context, action string, report *dryrun.Report, opts ...any) error {
+	var page candidatePage
+	var preview func(ctx context.Context, id int) error
+
+	switch action {
+	case model.EntityRepository:
+		page = windowPage(s.repositoryRepo.FindIdsAfter, opts...)
+		if scheduled(opts...) {
+			page = schedulePage(s.repositoryRepo.FindSyncCandidatesAfter, time.Now())
+		}
+
+		preview = func(ctx context.Context, id int) error {
+			return s.previewRepository(ctx, id, report)
+		}
+	case model.EntityDeveloper:
+		page = windowPage(s.developerRepo.FindIdsAfter, opts...)
+		if scheduled(opts...) {
+			page = schedulePage(s.developerRepo.FindSyncCandidatesAfter, time.Now())
+		}
+
+		preview = func(ctx context.Context, id int) error {
+			return s.previewDeveloper(ctx, id, report)
+		}
+	default:
+		return errors.New("invalid sync action")
+	}
+
+	backingOff, err := s.syncFailureRepo.FindBackingOffIds(ctx, action, time.Now())
+	if err != nil {
+		return err
+	}
+
+	limit := opt.ExtractOptions(opts...).Limit
+	candidates := 0
+
+	for lastId := 0; ; {
+		size := syncPageSize
+		if limit > 0 {
+			size = min(size, limit-candidates)
+		}
+
+		if size <= 0 {
+			return nil
+		}
+
+		ids, scannedId, err := page(ctx, lastId, size)
+		if err != nil {
+			return err
+		}
+
+		if scannedId == lastId {
+			return nil
+		}
+
+		candidates += len(ids)
+		lastId = scannedId
+
+		group, groupCtx := errgroup.WithContext(ctx)
+		group.SetLimit(syncQueueOptions.Workers)
+
+		for _, id := range ids {
+			if backingOff[id] {
+				report.Skip(action, strconv.Itoa(id), "backing off after failed syncs")
+				continue
+			}
+
+			group.Go(func() error {
+				return preview(groupCtx, id)
+			})
+		}
+
+		if err := group.Wait(); err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *SyncHandler) previewRepository(ctx context.Context, id int, report *dryrun.Report) error {
+	repository, err := s.repositoryRepo.FindById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find repository: %v", err)
+	}
+
+	if repository.Id == 0 {
+		return nil
+	}
+
+	fetched, err := s.fetchRepository(ctx, repository)
+	if status := unavailableStatus(err); status != "" {
+		fetched.Skipped = true
+		err = nil
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		report.Skip(model.EntityRepository, repository.FullName, err.Error())
+		return nil
+	}
+
+	report.Update(model.EntityRepository, repository.FullName, repository.DryRunFields(), fetched.DryRunFields())
 	return nil
 }
 
-func (s *SyncHandler) syncDevelopers(ctx context.Context, opts ...any) error {
-	developers, err := s.developerRepo.FindAll(ctx, opts...)
+func (s *SyncHandler) previewDeveloper(ctx context.Context, id int, report *dryrun.Report) error {
+	developer, err := s.developerRepo.FindById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find developer: %v", err)
+	}
+
+	if developer.Id == 0 {
+		return nil
+	}
+
+	fetched, err := s.fetchDeveloper(ctx, developer)
+	if status := unavailableStatus(err); status != "" {
+		fetched.Skipped = true
+		err = nil
+	}
 
 	if err != nil {
-		return fmt.Errorf("failed to find developers: %v", err)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		report.Skip(model.EntityDeveloper, developer.Username, err.Error())
+		return nil
 	}
 
-	chulks := sliceutils.Chunk(developers, chulkSize)
+	report.Update(model.EntityDeveloper, developer.Username, developer.DryRunFields(), fetched.DryRunFields())
+	return nil
+}
+
+// Start a new run, or continue the last unfinished run of the action when resume is set.
+func (s *SyncHandler) startRun(ctx context.Context, action string, resume bool) (model.SyncRun, error) {
+	if resume {
+		run, err := s.syncRunRepo.FindResumable(ctx, action)
+
+		if err == nil {
+			slog.Info("resuming sync run", slog.Int64("run_id", run.Id), slog.Int("last_id", run.LastId))
+			return run, nil
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return run, fmt.Errorf("failed to find resumable sync run: %v", err)
+		}
+
+		slog.Info("no sync run to resume, starting a new one", slog.String("action", action))
+	}
+
+	return s.syncRunRepo.Start(ctx, action)
+}
+
+// Sync the candidates which are not backing off from a previous failure. The candidates are loaded a page at a time,
+// enqueued and drained, then the run is checkpointed so an interrupted run resumes after the last synced page.
+// A failing entity does not stop the run, its failure is recorded and it is left out of the next runs until its backoff expires.
+func (s *SyncHandler) run(
+	ctx context.Context,
+	entityType, kind string,
+	handler queue.Handler,
+	page candidatePage,
+	resume bool,
+	opts ...any,
+) (SyncSummary, error) {
+	syncRun, err := s.startRun(ctx, entityType, resume)
+	if err != nil {
+		return SyncSummary{}, err
+	}
+
+	summary := SyncSummary{RunId: syncRun.Id, ResumedAt: syncRun.LastId}
+
+	backingOff, err := s.syncFailureRepo.FindBackingOffIds(ctx, entityType, time.Now())
+	if err != nil {
+		return summary, err
+	}
+
+	q := queue.New(s.jobRepo, syncQueueOptions)
+	q.Handle(kind, handler)
+
+	// Runs selecting the candidates by their last update leave the unavailable entities alone.
+	if scheduled(opts...) {
+		if err := s.recheck(ctx, q, entityType, kind, backingOff, &summary); err != nil {
+			return summary, err
+		}
+	}
+
+	limit := opt.ExtractOptions(opts...).Limit
+	lastId := syncRun.LastId
+
+	for {
+		size := syncPageSize
+		if limit > 0 {
+			size = min(size, limit-summary.Candidates)
+		}
+
+		if size <= 0 {
+			break
+		}
+
+		ids, scannedId, err := page(ctx, lastId, size)
+		if err != nil {
+			return summary, err
+		}
+
+		if scannedId == lastId {
+			break
+		}
+
+		summary.Candidates += len(ids)
+
+		payloads := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if backingOff[id] {
+				summary.BackingOff++
+				continue
+			}
+
+			payloads = append(payloads, strconv.Itoa(id))
+		}
+
+		if _, err := s.jobRepo.EnqueueWithMaxAttempts(ctx, kind, syncJobMaxAttempts, payloads...); err != nil {
+			return summary, fmt.Errorf("failed to enqueue %s sync: %v", entityType, err)
+		}
+
+		stats, err := q.Drain(ctx)
 
-	for _, chulk := range chulks {
-		err := s.updateDevelopers(ctx, chulk)
+		summary.Synced = stats.Done
+		summary.Deferred = stats.Deferred
+		summary.Failed = stats.Dead
 
 		if err != nil {
-			return fmt.Errorf("could not sync developers: %v", err)
+			return summary, err
+		}
+
+		// The page is not fully synced, its pending jobs are picked up again when the run resumes.
+		if ctx.Err() != nil {
+			return summary, fmt.Errorf("sync run %d interrupted after id %d, continue it with --resume: %w", syncRun.Id, lastId, ctx.Err())
 		}
 
-		slog.Info(fmt.Sprintf("completed batch update for %d developers", len(chulk)))
+		lastId = scannedId
+
+		if err := s.syncRunRepo.Checkpoint(ctx, syncRun.Id, lastId); err != nil {
+			return summary, err
+		}
+	}
+
+	return summary, s.syncRunRepo.Complete(ctx, syncRun.Id)
+}
+
+// Sync the unavailable entities due for a recheck, an entity found on GitHub again is active and no longer skipped.
+func (s *SyncHandler) recheck(ctx context.Context, q *queue.Queue, entityType, kind string, backingOff map[int]bool, summary *SyncSummary) error {
+	ids, err := s.entityStatusRepo.FindDueRechecks(ctx, entityType, time.Now(), syncPageSize)
+	if err != nil {
+		return err
+	}
+
+	payloads := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !backingOff[id] {
+			payloads = append(payloads, strconv.Itoa(id))
+		}
+	}
+
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	if _, err := s.jobRepo.EnqueueWithMaxAttempts(ctx, kind, syncJobMaxAttempts, payloads...); err != nil {
+		return fmt.Errorf("failed to enqueue %s rechecks: %v", entityType, err)
+	}
+
+	summary.Rechecked = len(payloads)
+
+	stats, err := q.Drain(ctx)
+
+	summary.Synced = stats.Done
+	summary.Deferred = stats.Deferred
+	summary.Failed = stats.Dead
+
+	if err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("sync run %d interrupted while rechecking unavailable %ss: %w", summary.RunId, entityType, ctx.Err())
 	}
 
-	slog.Info("developers update completed.")
 	return nil
 }
 
-func (s *SyncHandler) Handle(ctx context.Context, action string, opts ...any) error {
+// Record the outcome of a sync job, the failure is recorded once the job runs out of attempts, i.e. after its single attempt of the run.
+func (s *SyncHandler) recordOutcome(ctx context.Context, entityType string, id int, job model.Job, err error) error {
+	if err == nil {
+		return s.syncFailureRepo.Clear(ctx, entityType, id)
+	}
+
+	if !queue.Final(job, err) || ctx.Err() != nil {
+		return err
+	}
+
+	failure, recordErr := s.syncFailureRepo.Record(context.WithoutCancel(ctx), entityType, id, err.Error())
+	if recordErr != nil {
+		return errors.Join(err, recordErr)
+	}
+
+	slog.Warn("sync failed, backing off", slog.String("type", entityType), slog.Int("id", id), slog.Int("failures", failure.Failures), slog.Time("retry_after", failure.RetryAfter))
+	return err
+}
+
+// Reports whether an entity was soft deleted by an admin, it is not synced until it is restored.
+// Only a skipped entity can have been deleted, the others are not looked up.
+func (s *SyncHandler) deleted(ctx context.Context, entityType string, id int, skipped bool) (bool, error) {
+	if !skipped {
+		return false, nil
+	}
+
+	status, err := s.entityStatusRepo.FindByEntity(ctx, entityType, id)
+	if err != nil {
+		return false, err
+	}
+
+	return status.Status == model.StatusDeleted, nil
+}
+
+// Job handler of model.JobSyncRepository.
+func (s *SyncHandler) HandleRepositoryJob(ctx context.Context, job model.Job) error {
+	id, err := strconv.Atoi(job.Payload)
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("invalid repository id: %s", job.Payload))
+	}
+
+	repository, err := s.repositoryRepo.FindById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find repository: %v", err)
+	}
+
+	// The repository has been deleted since the job was enqueued.
+	if repository.Id == 0 {
+		return nil
+	}
+
+	if deleted, err := s.deleted(ctx, model.EntityRepository, repository.Id, repository.Skipped); err != nil || deleted {
+		return err
+	}
+
+	err = s.client.DeferRateLimited(s.updateRepository(ctx, repository))
+	return s.recordOutcome(ctx, model.EntityRepository, id, job, err)
+}
+
+// Job handler of model.JobSyncDeveloper.
+func (s *SyncHandler) HandleDeveloperJob(ctx context.Context, job model.Job) error {
+	id, err := strconv.Atoi(job.Payload)
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("invalid developer id: %s", job.Payload))
+	}
+
+	developer, err := s.developerRepo.FindById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find developer: %v", err)
+	}
+
+	// The developer has been deleted since the job was enqueued.
+	if developer.Id == 0 {
+		return nil
+	}
+
+	if deleted, err := s.deleted(ctx, model.EntityDeveloper, developer.Id, developer.Skipped); err != nil || deleted {
+		return err
+	}
+
+	err = s.client.DeferRateLimited(s.updateDeveloper(ctx, developer))
+	return s.recordOutcome(ctx, model.EntityDeveloper, id, job, err)
+}
+
+// Sync the repositories or developers, resume continues the last interrupted run of the action.
+func (s *SyncHandler) Handle(ctx context.Context, action string, resume bool, opts ...any) (SyncSummary, error) {
 	switch action {
 	case "repository":
-		return s.syncRepositories(ctx, opts...)
+		return s.syncRepositories(ctx, resume, opts...)
 	case "developer":
-		return s.syncDevelopers(ctx, opts...)
+		return s.syncDevelopers(ctx, resume, opts...)
 	default:
-		return errors.New("invalid search action")
+		return SyncSummary{}, errors.New("invalid search action")
 	}
 }
+
+// Register the sync job handlers in a queue run by a long running worker.
+func (s *SyncHandler) Register(q *queue.Queue) {
+	q.Handle(model.JobSyncRepository, s.HandleRepositoryJob)
+	q.Handle(model.JobSyncDeveloper, s.HandleDeveloperJob)
+}
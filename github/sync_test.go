@@ -1,8 +1,10 @@
 package github
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/liweiyi88/trendshift-backend/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -10,3 +12,20 @@ func TestFetchTotalContributors(t *testing.T) {
 	total := fetchTotalContributors("liweiyi88/onedump")
 	assert.Equal(t, 3, total)
 }
+
+func TestSyncSummaryFailureRate(t *testing.T) {
+	assert.Equal(t, float64(0), SyncSummary{}.FailureRate())
+	assert.Equal(t, float64(0), SyncSummary{Candidates: 5, BackingOff: 5}.FailureRate())
+	assert.Equal(t, 0.25, SyncSummary{Candidates: 10, BackingOff: 2, Synced: 6, Failed: 2}.FailureRate())
+	assert.Equal(t, 0.25, SyncSummary{Candidates: 6, BackingOff: 2, Rechecked: 4, Synced: 6, Failed: 2}.FailureRate())
+}
+
+func TestUnavailableStatus(t *testing.T) {
+	assert.Equal(t, model.StatusNotFound, unavailableStatus(fmt.Errorf("failed to get repository details from GitHub: %w", ErrNotFound)))
+	assert.Equal(t, model.StatusDmcaBlocked, unavailableStatus(ErrDmcaBlocked))
+	assert.Equal(t, model.StatusSuspended, unavailableStatus(ErrSuspended))
+	assert.Equal(t, model.StatusSuspended, unavailableStatus(ErrAccessBlocked))
+	assert.Equal(t, "", unavailableStatus(ErrTooManyRequests))
+	assert.Equal(t, "", unavailableStatus(fmt.Errorf("%w: Resource not accessible by integration", ErrForbidden)))
+	assert.Equal(t, "", unavailableStatus(nil))
+}
@@ -14,6 +14,18 @@ type Repositories struct {
 	UserRepo                     *model.UserRepo
 	StatsRepo                    *model.StatsRepo
 	RepositoryMonthlyInsightRepo *model.RepositoryMonthlyInsightRepo
+	RepositorySnapshotRepo       *model.RepositorySnapshotRepo
+	DeveloperSnapshotRepo        *model.DeveloperSnapshotRepo
+	DeveloperProfileRepo         *model.DeveloperProfileRepo
+	RepositoryContributorRepo    *model.RepositoryContributorRepo
+	OrganizationRepo             *model.OrganizationRepo
+	RepositorySimilarityRepo     *model.RepositorySimilarityRepo
+	RepositorySubmissionRepo     *model.RepositorySubmissionRepo
+	JobRepo                      *model.JobRepo
+	SyncFailureRepo              *model.SyncFailureRepo
+	SyncRunRepo                  *model.SyncRunRepo
+	PageViewRepo                 *model.PageViewRepo
+	EntityStatusRepo             *model.EntityStatusRepo
 }
 
 func InitRepositories(db database.DB) *Repositories {
@@ -26,5 +38,17 @@ func InitRepositories(db database.DB) *Repositories {
 		UserRepo:                     model.NewUserRepo(db),
 		StatsRepo:                    model.NewStatsRepo(db),
 		RepositoryMonthlyInsightRepo: model.NewRepositoryMonthlyInsightRepo(db),
+		RepositorySnapshotRepo:       model.NewRepositorySnapshotRepo(db),
+		DeveloperSnapshotRepo:        model.NewDeveloperSnapshotRepo(db),
+		DeveloperProfileRepo:         model.NewDeveloperProfileRepo(db),
+		RepositoryContributorRepo:    model.NewRepositoryContributorRepo(db),
+		OrganizationRepo:             model.NewOrganizationRepo(db),
+		RepositorySimilarityRepo:     model.NewRepositorySimilarityRepo(db),
+		RepositorySubmissionRepo:     model.NewRepositorySubmissionRepo(db),
+		JobRepo:                      model.NewJobRepo(db),
+		SyncFailureRepo:              model.NewSyncFailureRepo(db),
+		SyncRunRepo:                  model.NewSyncRunRepo(db),
+		PageViewRepo:                 model.NewPageViewRepo(db),
+		EntityStatusRepo:             model.NewEntityStatusRepo(db),
 	}
 }
@@ -2,14 +2,17 @@ package ingestion
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
-	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/liweiyi88/trendshift-backend/github"
 	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/queue"
 	"github.com/liweiyi88/trendshift-backend/utils/datetime"
 	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 	"golang.org/x/sync/errgroup"
@@ -17,15 +20,23 @@ import (
 
 const batchSize = 1000
 
+var ingestQueueOptions = queue.Options{
+	Workers:      10,
+	PollInterval: queue.DefaultOptions.PollInterval,
+	LockTimeout:  queue.DefaultOptions.LockTimeout,
+}
+
 type MonthlyRepoDataIngestor struct {
-	gh  *github.Client
-	rmr *model.RepositoryMonthlyInsightRepo
+	gh   *github.Client
+	rmr  *model.RepositoryMonthlyInsightRepo
+	jobs *model.JobRepo
 }
 
-func NewMonthlyRepoDataIngestor(rmr *model.RepositoryMonthlyInsightRepo, gh *github.Client) *MonthlyRepoDataIngestor {
+func NewMonthlyRepoDataIngestor(rmr *model.RepositoryMonthlyInsightRepo, jobs *model.JobRepo, gh *github.Client) *MonthlyRepoDataIngestor {
 	return &MonthlyRepoDataIngestor{
-		rmr: rmr,
-		gh:  gh,
+		rmr:  rmr,
+		jobs: jobs,
+		gh:   gh,
 	}
 }
 
@@ -121,25 +132,54 @@ func (ingestor *MonthlyRepoDataIngestor) Ingest(ctx context.Context, month, year
 		return true, nil
 	}
 
-	chunks := slices.Chunk(montlyRepoInsights, 10)
-	for chunk := range chunks {
-		g, gctx := errgroup.WithContext(ctx)
-		for _, insight := range chunk {
-			g.Go(func() error {
-				start := time.Date(insight.Year, time.Month(insight.Month), 1, 0, 0, 0, 0, time.Local)
-				end := datetime.EndOfMonth(start)
-				return ingestor.ingest(gctx, start, end, insight)
-			})
-		}
+	ids := make([]string, 0, len(montlyRepoInsights))
+	for _, insight := range montlyRepoInsights {
+		ids = append(ids, strconv.Itoa(insight.Id))
+	}
 
-		if err := g.Wait(); err != nil {
-			return false, err
-		}
+	if _, err := ingestor.jobs.Enqueue(ctx, model.JobIngestMonthlyInsight, ids...); err != nil {
+		return false, fmt.Errorf("failed to enqueue repository monthly data ingestion, error: %w", err)
+	}
+
+	q := queue.New(ingestor.jobs, ingestQueueOptions)
+	q.Handle(model.JobIngestMonthlyInsight, ingestor.HandleJob)
+
+	stats, err := q.Drain(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	ingestor.gh.TokenPool.Debug()
+
+	// Insights whose job went to the dead letter are still incomplete, stop for today instead of failing them again in a loop.
+	return stats.Done == 0, nil
+}
 
-		ingestor.gh.TokenPool.Debug()
+// Job handler of model.JobIngestMonthlyInsight.
+func (ingestor *MonthlyRepoDataIngestor) HandleJob(ctx context.Context, job model.Job) error {
+	id, err := strconv.Atoi(job.Payload)
+	if err != nil {
+		return queue.Permanent(fmt.Errorf("invalid repository monthly insight id: %s", job.Payload))
+	}
+
+	insight, err := ingestor.rmr.FindWithNameById(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to find repository monthly insight, error: %w", err)
 	}
 
-	return false, nil
+	// Completed since the job was enqueued.
+	if insight.CompletedAt.Valid {
+		return nil
+	}
+
+	start := time.Date(insight.Year, time.Month(insight.Month), 1, 0, 0, 0, 0, time.Local)
+	end := datetime.EndOfMonth(start)
+
+	return ingestor.gh.DeferRateLimited(ingestor.ingest(ctx, start, end, insight))
 }
 
 func fetchPaginated[T any](
@@ -205,3 +245,8 @@ func (ingestor *MonthlyRepoDataIngestor) fetchStars(ctx context.Context, reposit
 	data, err := fetchPaginated(ctx, repository, start, end, ingestor.gh.GetRepositoryStars)
 	return len(data), err
 }
+
+// Register the ingestion job handler in a queue run by a long running worker.
+func (ingestor *MonthlyRepoDataIngestor) Register(q *queue.Queue) {
+	q.Handle(model.JobIngestMonthlyInsight, ingestor.HandleJob)
+}
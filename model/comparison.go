@@ -0,0 +1,140 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const MaxComparedRepositories = 10
+
+type TrendingSummary struct {
+	FeaturedCount  int                `json:"featured_count"`
+	BestRanking    dbutils.NullInt64  `json:"best_ranking"`
+	FirstTrendDate dbutils.NullString `json:"first_trend_date"`
+	LastTrendDate  dbutils.NullString `json:"last_trend_date"`
+}
+
+type ComparedRepository struct {
+	GhRepository
+	Trending          TrendingSummary             `json:"trending"`
+	MonthlyActivities []*RepositoryMonthlyInsight `json:"monthly_activities"` // aligned with the comparison months, null when a month is missing.
+}
+
+type RepositoryComparison struct {
+	Months       []string             `json:"months"` // formatted as 2006-01, oldest first.
+	Repositories []ComparedRepository `json:"repositories"`
+}
+
+// Parse comma separated repository ids, duplicates are ignored and the order is kept.
+func ParseRepositoryIds(idsStr string) ([]int, error) {
+	ids := make([]int, 0)
+
+	for _, part := range strings.Split(idsStr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(part)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid repository id: %s", part)
+		}
+
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.New("no repository id")
+	}
+
+	if len(ids) > MaxComparedRepositories {
+		return nil, fmt.Errorf("can not compare more than %d repositories", MaxComparedRepositories)
+	}
+
+	return ids, nil
+}
+
+// Summarise the trending appearances of a repository across all trending pages.
+func SummariseTrendings(trendings []Trending) TrendingSummary {
+	var summary TrendingSummary
+
+	for _, trending := range trendings {
+		if trending.IsZero() {
+			continue
+		}
+
+		summary.FeaturedCount++
+
+		if trending.Rank.Valid && (!summary.BestRanking.Valid || trending.Rank.Int64 < summary.BestRanking.Int64) {
+			summary.BestRanking = trending.Rank
+		}
+
+		if trending.TrendDate.Valid {
+			if !summary.FirstTrendDate.Valid || trending.TrendDate.String < summary.FirstTrendDate.String {
+				summary.FirstTrendDate = trending.TrendDate
+			}
+
+			if !summary.LastTrendDate.Valid || trending.TrendDate.String > summary.LastTrendDate.String {
+				summary.LastTrendDate = trending.TrendDate
+			}
+		}
+	}
+
+	return summary
+}
+
+// Align the monthly activities of several repositories on the same continuous months, from the oldest to the latest
+// month any of them has. Missing months are nil so that every repository has one entry per month.
+func AlignMonthlyActivities(activities [][]RepositoryMonthlyInsight) ([]string, [][]*RepositoryMonthlyInsight) {
+	var first, last time.Time
+
+	for _, insights := range activities {
+		for _, insight := range insights {
+			month := time.Date(insight.Year, time.Month(insight.Month), 1, 0, 0, 0, 0, time.UTC)
+
+			if first.IsZero() || month.Before(first) {
+				first = month
+			}
+
+			if last.IsZero() || month.After(last) {
+				last = month
+			}
+		}
+	}
+
+	months := make([]string, 0)
+	aligned := make([][]*RepositoryMonthlyInsight, len(activities))
+
+	if first.IsZero() {
+		for i := range aligned {
+			aligned[i] = make([]*RepositoryMonthlyInsight, 0)
+		}
+
+		return months, aligned
+	}
+
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		months = append(months, month.Format("2006-01"))
+	}
+
+	for i, insights := range activities {
+		aligned[i] = make([]*RepositoryMonthlyInsight, len(months))
+
+		for _, insight := range insights {
+			month := time.Date(insight.Year, time.Month(insight.Month), 1, 0, 0, 0, 0, time.UTC)
+			index := (month.Year()-first.Year())*12 + int(month.Month()) - int(first.Month())
+
+			aligned[i][index] = &insight
+		}
+	}
+
+	return months, aligned
+}
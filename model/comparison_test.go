@@ -0,0 +1,71 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRepositoryIds(t *testing.T) {
+	ids, err := ParseRepositoryIds(" 3,1, 3 ,2,")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 1, 2}, ids)
+
+	invalid := []string{"", ",", "1,abc", "0", "-1", "1,2,3,4,5,6,7,8,9,10,11"}
+
+	for _, idsStr := range invalid {
+		_, err := ParseRepositoryIds(idsStr)
+		assert.Error(t, err, idsStr)
+	}
+}
+
+func TestSummariseTrendings(t *testing.T) {
+	assert.Equal(t, TrendingSummary{}, SummariseTrendings(nil))
+
+	summary := SummariseTrendings([]Trending{
+		{TrendDate: dbutils.NewNullString("2025-06-03"), Rank: dbutils.NewNullInt64(5)},
+		{TrendDate: dbutils.NewNullString("2025-06-01"), Rank: dbutils.NewNullInt64(2), TrendingLanguage: dbutils.NewNullString("Go")},
+		{TrendDate: dbutils.NewNullString("2025-06-02"), Rank: dbutils.NewNullInt64(9)},
+		{},
+	})
+
+	assert.Equal(t, TrendingSummary{
+		FeaturedCount:  3,
+		BestRanking:    dbutils.NewNullInt64(2),
+		FirstTrendDate: dbutils.NewNullString("2025-06-01"),
+		LastTrendDate:  dbutils.NewNullString("2025-06-03"),
+	}, summary)
+}
+
+func TestAlignMonthlyActivities(t *testing.T) {
+	months, aligned := AlignMonthlyActivities([][]RepositoryMonthlyInsight{{}, {}})
+	assert.Empty(t, months)
+	assert.Equal(t, [][]*RepositoryMonthlyInsight{{}, {}}, aligned)
+
+	activities := [][]RepositoryMonthlyInsight{
+		{
+			{Id: 1, Year: 2025, Month: 1},
+			{Id: 2, Year: 2024, Month: 11},
+		},
+		{
+			{Id: 3, Year: 2024, Month: 12},
+		},
+		{},
+	}
+
+	months, aligned = AlignMonthlyActivities(activities)
+
+	assert.Equal(t, []string{"2024-11", "2024-12", "2025-01"}, months)
+	assert.Len(t, aligned, 3)
+
+	assert.Equal(t, 2, aligned[0][0].Id)
+	assert.Nil(t, aligned[0][1])
+	assert.Equal(t, 1, aligned[0][2].Id)
+
+	assert.Nil(t, aligned[1][0])
+	assert.Equal(t, 3, aligned[1][1].Id)
+	assert.Nil(t, aligned[1][2])
+
+	assert.Equal(t, []*RepositoryMonthlyInsight{nil, nil, nil}, aligned[2])
+}
@@ -3,10 +3,12 @@ package model
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/model/opt"
 	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 )
@@ -33,12 +35,39 @@ type Developer struct {
 	UpdatedAt       time.Time          `json:"updated_at"` // It is the datetime we update the DB record, not when developer info updated on GitHub
 }
 
+// The columns a sync or a fetch writes, compared by dry runs.
+func (d Developer) DryRunFields() []dryrun.Field {
+	return []dryrun.Field{
+		{Name: "username", Value: d.Username},
+		{Name: "gh_id", Value: d.GhId},
+		{Name: "avatar_url", Value: d.AvatarUrl},
+		{Name: "name", Value: d.Name},
+		{Name: "company", Value: d.Company},
+		{Name: "blog", Value: d.Blog},
+		{Name: "location", Value: d.Location},
+		{Name: "email", Value: d.Email},
+		{Name: "bio", Value: d.Bio},
+		{Name: "twitter_username", Value: d.TwitterUsername},
+		{Name: "public_repos", Value: d.PublicRepos},
+		{Name: "public_gists", Value: d.PublicGists},
+		{Name: "followers", Value: d.Followers},
+		{Name: "following", Value: d.Following},
+		{Name: "skipped", Value: d.Skipped},
+		{Name: "created_at", Value: d.CreatedAt},
+	}
+}
+
 type TrendingDeveloperResponse struct {
 	Developer
 	BestRanking   int `json:"best_ranking"`   // non db column field
 	FeaturedCount int `json:"featured_count"` // non db column field
 }
 
+type NewTrendingDeveloperResponse struct {
+	TrendingDeveloperResponse
+	FirstTrendDate time.Time `json:"first_trend_date"` // non db column field, first date the developer trended on any trending page.
+}
+
 type DeveloperRepo struct {
 	db database.DB
 }
@@ -115,6 +144,177 @@ func (dr *DeveloperRepo) FindAll(ctx context.Context, opts ...any) ([]Developer,
 	return developers, nil
 }
 
+// Find the ids of the entities to sync after afterId in id order, it pages through the candidates with a keyset.
+func (dr *DeveloperRepo) FindIdsAfter(ctx context.Context, afterId int, size int, opts ...any) ([]int, error) {
+	qb := dbutils.NewQueryBuilder()
+	qb.Query("SELECT id FROM developers")
+
+	options := opt.ExtractOptions(opts...)
+	start, end := options.Start, options.End
+
+	qb.Where("skipped = ?", false)
+	qb.Where("id > ?", afterId)
+
+	if start != "" {
+		qb.Where("updated_at > ?", start)
+	}
+
+	if end != "" {
+		qb.Where("updated_at <= ?", end)
+	}
+
+	qb.OrderBy("id", "ASC")
+	qb.Limit(size)
+
+	q, args := qb.GetQuery()
+
+	rows, err := dr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query developers ids: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "developerRepo.FindIdsAfter"))
+		}
+	}()
+
+	ids := make([]int, 0, size)
+
+	for rows.Next() {
+		var id int
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan developers table, error: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("developerRepo.FindIdsAfter, rows error: %v", err)
+	}
+
+	return ids, nil
+}
+
+// Find the entities after afterId in id order with the signals of their sync schedule since the given time.
+func (dr *DeveloperRepo) FindSyncCandidatesAfter(ctx context.Context, afterId int, size int, since time.Time) ([]SyncCandidate, error) {
+	query := "SELECT d.id, d.username, d.followers, d.updated_at, " +
+		"(SELECT COUNT(DISTINCT t.trend_date) FROM trending_developers t WHERE t.developer_id = d.id AND t.trend_date >= ?), " +
+		"(SELECT COALESCE(SUM(p.views), 0) FROM page_views p WHERE p.entity_type = ? AND p.entity_id = d.id AND p.view_date >= ?) " +
+		"FROM developers d WHERE d.skipped = ? AND d.id > ? ORDER BY d.id ASC LIMIT ?"
+
+	sinceDate := since.Format(time.DateOnly)
+
+	rows, err := dr.db.QueryContext(ctx, query, sinceDate, EntityDeveloper, sinceDate, false, afterId, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query developers sync candidates: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "developerRepo.FindSyncCandidatesAfter"))
+		}
+	}()
+
+	candidates := make([]SyncCandidate, 0, size)
+
+	for rows.Next() {
+		var candidate SyncCandidate
+
+		if err := rows.Scan(
+			&candidate.Id,
+			&candidate.Name,
+			&candidate.Stars,
+			&candidate.LastSyncedAt,
+			&candidate.TrendingDays,
+			&candidate.PageViews,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan developers sync candidates, error: %v", err)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("developerRepo.FindSyncCandidatesAfter, rows error: %v", err)
+	}
+
+	return candidates, nil
+}
+
+// Find a page of developers which are not skipped.
+func (dr *DeveloperRepo) FindPage(ctx context.Context, params *DeveloperListParams) (Page[Developer], error) {
+	qb := dbutils.NewQueryBuilder()
+	qb.Query("select developers.* from developers")
+	qb.Where("developers.skipped = ?", false)
+
+	if params.Location != "" {
+		qb.Where("developers.location like ?", "%"+params.Location+"%")
+	}
+
+	if params.Company != "" {
+		qb.Where("developers.company like ?", "%"+params.Company+"%")
+	}
+
+	if params.TrendingLanguage != "" {
+		qb.Where("exists (select 1 from trending_developers where trending_developers.developer_id = developers.id and trending_developers.language = ?)", params.TrendingLanguage)
+	}
+
+	applyPage(qb, developerSorts, "developers.id", params.PageParams)
+
+	q, args := qb.GetQuery()
+
+	rows, err := dr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return Page[Developer]{}, fmt.Errorf("failed to query developers page: %v", err)
+	}
+
+	defer rows.Close()
+
+	developers := make([]Developer, 0, params.Limit+1)
+
+	for rows.Next() {
+		var dev Developer
+
+		if err := rows.Scan(
+			&dev.Id,
+			&dev.GhId,
+			&dev.Username,
+			&dev.AvatarUrl,
+			&dev.Name,
+			&dev.Company,
+			&dev.Blog,
+			&dev.Location,
+			&dev.Email,
+			&dev.Bio,
+			&dev.TwitterUsername,
+			&dev.PublicRepos,
+			&dev.PublicGists,
+			&dev.Followers,
+			&dev.Following,
+			&dev.CreatedAt,
+			&dev.UpdatedAt,
+			&dev.Skipped,
+		); err != nil {
+			return Page[Developer]{}, err
+		}
+
+		developers = append(developers, dev)
+	}
+
+	if err = rows.Err(); err != nil {
+		return Page[Developer]{}, err
+	}
+
+	return newPage(developers, params.PageParams, func(developer Developer) string {
+		return developerSortValue(params.Sort, developer)
+	}, func(developer Developer) int {
+		return developer.Id
+	}), nil
+}
+
 func (dr *DeveloperRepo) FindById(ctx context.Context, id int) (Developer, error) {
 	qb := dbutils.NewQueryBuilder()
 	qb.Query("select developers.*, trending_developers.`trend_date`, trending_developers.`rank`, trending_developers.`language` as `trending_language` from developers left join trending_developers on developers.id = trending_developers.developer_id")
@@ -185,6 +385,39 @@ func (dr *DeveloperRepo) FindById(ctx context.Context, id int) (Developer, error
 	return developer, nil
 }
 
+func (dr *DeveloperRepo) FindByUsername(ctx context.Context, username string) (Developer, error) {
+	query := "SELECT * FROM developers WHERE username = ?"
+
+	var developer Developer
+
+	row := dr.db.QueryRowContext(ctx, query, username)
+
+	if err := row.Scan(
+		&developer.Id,
+		&developer.GhId,
+		&developer.Username,
+		&developer.AvatarUrl,
+		&developer.Name,
+		&developer.Company,
+		&developer.Blog,
+		&developer.Location,
+		&developer.Email,
+		&developer.Bio,
+		&developer.TwitterUsername,
+		&developer.PublicRepos,
+		&developer.PublicGists,
+		&developer.Followers,
+		&developer.Following,
+		&developer.CreatedAt,
+		&developer.UpdatedAt,
+		&developer.Skipped,
+	); err != nil {
+		return developer, err
+	}
+
+	return developer, nil
+}
+
 func (dr *DeveloperRepo) FindTrendingDevelopers(ctx context.Context, opts ...any) ([]TrendingDeveloperResponse, error) {
 	query := "select developers.*, count(*) as count, min(trending_developers.`rank`) as best_ranking from developers join trending_developers on developers.id = trending_developers.developer_id"
 
@@ -266,6 +499,89 @@ func (dr *DeveloperRepo) FindTrendingDevelopers(ctx context.Context, opts ...any
 	return developers, nil
 }
 
+// Find developers who trended for the first time within the date range, the most recent debutants first.
+func (dr *DeveloperRepo) FindNewTrendingDevelopers(ctx context.Context, opts ...any) ([]NewTrendingDeveloperResponse, error) {
+	query := "select developers.*, count(*) as count, min(trending_developers.`rank`) as best_ranking, min(first_trendings.first_trend_date) as first_trend_date from developers " +
+		"join trending_developers on developers.id = trending_developers.developer_id " +
+		"join (select developer_id, min(trend_date) as first_trend_date from trending_developers where developer_id is not null group by developer_id) as first_trendings on first_trendings.developer_id = developers.id"
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(query)
+
+	qb.OrderBy("first_trend_date", "DESC")
+	qb.OrderBy("best_ranking", "ASC")
+	qb.OrderBy("developers.id", "ASC")
+
+	options := opt.ExtractOptions(opts...)
+	lang, dateRange, limit := options.Language, options.DateRange, options.Limit
+
+	if lang != "" {
+		qb.Where("`trending_developers`.`language` = ?", lang)
+	} else {
+		qb.Where("`trending_developers`.`language` is null", nil)
+	}
+
+	since := time.Now().AddDate(0, 0, -dateRange).Format("2006-01-02")
+	qb.Where("`trending_developers`.`trend_date` > ?", since)
+	qb.Where("first_trendings.first_trend_date > ?", since)
+
+	if limit > 0 {
+		qb.Limit(limit)
+	}
+
+	qb.GroupBy("developers.id")
+
+	q, args := qb.GetQuery()
+
+	rows, err := dr.db.QueryContext(ctx, q, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query new trending developers: %v", err)
+	}
+
+	defer rows.Close()
+
+	developers := make([]NewTrendingDeveloperResponse, 0)
+
+	for rows.Next() {
+		var dev NewTrendingDeveloperResponse
+
+		if err := rows.Scan(
+			&dev.Id,
+			&dev.GhId,
+			&dev.Username,
+			&dev.AvatarUrl,
+			&dev.Name,
+			&dev.Company,
+			&dev.Blog,
+			&dev.Location,
+			&dev.Email,
+			&dev.Bio,
+			&dev.TwitterUsername,
+			&dev.PublicRepos,
+			&dev.PublicGists,
+			&dev.Followers,
+			&dev.Following,
+			&dev.CreatedAt,
+			&dev.UpdatedAt,
+			&dev.Skipped,
+			&dev.FeaturedCount,
+			&dev.BestRanking,
+			&dev.FirstTrendDate,
+		); err != nil {
+			return nil, err
+		}
+
+		developers = append(developers, dev)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return developers, nil
+}
+
 func (dr *DeveloperRepo) Update(ctx context.Context, developer Developer) error {
 	query := "UPDATE `developers` SET avatar_url = ?, name = ?, company = ?, blog = ?, location = ?, email = ?, bio = ?, twitter_username = ?, public_repos = ?, public_gists = ?, followers = ?, following = ?, skipped = ?, created_at = ?, updated_at = ? WHERE id = ?"
 
@@ -307,8 +623,11 @@ func (dr *DeveloperRepo) Update(ctx context.Context, developer Developer) error
 	return nil
 }
 
+// Save a new developer and return its id. Concurrent syncs and fetches may save the same developer,
+// the one saved first is kept and its id is returned.
 func (dr *DeveloperRepo) Save(ctx context.Context, developer Developer) (int64, error) {
-	query := "INSERT INTO `developers` (`gh_id`, `username`, `avatar_url`, `name`, `company`, `blog`, `location`, `email`, `bio`, `twitter_username`, `public_repos`, `public_gists`, `followers`, `following`, `created_at`, `updated_at`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	query := "INSERT INTO `developers` (`gh_id`, `username`, `avatar_url`, `name`, `company`, `blog`, `location`, `email`, `bio`, `twitter_username`, `public_repos`, `public_gists`, `followers`, `following`, `created_at`, `updated_at`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `id` = LAST_INSERT_ID(`id`)"
 
 	var lastInsertId int64
 
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+var developerSorts = map[string]sortColumn{
+	"followers":    {expression: "developers.followers", numeric: true},
+	"public_repos": {expression: "developers.public_repos", numeric: true},
+	"created_at":   {expression: "developers.created_at"},
+}
+
+// PublicDeveloper is a developer as listed publicly, without the contact email and our sync details.
+type PublicDeveloper struct {
+	Id              int                `json:"developer_id"`
+	GhId            int                `json:"id"`
+	Username        string             `json:"login"`
+	AvatarUrl       string             `json:"avatar_url"`
+	Name            dbutils.NullString `json:"name"`
+	Company         dbutils.NullString `json:"company"`
+	Blog            dbutils.NullString `json:"blog"`
+	Location        dbutils.NullString `json:"location"`
+	Bio             dbutils.NullString `json:"bio"`
+	TwitterUsername dbutils.NullString `json:"twitter_username"`
+	PublicRepos     int                `json:"public_repos"`
+	PublicGists     int                `json:"public_gists"`
+	Followers       int                `json:"followers"`
+	Following       int                `json:"following"`
+	CreatedAt       time.Time          `json:"created_at"` // It is the datetime the developer was created on GitHub.
+}
+
+func NewPublicDeveloper(developer Developer) PublicDeveloper {
+	return PublicDeveloper{
+		Id:              developer.Id,
+		GhId:            developer.GhId,
+		Username:        developer.Username,
+		AvatarUrl:       developer.AvatarUrl,
+		Name:            developer.Name,
+		Company:         developer.Company,
+		Blog:            developer.Blog,
+		Location:        developer.Location,
+		Bio:             developer.Bio,
+		TwitterUsername: developer.TwitterUsername,
+		PublicRepos:     developer.PublicRepos,
+		PublicGists:     developer.PublicGists,
+		Followers:       developer.Followers,
+		Following:       developer.Following,
+		CreatedAt:       developer.CreatedAt,
+	}
+}
+
+// Skipped developers are never listed.
+type DeveloperListParams struct {
+	PageParams
+	Location         string // partial match.
+	Company          string // partial match, a leading @ is ignored.
+	TrendingLanguage string // developers who trended on the trending page of this language.
+}
+
+func NewDeveloperListParams(sortStr, orderStr, limitStr, cursorStr, locationStr, companyStr, trendingLanguageStr string) (*DeveloperListParams, error) {
+	pageParams, err := newPageParams(developerSorts, sortStr, orderStr, limitStr, cursorStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeveloperListParams{
+		PageParams:       pageParams,
+		Location:         strings.TrimSpace(locationStr),
+		Company:          strings.TrimPrefix(strings.TrimSpace(companyStr), "@"),
+		TrendingLanguage: strings.TrimSpace(trendingLanguageStr),
+	}, nil
+}
+
+func developerSortValue(sort string, developer Developer) string {
+	switch sort {
+	case "followers":
+		return strconv.Itoa(developer.Followers)
+	case "public_repos":
+		return strconv.Itoa(developer.PublicRepos)
+	default:
+		return cursorTime(developer.CreatedAt)
+	}
+}
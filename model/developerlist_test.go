@@ -0,0 +1,32 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeveloperListParams(t *testing.T) {
+	params, err := NewDeveloperListParams("public_repos", "asc", "10", "", " Berlin ", " @github ", "Go")
+	assert.NoError(t, err)
+	assert.Equal(t, &DeveloperListParams{
+		PageParams:       PageParams{Sort: "public_repos", Order: SortOrderAsc, Limit: 10},
+		Location:         "Berlin",
+		Company:          "github",
+		TrendingLanguage: "Go",
+	}, params)
+
+	for _, sort := range []string{"updated_at", "email", ""} {
+		_, err := NewDeveloperListParams(sort, "", "", "", "", "", "")
+		assert.Error(t, err, sort)
+	}
+}
+
+func TestDeveloperSortValue(t *testing.T) {
+	developer := Developer{Followers: 5, PublicRepos: 7, CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	assert.Equal(t, "5", developerSortValue("followers", developer))
+	assert.Equal(t, "7", developerSortValue("public_repos", developer))
+	assert.Equal(t, "2020-01-02 03:04:05.000", developerSortValue("created_at", developer))
+}
@@ -0,0 +1,282 @@
+package model
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const developerTopRepositories = 10
+
+// DeveloperRepository is a non-fork repository owned by a developer on GitHub.
+type DeveloperRepository struct {
+	FullName     string             `json:"full_name"`
+	Description  dbutils.NullString `json:"description"`
+	Stars        int                `json:"stars"`
+	Forks        int                `json:"forks"`
+	Language     dbutils.NullString `json:"language"`
+	RepositoryId dbutils.NullInt64  `json:"repository_id"` // set when the repository is also tracked in the repositories table.
+}
+
+// OwnedRepositories are the non-fork repositories owned by a developer on GitHub, most starred first.
+type OwnedRepositories struct {
+	Total int                   // number of owned repositories.
+	Top   []DeveloperRepository // the most starred ones with their details.
+	Stats []DeveloperRepository // the stars and language of up to the 100 most starred ones, they hold nearly all the stars.
+}
+
+type DeveloperLanguage struct {
+	Language     string `json:"language"`
+	Repositories int    `json:"repositories"`
+	Stars        int    `json:"stars"`
+}
+
+type DeveloperContribution struct {
+	Year          int `json:"year"`
+	Contributions int `json:"contributions"`
+}
+
+type DeveloperProfile struct {
+	TotalStars        int                     `json:"total_stars"`
+	TotalRepositories int                     `json:"total_repositories"`
+	TopRepositories   []DeveloperRepository   `json:"top_repositories"`
+	Languages         []DeveloperLanguage     `json:"languages"`
+	Contributions     []DeveloperContribution `json:"contributions"`
+	UpdatedAt         dbutils.NullTime        `json:"updated_at"`
+}
+
+type DeveloperWithProfile struct {
+	Developer
+	Profile       DeveloperProfile        `json:"profile"`
+	ContributesTo []ContributedRepository `json:"contributes_to"`
+}
+
+// Aggregate the owned repositories of a developer into total stars, a language breakdown and the most starred repositories.
+func NewDeveloperProfile(owned OwnedRepositories, contributions []DeveloperContribution) DeveloperProfile {
+	profile := DeveloperProfile{
+		TotalRepositories: owned.Total,
+		TopRepositories:   make([]DeveloperRepository, 0),
+		Languages:         make([]DeveloperLanguage, 0),
+		Contributions:     make([]DeveloperContribution, 0, len(contributions)),
+	}
+
+	languages := dbutils.NewCollectionMap[string, *DeveloperLanguage]()
+
+	for _, repository := range owned.Stats {
+		profile.TotalStars += repository.Stars
+
+		if !repository.Language.Valid {
+			continue
+		}
+
+		if !languages.Has(repository.Language.String) {
+			languages.Set(repository.Language.String, &DeveloperLanguage{Language: repository.Language.String})
+		}
+
+		language := languages.Get(repository.Language.String)
+		language.Repositories++
+		language.Stars += repository.Stars
+	}
+
+	for _, language := range languages.All() {
+		profile.Languages = append(profile.Languages, *language)
+	}
+
+	slices.SortStableFunc(profile.Languages, func(a, b DeveloperLanguage) int {
+		return cmp.Or(cmp.Compare(b.Stars, a.Stars), cmp.Compare(b.Repositories, a.Repositories))
+	})
+
+	top := slices.Clone(owned.Top)
+	slices.SortStableFunc(top, func(a, b DeveloperRepository) int {
+		return cmp.Compare(b.Stars, a.Stars)
+	})
+
+	profile.TopRepositories = append(profile.TopRepositories, top[:min(len(top), developerTopRepositories)]...)
+
+	profile.Contributions = append(profile.Contributions, contributions...)
+	slices.SortFunc(profile.Contributions, func(a, b DeveloperContribution) int {
+		return cmp.Compare(a.Year, b.Year)
+	})
+
+	return profile
+}
+
+type DeveloperProfileRepo struct {
+	db database.DB
+}
+
+func NewDeveloperProfileRepo(db database.DB) *DeveloperProfileRepo {
+	return &DeveloperProfileRepo{
+		db: db,
+	}
+}
+
+// Replace the stored profile of a developer.
+func (dpr *DeveloperProfileRepo) Save(ctx context.Context, developerId int, profile DeveloperProfile) error {
+	tx, err := dpr.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to begin developer profile transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	now := time.Now().Format(time.DateTime)
+
+	query := "INSERT INTO `developer_profiles` (`developer_id`, `total_stars`, `total_repositories`, `created_at`, `updated_at`) VALUES (?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `total_stars` = VALUES(`total_stars`), `total_repositories` = VALUES(`total_repositories`), `updated_at` = VALUES(`updated_at`)"
+
+	if _, err := tx.ExecContext(ctx, query, developerId, profile.TotalStars, profile.TotalRepositories, now, now); err != nil {
+		return fmt.Errorf("failed to run upsert developer_profiles query, developer id: %d, error: %v", developerId, err)
+	}
+
+	for _, table := range []string{"developer_owned_repositories", "developer_languages", "developer_contributions"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM `%s` WHERE developer_id = ?", table), developerId); err != nil {
+			return fmt.Errorf("failed to run delete %s query, developer id: %d, error: %v", table, developerId, err)
+		}
+	}
+
+	for _, repository := range profile.TopRepositories {
+		query := "INSERT INTO `developer_owned_repositories` (`developer_id`, `full_name`, `description`, `stars`, `forks`, `language`) VALUES (?, ?, ?, ?, ?, ?)"
+
+		if _, err := tx.ExecContext(ctx, query, developerId, repository.FullName, repository.Description, repository.Stars, repository.Forks, repository.Language); err != nil {
+			return fmt.Errorf("failed to run insert developer_owned_repositories query, developer id: %d, repository: %s, error: %v", developerId, repository.FullName, err)
+		}
+	}
+
+	for _, language := range profile.Languages {
+		query := "INSERT INTO `developer_languages` (`developer_id`, `language`, `repositories`, `stars`) VALUES (?, ?, ?, ?)"
+
+		if _, err := tx.ExecContext(ctx, query, developerId, language.Language, language.Repositories, language.Stars); err != nil {
+			return fmt.Errorf("failed to run insert developer_languages query, developer id: %d, language: %s, error: %v", developerId, language.Language, err)
+		}
+	}
+
+	for _, contribution := range profile.Contributions {
+		query := "INSERT INTO `developer_contributions` (`developer_id`, `year`, `contributions`) VALUES (?, ?, ?)"
+
+		if _, err := tx.ExecContext(ctx, query, developerId, contribution.Year, contribution.Contributions); err != nil {
+			return fmt.Errorf("failed to run insert developer_contributions query, developer id: %d, year: %d, error: %v", developerId, contribution.Year, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Find the stored profile of a developer, it returns an empty profile if the developer has not been enriched yet.
+func (dpr *DeveloperProfileRepo) FindByDeveloperId(ctx context.Context, developerId int) (DeveloperProfile, error) {
+	profile := DeveloperProfile{
+		TopRepositories: make([]DeveloperRepository, 0),
+		Languages:       make([]DeveloperLanguage, 0),
+		Contributions:   make([]DeveloperContribution, 0),
+	}
+
+	query := "SELECT total_stars, total_repositories, updated_at FROM developer_profiles WHERE developer_id = ?"
+
+	rows, err := dpr.db.QueryContext(ctx, query, developerId)
+	if err != nil {
+		return profile, fmt.Errorf("failed to find developer profile, error: %v", err)
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(&profile.TotalStars, &profile.TotalRepositories, &profile.UpdatedAt); err != nil {
+			rows.Close()
+			return profile, fmt.Errorf("failed to scan developer_profiles table, error: %v", err)
+		}
+	}
+
+	if err := closeRows(rows, "developerProfileRepo.FindByDeveloperId"); err != nil {
+		return profile, err
+	}
+
+	// Owned repositories are linked at read time so repositories tracked later are linked too.
+	query = "SELECT dor.full_name, dor.description, dor.stars, dor.forks, dor.language, repositories.id FROM developer_owned_repositories dor LEFT JOIN repositories ON repositories.full_name = dor.full_name WHERE dor.developer_id = ? ORDER BY dor.stars DESC, dor.id ASC"
+
+	rows, err = dpr.db.QueryContext(ctx, query, developerId)
+	if err != nil {
+		return profile, fmt.Errorf("failed to find developer owned repositories, error: %v", err)
+	}
+
+	for rows.Next() {
+		var repository DeveloperRepository
+
+		if err := rows.Scan(&repository.FullName, &repository.Description, &repository.Stars, &repository.Forks, &repository.Language, &repository.RepositoryId); err != nil {
+			rows.Close()
+			return profile, fmt.Errorf("failed to scan developer_owned_repositories table, error: %v", err)
+		}
+
+		profile.TopRepositories = append(profile.TopRepositories, repository)
+	}
+
+	if err := closeRows(rows, "developerProfileRepo.FindByDeveloperId"); err != nil {
+		return profile, err
+	}
+
+	query = "SELECT language, repositories, stars FROM developer_languages WHERE developer_id = ? ORDER BY stars DESC, repositories DESC"
+
+	rows, err = dpr.db.QueryContext(ctx, query, developerId)
+	if err != nil {
+		return profile, fmt.Errorf("failed to find developer languages, error: %v", err)
+	}
+
+	for rows.Next() {
+		var language DeveloperLanguage
+
+		if err := rows.Scan(&language.Language, &language.Repositories, &language.Stars); err != nil {
+			rows.Close()
+			return profile, fmt.Errorf("failed to scan developer_languages table, error: %v", err)
+		}
+
+		profile.Languages = append(profile.Languages, language)
+	}
+
+	if err := closeRows(rows, "developerProfileRepo.FindByDeveloperId"); err != nil {
+		return profile, err
+	}
+
+	query = "SELECT year, contributions FROM developer_contributions WHERE developer_id = ? ORDER BY year ASC"
+
+	rows, err = dpr.db.QueryContext(ctx, query, developerId)
+	if err != nil {
+		return profile, fmt.Errorf("failed to find developer contributions, error: %v", err)
+	}
+
+	for rows.Next() {
+		var contribution DeveloperContribution
+
+		if err := rows.Scan(&contribution.Year, &contribution.Contributions); err != nil {
+			rows.Close()
+			return profile, fmt.Errorf("failed to scan developer_contributions table, error: %v", err)
+		}
+
+		profile.Contributions = append(profile.Contributions, contribution)
+	}
+
+	if err := closeRows(rows, "developerProfileRepo.FindByDeveloperId"); err != nil {
+		return profile, err
+	}
+
+	return profile, nil
+}
+
+// Check the rows error and close the rows, so several queries can run one after another in the same method.
+func closeRows(rows *sql.Rows, action string) error {
+	rowsErr := rows.Err()
+
+	if err := rows.Close(); err != nil {
+		slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", action))
+	}
+
+	if rowsErr != nil {
+		return fmt.Errorf("%s, rows error: %v", action, rowsErr)
+	}
+
+	return nil
+}
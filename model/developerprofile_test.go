@@ -0,0 +1,43 @@
+package model
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeveloperProfile(t *testing.T) {
+	language := func(name string) dbutils.NullString {
+		return dbutils.NullString{NullString: sql.NullString{String: name, Valid: name != ""}}
+	}
+
+	owned := []DeveloperRepository{
+		{FullName: "dev/cli", Stars: 10, Language: language("Go")},
+		{FullName: "dev/web", Stars: 300, Language: language("TypeScript")},
+		{FullName: "dev/api", Stars: 200, Language: language("Go")},
+		{FullName: "dev/dotfiles", Stars: 5, Language: language("")},
+	}
+
+	contributions := []DeveloperContribution{
+		{Year: 2024, Contributions: 900},
+		{Year: 2023, Contributions: 800},
+	}
+
+	profile := NewDeveloperProfile(OwnedRepositories{Total: 12, Top: owned, Stats: owned}, contributions)
+
+	assert.Equal(t, 515, profile.TotalStars)
+	assert.Equal(t, 12, profile.TotalRepositories)
+	assert.Equal(t, []DeveloperLanguage{
+		{Language: "TypeScript", Repositories: 1, Stars: 300},
+		{Language: "Go", Repositories: 2, Stars: 210},
+	}, profile.Languages)
+
+	assert.Len(t, profile.TopRepositories, 4)
+	assert.Equal(t, "dev/web", profile.TopRepositories[0].FullName)
+	assert.Equal(t, "dev/dotfiles", profile.TopRepositories[3].FullName)
+
+	assert.Equal(t, 2023, profile.Contributions[0].Year)
+	assert.Equal(t, 2024, profile.Contributions[1].Year)
+}
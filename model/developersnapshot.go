@@ -0,0 +1,94 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/liweiyi88/trendshift-backend/database"
+)
+
+// DeveloperSnapshot is the followers and public repositories of a developer at the time it was synced.
+type DeveloperSnapshot struct {
+	Id          int       `json:"id"`
+	DeveloperId int       `json:"developer_id"`
+	Followers   int       `json:"followers"`
+	PublicRepos int       `json:"public_repos"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type DeveloperSnapshotRepo struct {
+	db database.DB
+}
+
+func NewDeveloperSnapshotRepo(db database.DB) *DeveloperSnapshotRepo {
+	return &DeveloperSnapshotRepo{
+		db: db,
+	}
+}
+
+func (dsr *DeveloperSnapshotRepo) Save(ctx context.Context, developer Developer) error {
+	query := "INSERT INTO `developer_snapshots` (`developer_id`, `followers`, `public_repos`, `created_at`) VALUES (?, ?, ?, ?)"
+
+	createdAt := time.Now()
+
+	result, err := dsr.db.ExecContext(ctx, query, developer.Id, developer.Followers, developer.PublicRepos, createdAt.Format(time.DateTime))
+	if err != nil {
+		return fmt.Errorf("failed to exec insert developer_snapshots query to db, developer id: %d, error: %v", developer.Id, err)
+	}
+
+	_, err = result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("developer_snapshots insert rows affected returns error: %v", err)
+	}
+
+	return nil
+}
+
+// Returns the followers and public repositories history of a developer, oldest first. Pass a zero since to get the full history.
+func (dsr *DeveloperSnapshotRepo) FindByDeveloperId(ctx context.Context, developerId int, since time.Time) ([]DeveloperSnapshot, error) {
+	qb := sq.Select("id", "developer_id", "followers", "public_repos", "created_at").
+		From("developer_snapshots").
+		Where("developer_id = ?", developerId).
+		OrderBy("created_at ASC", "id ASC")
+
+	if !since.IsZero() {
+		qb = qb.Where("created_at >= ?", since.Format(time.DateTime))
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SQL when find developer snapshots, error: %v", err)
+	}
+
+	rows, err := dsr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find developer snapshots, error: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "developerSnapshotRepo.FindByDeveloperId"))
+		}
+	}()
+
+	snapshots := make([]DeveloperSnapshot, 0)
+
+	for rows.Next() {
+		var snapshot DeveloperSnapshot
+
+		if err := rows.Scan(&snapshot.Id, &snapshot.DeveloperId, &snapshot.Followers, &snapshot.PublicRepos, &snapshot.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan developer_snapshots table, error: %v", err)
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("developerSnapshotRepo.FindByDeveloperId, rows error: %v", err)
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,366 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+// Statuses of a repository or developer, any status but active hides the entity, i.e. it is skipped.
+const (
+	StatusActive      = "active"
+	StatusNotFound    = "not_found"
+	StatusDmcaBlocked = "dmca_blocked"
+	StatusSuspended   = "suspended"
+	StatusDeleted     = "deleted" // deleted by an admin, it is never rechecked.
+)
+
+const (
+	baseRecheckDelay = 24 * time.Hour
+	maxRecheckDelay  = 90 * 24 * time.Hour
+)
+
+var entityTables = map[string]string{
+	EntityRepository: "repositories",
+	EntityDeveloper:  "developers",
+}
+
+// EntityStatus is the status of a repository or developer which is not active, an active entity has no status row.
+type EntityStatus struct {
+	EntityType string             `json:"entity_type"`
+	EntityId   int                `json:"entity_id"`
+	Status     string             `json:"status"`
+	Reason     dbutils.NullString `json:"reason"`
+	ChangedAt  time.Time          `json:"changed_at"`
+	Checks     int                `json:"checks"`     // consecutive checks which found the entity unavailable.
+	RecheckAt  dbutils.NullTime   `json:"recheck_at"` // null when the entity is not rechecked.
+}
+
+func ParseEntityType(entityType string) (string, error) {
+	if _, ok := entityTables[entityType]; !ok {
+		return "", fmt.Errorf("invalid entity type, expected: repository or developer, passed %s", entityType)
+	}
+
+	return entityType, nil
+}
+
+func ParseEntityStatus(status string) (string, error) {
+	switch status {
+	case "", StatusNotFound, StatusDmcaBlocked, StatusSuspended, StatusDeleted:
+		return status, nil
+	default:
+		return "", fmt.Errorf("invalid entity status, expected: not_found, dmca_blocked, suspended or deleted, passed %s", status)
+	}
+}
+
+// Returns when an entity found unavailable by the given number of consecutive checks is checked again,
+// the delay doubles from a day after each check up to 90 days.
+func RecheckAt(checks int, checkedAt time.Time) time.Time {
+	delay := baseRecheckDelay
+
+	for i := 1; i < checks && delay < maxRecheckDelay; i++ {
+		delay *= 2
+	}
+
+	return checkedAt.Add(min(delay, maxRecheckDelay))
+}
+
+type EntityStatusRepo struct {
+	db database.DB
+}
+
+func NewEntityStatusRepo(db database.DB) *EntityStatusRepo {
+	return &EntityStatusRepo{
+		db: db,
+	}
+}
+
+// Returns the status of an entity, an entity without status row is active.
+func (esr *EntityStatusRepo) FindByEntity(ctx context.Context, entityType string, entityId int) (EntityStatus, error) {
+	status := EntityStatus{EntityType: entityType, EntityId: entityId, Status: StatusActive}
+
+	query := "SELECT status, reason, changed_at, checks, recheck_at FROM entity_statuses WHERE entity_type = ? AND entity_id = ?"
+
+	err := esr.db.QueryRowContext(ctx, query, entityType, entityId).Scan(&status.Status, &status.Reason, &status.ChangedAt, &status.Checks, &status.RecheckAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return status, fmt.Errorf("failed to query entity status, %s id: %d, error: %v", entityType, entityId, err)
+	}
+
+	return status, nil
+}
+
+// Find the statuses of an entity type, optionally filtered by status, most recently changed first.
+func (esr *EntityStatusRepo) FindAll(ctx context.Context, entityType, status string, limit int) ([]EntityStatus, error) {
+	query := "SELECT entity_type, entity_id, status, reason, changed_at, checks, recheck_at FROM entity_statuses WHERE entity_type = ?"
+	args := []any{entityType}
+
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+
+	query += " ORDER BY changed_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := esr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entity statuses: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "entityStatusRepo.FindAll"))
+		}
+	}()
+
+	statuses := make([]EntityStatus, 0)
+
+	for rows.Next() {
+		var s EntityStatus
+
+		if err := rows.Scan(&s.EntityType, &s.EntityId, &s.Status, &s.Reason, &s.ChangedAt, &s.Checks, &s.RecheckAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entity_statuses table, error: %v", err)
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("entityStatusRepo.FindAll, rows error: %v", err)
+	}
+
+	return statuses, nil
+}
+
+// Find the ids of the unavailable entities due to be checked again at the given time, the longest overdue first.
+func (esr *EntityStatusRepo) FindDueRechecks(ctx context.Context, entityType string, at time.Time, limit int) ([]int, error) {
+	query := "SELECT entity_id FROM entity_statuses WHERE entity_type = ? AND recheck_at <= ? ORDER BY recheck_at LIMIT ?"
+
+	rows, err := esr.db.QueryContext(ctx, query, entityType, at.Format(time.DateTime), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due entity rechecks: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "entityStatusRepo.FindDueRechecks"))
+		}
+	}()
+
+	ids := make([]int, 0)
+
+	for rows.Next() {
+		var id int
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan entity_statuses table, error: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("entityStatusRepo.FindDueRechecks, rows error: %v", err)
+	}
+
+	return ids, nil
+}
+
+// Record that a check found an entity unavailable and hide it. The checks are counted while the status stays the same,
+// each one pushes back the next recheck.
+func (esr *EntityStatusRepo) MarkUnavailable(ctx context.Context, entityType string, entityId int, status, reason string) (EntityStatus, error) {
+	now := time.Now()
+
+	entityStatus := EntityStatus{
+		EntityType: entityType,
+		EntityId:   entityId,
+		Status:     status,
+		Reason:     dbutils.NewNullString(reason),
+		ChangedAt:  now,
+	}
+
+	tx, err := esr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entityStatus, fmt.Errorf("failed to begin entity status transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	var current EntityStatus
+
+	query := "SELECT status, changed_at, checks FROM entity_statuses WHERE entity_type = ? AND entity_id = ? FOR UPDATE"
+
+	err = tx.QueryRowContext(ctx, query, entityType, entityId).Scan(&current.Status, &current.ChangedAt, &current.Checks)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return entityStatus, fmt.Errorf("failed to query entity status, %s id: %d, error: %v", entityType, entityId, err)
+	}
+
+	if err == nil && current.Status == status {
+		entityStatus.ChangedAt = current.ChangedAt
+		entityStatus.Checks = current.Checks
+	}
+
+	entityStatus.Checks++
+	entityStatus.RecheckAt = dbutils.NewNullTime(RecheckAt(entityStatus.Checks, now))
+
+	if err := upsertEntityStatus(ctx, tx, entityStatus); err != nil {
+		return entityStatus, err
+	}
+
+	if err := setSkipped(ctx, tx, entityType, entityId, true); err != nil {
+		return entityStatus, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entityStatus, fmt.Errorf("failed to commit entity status transaction: %v", err)
+	}
+
+	return entityStatus, nil
+}
+
+// Clear the status of an entity after a check found it available, the caller saves the entity as not skipped.
+func (esr *EntityStatusRepo) Activate(ctx context.Context, entityType string, entityId int) error {
+	query := "DELETE FROM entity_statuses WHERE entity_type = ? AND entity_id = ?"
+
+	if _, err := esr.db.ExecContext(ctx, query, entityType, entityId); err != nil {
+		return fmt.Errorf("failed to run delete entity_statuses query, %s id: %d, error: %v", entityType, entityId, err)
+	}
+
+	return nil
+}
+
+// Manually make an entity active and visible again, whatever its status. It returns sql.ErrNoRows when the entity does not exist.
+func (esr *EntityStatusRepo) Restore(ctx context.Context, entityType string, entityId int) error {
+	tx, err := esr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin entity status transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	if err := lockEntity(ctx, tx, entityType, entityId); err != nil {
+		return err
+	}
+
+	query := "DELETE FROM entity_statuses WHERE entity_type = ? AND entity_id = ?"
+
+	if _, err := tx.ExecContext(ctx, query, entityType, entityId); err != nil {
+		return fmt.Errorf("failed to run delete entity_statuses query, %s id: %d, error: %v", entityType, entityId, err)
+	}
+
+	if err := setSkipped(ctx, tx, entityType, entityId, false); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit entity status transaction: %v", err)
+	}
+
+	return nil
+}
+
+// Manually soft delete an entity, it is hidden and never rechecked until it is restored.
+// It returns sql.ErrNoRows when the entity does not exist.
+func (esr *EntityStatusRepo) Purge(ctx context.Context, entityType string, entityId int, reason string) (EntityStatus, error) {
+	entityStatus := EntityStatus{
+		EntityType: entityType,
+		EntityId:   entityId,
+		Status:     StatusDeleted,
+		Reason:     dbutils.NewNullString(reason),
+		ChangedAt:  time.Now(),
+	}
+
+	tx, err := esr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entityStatus, fmt.Errorf("failed to begin entity status transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	if err := lockEntity(ctx, tx, entityType, entityId); err != nil {
+		return entityStatus, err
+	}
+
+	if err := upsertEntityStatus(ctx, tx, entityStatus); err != nil {
+		return entityStatus, err
+	}
+
+	if err := setSkipped(ctx, tx, entityType, entityId, true); err != nil {
+		return entityStatus, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entityStatus, fmt.Errorf("failed to commit entity status transaction: %v", err)
+	}
+
+	return entityStatus, nil
+}
+
+func upsertEntityStatus(ctx context.Context, tx *sql.Tx, status EntityStatus) error {
+	query := "INSERT INTO `entity_statuses` (`entity_type`, `entity_id`, `status`, `reason`, `changed_at`, `checks`, `recheck_at`) VALUES (?, ?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `status` = VALUES(`status`), `reason` = VALUES(`reason`), `changed_at` = VALUES(`changed_at`), `checks` = VALUES(`checks`), `recheck_at` = VALUES(`recheck_at`)"
+
+	var recheckAt any
+	if status.RecheckAt.Valid {
+		recheckAt = status.RecheckAt.Time.Format(time.DateTime)
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		query,
+		status.EntityType,
+		status.EntityId,
+		status.Status,
+		status.Reason,
+		status.ChangedAt.Format(time.DateTime),
+		status.Checks,
+		recheckAt,
+	); err != nil {
+		return fmt.Errorf("failed to run upsert entity_statuses query, %s id: %d, error: %v", status.EntityType, status.EntityId, err)
+	}
+
+	return nil
+}
+
+// Lock the row of an entity for the transaction, it returns sql.ErrNoRows when the entity does not exist.
+func lockEntity(ctx context.Context, tx *sql.Tx, entityType string, entityId int) error {
+	table, ok := entityTables[entityType]
+	if !ok {
+		return fmt.Errorf("invalid entity type: %s", entityType)
+	}
+
+	var id int
+
+	query := fmt.Sprintf("SELECT id FROM `%s` WHERE id = ? FOR UPDATE", table)
+
+	if err := tx.QueryRowContext(ctx, query, entityId).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		return fmt.Errorf("failed to query %s, id: %d, error: %v", table, entityId, err)
+	}
+
+	return nil
+}
+
+func setSkipped(ctx context.Context, tx *sql.Tx, entityType string, entityId int, skipped bool) error {
+	table, ok := entityTables[entityType]
+	if !ok {
+		return fmt.Errorf("invalid entity type: %s", entityType)
+	}
+
+	query := fmt.Sprintf("UPDATE `%s` SET `skipped` = ?, `updated_at` = ? WHERE id = ?", table)
+
+	if _, err := tx.ExecContext(ctx, query, skipped, time.Now().Format(time.DateTime), entityId); err != nil {
+		return fmt.Errorf("failed to run update %s skipped query, id: %d, error: %v", table, entityId, err)
+	}
+
+	return nil
+}
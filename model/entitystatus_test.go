@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecheckAt(t *testing.T) {
+	checkedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, checkedAt.Add(24*time.Hour), RecheckAt(1, checkedAt))
+	assert.Equal(t, checkedAt.Add(2*24*time.Hour), RecheckAt(2, checkedAt))
+	assert.Equal(t, checkedAt.Add(8*24*time.Hour), RecheckAt(4, checkedAt))
+	assert.Equal(t, checkedAt.Add(90*24*time.Hour), RecheckAt(20, checkedAt))
+}
+
+func TestParseEntityStatus(t *testing.T) {
+	for _, status := range []string{"", StatusNotFound, StatusDmcaBlocked, StatusSuspended, StatusDeleted} {
+		parsed, err := ParseEntityStatus(status)
+		assert.Nil(t, err)
+		assert.Equal(t, status, parsed)
+	}
+
+	_, err := ParseEntityStatus(StatusActive)
+	assert.NotNil(t, err)
+
+	_, err = ParseEntityType("organization")
+	assert.NotNil(t, err)
+}
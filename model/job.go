@@ -0,0 +1,384 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-sql-driver/mysql"
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const (
+	JobFetchRepository      = "fetch_repository" // payload is the full name of the repository.
+	JobFetchDeveloper       = "fetch_developer"  // payload is the username of the developer.
+	JobSyncRepository       = "sync_repository"  // payload is the id of the repository.
+	JobSyncDeveloper        = "sync_developer"   // payload is the id of the developer.
+	JobIngestMonthlyInsight = "ingest_monthly_insight"
+)
+
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDead    = "dead" // failed too many times, kept for inspection until it is retried manually.
+)
+
+var (
+	// A dead job can not be requeued while a job of the same kind and payload is pending or running.
+	ErrJobAlreadyActive = errors.New("a job of the same kind and payload is already pending or running")
+
+	// The job ran past the lock timeout and was claimed again, its outcome is recorded by the new claim.
+	ErrJobLockLost = errors.New("the job lock is lost")
+)
+
+const (
+	mysqlDuplicateEntry   = 1062
+	defaultJobMaxAttempts = 5
+	jobInsertBatchSize    = 500
+)
+
+// Job is a unit of work of the queue, jobs are deleted once they are done.
+type Job struct {
+	Id          int64              `json:"id"`
+	Kind        string             `json:"kind"`
+	Payload     string             `json:"payload"`
+	Status      string             `json:"status"`
+	Attempts    int                `json:"attempts"`
+	MaxAttempts int                `json:"max_attempts"`
+	LastError   dbutils.NullString `json:"last_error"`
+	RunAt       time.Time          `json:"run_at"`
+	LockedAt    dbutils.NullTime   `json:"locked_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+type JobRepo struct {
+	db database.DB
+}
+
+func NewJobRepo(db database.DB) *JobRepo {
+	return &JobRepo{
+		db: db,
+	}
+}
+
+// Enqueue a job per payload, payloads which already have a pending or running job of the same kind are ignored.
+// It returns the number of enqueued jobs.
+func (jr *JobRepo) Enqueue(ctx context.Context, kind string, payloads ...string) (int, error) {
+	return jr.EnqueueWithMaxAttempts(ctx, kind, defaultJobMaxAttempts, payloads...)
+}
+
+// Enqueue jobs like Enqueue, they are moved to the dead letter once they failed maxAttempts times.
+func (jr *JobRepo) EnqueueWithMaxAttempts(ctx context.Context, kind string, maxAttempts int, payloads ...string) (int, error) {
+	enqueued := 0
+	now := time.Now().Format(time.DateTime)
+
+	for start := 0; start < len(payloads); start += jobInsertBatchSize {
+		end := min(start+jobInsertBatchSize, len(payloads))
+
+		qb := sq.Insert("jobs").
+			Options("IGNORE").
+			Columns("kind", "payload", "status", "max_attempts", "run_at", "created_at", "updated_at")
+
+		for _, payload := range payloads[start:end] {
+			qb = qb.Values(kind, payload, JobPending, max(maxAttempts, 1), now, now, now)
+		}
+
+		query, args, err := qb.ToSql()
+		if err != nil {
+			return enqueued, fmt.Errorf("failed to build jobs insert query: %v", err)
+		}
+
+		result, err := jr.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return enqueued, fmt.Errorf("failed to exec insert jobs query to db, error: %v", err)
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return enqueued, fmt.Errorf("jobs insert rows affected returns error: %v", err)
+		}
+
+		enqueued += int(n)
+	}
+
+	return enqueued, nil
+}
+
+// Claim the next due job of the kinds and mark it as running, it returns sql.ErrNoRows when no job is due.
+// Running jobs locked before staleBefore are claimed again, their worker is assumed to be gone.
+func (jr *JobRepo) Claim(ctx context.Context, kinds []string, staleBefore time.Time) (Job, error) {
+	var job Job
+
+	tx, err := jr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return job, fmt.Errorf("failed to begin claim job transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	query := fmt.Sprintf(
+		"SELECT id, kind, payload, status, attempts, max_attempts, last_error, run_at, locked_at, created_at, updated_at FROM jobs "+
+			"WHERE kind IN (%s) AND ((status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)) "+
+			"ORDER BY run_at ASC, id ASC LIMIT 1 FOR UPDATE SKIP LOCKED",
+		strings.TrimSuffix(strings.Repeat("?,", len(kinds)), ","),
+	)
+
+	args := make([]any, 0, len(kinds)+4)
+	for _, kind := range kinds {
+		args = append(args, kind)
+	}
+
+	args = append(args, JobPending, now.Format(time.DateTime), JobRunning, staleBefore.Format(time.DateTime))
+
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(
+		&job.Id,
+		&job.Kind,
+		&job.Payload,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.LastError,
+		&job.RunAt,
+		&job.LockedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	); err != nil {
+		return job, err
+	}
+
+	job.Status = JobRunning
+	job.Attempts++
+	job.LockedAt = dbutils.NewNullTime(now)
+
+	query = "UPDATE jobs SET status = ?, attempts = ?, locked_at = ?, updated_at = ? WHERE id = ?"
+
+	if _, err := tx.ExecContext(ctx, query, job.Status, job.Attempts, now.Format(time.DateTime), now.Format(time.DateTime), job.Id); err != nil {
+		return job, fmt.Errorf("failed to run claim job update query, id: %d, error: %v", job.Id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return job, fmt.Errorf("failed to commit claim job transaction: %v", err)
+	}
+
+	return job, nil
+}
+
+// Delete a done job, it returns ErrJobLockLost if the job has been claimed again since.
+func (jr *JobRepo) Complete(ctx context.Context, job Job) error {
+	query := "DELETE FROM jobs WHERE id = ? AND status = ? AND locked_at = ?"
+
+	result, err := jr.db.ExecContext(ctx, query, job.Id, JobRunning, job.LockedAt.Time.Format(time.DateTime))
+	if err != nil {
+		return fmt.Errorf("failed to run delete jobs query, id: %d, error: %v", job.Id, err)
+	}
+
+	return checkJobLock(result, job.Id)
+}
+
+// Put a failed job back to pending until runAt.
+func (jr *JobRepo) Retry(ctx context.Context, job Job, runAt time.Time, lastError string) error {
+	return jr.update(ctx, job, JobPending, job.Attempts, runAt, dbutils.NewNullString(lastError))
+}
+
+// Put a job back to pending until runAt without counting the attempt, e.g. when it is rate limited or interrupted.
+func (jr *JobRepo) Defer(ctx context.Context, job Job, runAt time.Time) error {
+	return jr.update(ctx, job, JobPending, max(job.Attempts-1, 0), runAt, job.LastError)
+}
+
+// Move a job to the dead letter, it is not run again until it is requeued.
+func (jr *JobRepo) Bury(ctx context.Context, job Job, lastError string) error {
+	return jr.update(ctx, job, JobDead, job.Attempts, job.RunAt, dbutils.NewNullString(lastError))
+}
+
+// Requeue a dead job with its attempts reset, it returns sql.ErrNoRows if there is no dead job with the id
+// and ErrJobAlreadyActive if the same work is already pending or running.
+func (jr *JobRepo) Requeue(ctx context.Context, id int64) error {
+	tx, err := jr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin requeue job transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	var kind, payload string
+
+	query := "SELECT kind, payload FROM jobs WHERE id = ? AND status = ? FOR UPDATE"
+
+	if err := tx.QueryRowContext(ctx, query, id, JobDead).Scan(&kind, &payload); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		return fmt.Errorf("failed to query dead job, id: %d, error: %v", id, err)
+	}
+
+	var active int
+
+	query = "SELECT COUNT(*) FROM jobs WHERE kind = ? AND payload = ? AND status IN (?, ?) FOR UPDATE"
+
+	if err := tx.QueryRowContext(ctx, query, kind, payload, JobPending, JobRunning).Scan(&active); err != nil {
+		return fmt.Errorf("failed to count active jobs, kind: %s, payload: %s, error: %v", kind, payload, err)
+	}
+
+	if active > 0 {
+		return ErrJobAlreadyActive
+	}
+
+	query = "UPDATE jobs SET status = ?, attempts = 0, run_at = ?, locked_at = NULL, updated_at = ? WHERE id = ?"
+
+	now := time.Now().Format(time.DateTime)
+
+	if _, err := tx.ExecContext(ctx, query, JobPending, now, now, id); err != nil {
+		// The same work was enqueued concurrently.
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return ErrJobAlreadyActive
+		}
+
+		return fmt.Errorf("failed to run requeue job query, id: %d, error: %v", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit requeue job transaction: %v", err)
+	}
+
+	return nil
+}
+
+// Record the outcome of a claimed job, it returns ErrJobLockLost if the job has been claimed again since.
+func (jr *JobRepo) update(ctx context.Context, job Job, status string, attempts int, runAt time.Time, lastError dbutils.NullString) error {
+	query := "UPDATE jobs SET status = ?, attempts = ?, run_at = ?, last_error = ?, locked_at = NULL, updated_at = ? WHERE id = ? AND status = ? AND locked_at = ?"
+
+	result, err := jr.db.ExecContext(ctx, query,
+		status,
+		attempts,
+		runAt.Format(time.DateTime),
+		lastError,
+		time.Now().Format(time.DateTime),
+		job.Id,
+		JobRunning,
+		job.LockedAt.Time.Format(time.DateTime),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to run update jobs query, id: %d, error: %v", job.Id, err)
+	}
+
+	return checkJobLock(result, job.Id)
+}
+
+// A claimed job is written only while it is still running under its lock, no row is affected otherwise.
+func checkJobLock(result sql.Result, id int64) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("jobs update rows affected returns error, id: %d, error: %v", id, err)
+	}
+
+	if n == 0 {
+		return ErrJobLockLost
+	}
+
+	return nil
+}
+
+// Count the pending and running jobs of the kinds, due or not.
+func (jr *JobRepo) CountActive(ctx context.Context, kinds []string) (int, error) {
+	return jr.countActive(ctx, kinds, time.Time{})
+}
+
+// Count the running jobs of the kinds and the pending ones due by dueBy, jobs delayed to a later time are left out.
+func (jr *JobRepo) CountDue(ctx context.Context, kinds []string, dueBy time.Time) (int, error) {
+	return jr.countActive(ctx, kinds, dueBy)
+}
+
+// A zero dueBy counts all the pending jobs.
+func (jr *JobRepo) countActive(ctx context.Context, kinds []string, dueBy time.Time) (int, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM jobs WHERE kind IN (%s)", strings.TrimSuffix(strings.Repeat("?,", len(kinds)), ","))
+
+	args := make([]any, 0, len(kinds)+3)
+	for _, kind := range kinds {
+		args = append(args, kind)
+	}
+
+	if dueBy.IsZero() {
+		query += " AND status IN (?, ?)"
+		args = append(args, JobPending, JobRunning)
+	} else {
+		query += " AND (status = ? OR (status = ? AND run_at <= ?))"
+		args = append(args, JobRunning, JobPending, dueBy.Format(time.DateTime))
+	}
+
+	var count int
+
+	if err := jr.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count active jobs: %v", err)
+	}
+
+	return count, nil
+}
+
+// Find dead jobs newest first, an empty kind returns dead jobs of all kinds.
+func (jr *JobRepo) FindDead(ctx context.Context, kind string, limit int) ([]Job, error) {
+	qb := dbutils.NewQueryBuilder()
+	qb.Query("SELECT id, kind, payload, status, attempts, max_attempts, last_error, run_at, locked_at, created_at, updated_at FROM jobs")
+	qb.Where("status = ?", JobDead)
+
+	if kind != "" {
+		qb.Where("kind = ?", kind)
+	}
+
+	qb.OrderBy("updated_at", "DESC")
+	qb.Limit(limit)
+
+	q, args := qb.GetQuery()
+
+	rows, err := jr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead jobs: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "jobRepo.FindDead"))
+		}
+	}()
+
+	jobs := make([]Job, 0)
+
+	for rows.Next() {
+		var job Job
+
+		if err := rows.Scan(
+			&job.Id,
+			&job.Kind,
+			&job.Payload,
+			&job.Status,
+			&job.Attempts,
+			&job.MaxAttempts,
+			&job.LastError,
+			&job.RunAt,
+			&job.LockedAt,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan jobs table, error: %v", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("jobRepo.FindDead, rows error: %v", err)
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,267 @@
+package model
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+type LanguageStatsParams struct {
+	Range    int    // number of days.
+	Interval string // day, week or month, stars and forks are only available by month.
+	Language string // only return this language when set.
+	Limit    int    // number of languages, the most trending ones in the range first.
+}
+
+func NewLanguageStatsParams(rangeStr, intervalStr, languageStr, limitStr string) (*LanguageStatsParams, error) {
+	params := &LanguageStatsParams{
+		Language: strings.TrimSpace(languageStr),
+	}
+
+	dateRange, err := strconv.Atoi(rangeStr)
+	if err != nil || dateRange <= 0 {
+		return nil, errors.New("invalid range")
+	}
+
+	params.Range = min(dateRange, 3650)
+
+	if _, ok := statsIntervals[intervalStr]; !ok {
+		return nil, fmt.Errorf("invalid interval, expected: day, week or month, passed %s", intervalStr)
+	}
+
+	params.Interval = intervalStr
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return nil, errors.New("invalid limit")
+	}
+
+	params.Limit = min(limit, 100)
+
+	return params, nil
+}
+
+type LanguageStat struct {
+	Language             string            `json:"language"`
+	Period               time.Time         `json:"period"`
+	TrendingRepositories int               `json:"trending_repositories"` // distinct repositories which trended in the period.
+	NewRepositories      int               `json:"new_repositories"`      // repositories which trended for the first time in the period.
+	Stars                dbutils.NullInt64 `json:"stars"`                 // stars gained by the repositories of the language, monthly interval only.
+	Forks                dbutils.NullInt64 `json:"forks"`                 // forks gained by the repositories of the language, monthly interval only.
+}
+
+type languageBucket struct {
+	Language string
+	Period   time.Time
+}
+
+// Merge the per language and period counts into stats, limited to the languages with the most trending repositories.
+// Stats are ordered by period then by language.
+func mergeLanguageStats(trending, newRepositories map[languageBucket]int, stars, forks map[languageBucket]int64, withEngagements bool, limit int) []LanguageStat {
+	totals := make(map[string]int)
+	buckets := make(map[languageBucket]bool)
+
+	for bucket, count := range trending {
+		totals[bucket.Language] += count
+		buckets[bucket] = true
+	}
+
+	for bucket := range newRepositories {
+		buckets[bucket] = true
+	}
+
+	for bucket := range stars {
+		buckets[bucket] = true
+	}
+
+	for bucket := range forks {
+		buckets[bucket] = true
+	}
+
+	languages := make([]string, 0)
+	for bucket := range buckets {
+		if !slices.Contains(languages, bucket.Language) {
+			languages = append(languages, bucket.Language)
+		}
+	}
+
+	slices.SortFunc(languages, func(a, b string) int {
+		return cmp.Or(cmp.Compare(totals[b], totals[a]), strings.Compare(a, b))
+	})
+
+	if limit > 0 && len(languages) > limit {
+		languages = languages[:limit]
+	}
+
+	stats := make([]LanguageStat, 0)
+
+	for bucket := range buckets {
+		if !slices.Contains(languages, bucket.Language) {
+			continue
+		}
+
+		stat := LanguageStat{
+			Language:             bucket.Language,
+			Period:               bucket.Period,
+			TrendingRepositories: trending[bucket],
+			NewRepositories:      newRepositories[bucket],
+		}
+
+		if withEngagements {
+			stat.Stars = dbutils.NewNullInt64(int(stars[bucket]))
+			stat.Forks = dbutils.NewNullInt64(int(forks[bucket]))
+		}
+
+		stats = append(stats, stat)
+	}
+
+	slices.SortFunc(stats, func(a, b LanguageStat) int {
+		return cmp.Or(a.Period.Compare(b.Period), strings.Compare(a.Language, b.Language))
+	})
+
+	return stats
+}
+
+func (sr *StatsRepo) findLanguageCounts(ctx context.Context, qb *dbutils.QueryBuilder, action string) (map[languageBucket]int, error) {
+	q, args := qb.GetQuery()
+
+	rows, err := sr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %v", action, err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "statsRepo."+action))
+		}
+	}()
+
+	counts := make(map[languageBucket]int)
+
+	for rows.Next() {
+		var bucket languageBucket
+		var count int
+
+		if err := rows.Scan(&bucket.Language, &bucket.Period, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan %s, error: %v", action, err)
+		}
+
+		counts[bucket] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("statsRepo.%s, rows error: %v", action, err)
+	}
+
+	return counts, nil
+}
+
+func (sr *StatsRepo) findLanguageEngagements(ctx context.Context, params *LanguageStatsParams, since time.Time) (map[languageBucket]int64, map[languageBucket]int64, error) {
+	period := "DATE_ADD(MAKEDATE(ri.year, 1), INTERVAL ri.month - 1 MONTH)"
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(fmt.Sprintf("select repositories.language, %s as period, coalesce(sum(ri.stars), 0), coalesce(sum(ri.forks), 0) from repository_monthly_insights as ri join repositories on ri.repository_id = repositories.id", period))
+	qb.Where("repositories.language is not null", nil)
+	qb.Where("ri.year * 100 + ri.month >= ?", since.Year()*100+int(since.Month()))
+
+	if params.Language != "" {
+		qb.Where("repositories.language = ?", params.Language)
+	}
+
+	qb.GroupBy("repositories.language, " + period)
+
+	q, args := qb.GetQuery()
+
+	rows, err := sr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query language engagements: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "statsRepo.findLanguageEngagements"))
+		}
+	}()
+
+	stars, forks := make(map[languageBucket]int64), make(map[languageBucket]int64)
+
+	for rows.Next() {
+		var bucket languageBucket
+		var starCount, forkCount int64
+
+		if err := rows.Scan(&bucket.Language, &bucket.Period, &starCount, &forkCount); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan language engagements, error: %v", err)
+		}
+
+		stars[bucket], forks[bucket] = starCount, forkCount
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("statsRepo.findLanguageEngagements, rows error: %v", err)
+	}
+
+	return stars, forks, nil
+}
+
+// Returns per repository language and period the number of trending repositories, of repositories trending for the
+// first time and, by month only, the stars and forks gained according to the monthly insights.
+func (sr *StatsRepo) FindLanguageStats(ctx context.Context, params *LanguageStatsParams) ([]LanguageStat, error) {
+	since := time.Now().AddDate(0, 0, -params.Range)
+
+	bucket := statsBucket(params.Interval, "trending_repositories.trend_date")
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(fmt.Sprintf("select repositories.language, %s as period, count(distinct repositories.id) from trending_repositories join repositories on trending_repositories.repository_id = repositories.id", bucket))
+	qb.Where("repositories.language is not null", nil)
+	qb.Where("trending_repositories.trend_date > ?", since.Format("2006-01-02"))
+
+	if params.Language != "" {
+		qb.Where("repositories.language = ?", params.Language)
+	}
+
+	qb.GroupBy("repositories.language, " + bucket)
+
+	trending, err := sr.findLanguageCounts(ctx, qb, "findLanguageTrendingRepositories")
+	if err != nil {
+		return nil, err
+	}
+
+	// A repository enters trending on the first date it has ever trended, not only within the range.
+	firstBucket := statsBucket(params.Interval, "first_trendings.first_date")
+
+	qb = dbutils.NewQueryBuilder()
+	qb.Query(fmt.Sprintf("select repositories.language, %s as period, count(*) from (select repository_id, min(trend_date) as first_date from trending_repositories where repository_id is not null group by repository_id) as first_trendings join repositories on first_trendings.repository_id = repositories.id", firstBucket))
+	qb.Where("repositories.language is not null", nil)
+	qb.Where("first_trendings.first_date > ?", since.Format("2006-01-02"))
+
+	if params.Language != "" {
+		qb.Where("repositories.language = ?", params.Language)
+	}
+
+	qb.GroupBy("repositories.language, " + firstBucket)
+
+	newRepositories, err := sr.findLanguageCounts(ctx, qb, "findLanguageNewRepositories")
+	if err != nil {
+		return nil, err
+	}
+
+	withEngagements := params.Interval == StatsIntervalMonth
+	var stars, forks map[languageBucket]int64
+
+	if withEngagements {
+		stars, forks, err = sr.findLanguageEngagements(ctx, params, since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mergeLanguageStats(trending, newRepositories, stars, forks, withEngagements, params.Limit), nil
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLanguageStatsParams(t *testing.T) {
+	params, err := NewLanguageStatsParams("180", "month", " Go ", "500")
+	assert.NoError(t, err)
+	assert.Equal(t, &LanguageStatsParams{Range: 180, Interval: StatsIntervalMonth, Language: "Go", Limit: 100}, params)
+
+	invalid := [][]string{
+		{"0", "month", "", "10"},
+		{"abc", "month", "", "10"},
+		{"180", "year", "", "10"},
+		{"180", "", "", "10"},
+		{"180", "month", "", "0"},
+	}
+
+	for _, args := range invalid {
+		_, err := NewLanguageStatsParams(args[0], args[1], args[2], args[3])
+		assert.Error(t, err, args)
+	}
+}
+
+func TestMergeLanguageStats(t *testing.T) {
+	june := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	july := june.AddDate(0, 1, 0)
+
+	trending := map[languageBucket]int{
+		{"Go", june}:     10,
+		{"Go", july}:     12,
+		{"Rust", june}:   8,
+		{"Python", july}: 30,
+	}
+
+	newRepositories := map[languageBucket]int{
+		{"Go", june}:     4,
+		{"Python", july}: 20,
+	}
+
+	stars := map[languageBucket]int64{
+		{"Go", june}:   1000,
+		{"Rust", july}: 300,
+	}
+
+	forks := map[languageBucket]int64{
+		{"Go", june}: 50,
+	}
+
+	stats := mergeLanguageStats(trending, newRepositories, stars, forks, true, 2)
+
+	assert.Equal(t, []LanguageStat{
+		{Language: "Go", Period: june, TrendingRepositories: 10, NewRepositories: 4, Stars: dbutils.NewNullInt64(1000), Forks: dbutils.NewNullInt64(50)},
+		{Language: "Go", Period: july, TrendingRepositories: 12, Stars: dbutils.NewNullInt64(0), Forks: dbutils.NewNullInt64(0)},
+		{Language: "Python", Period: july, TrendingRepositories: 30, NewRepositories: 20, Stars: dbutils.NewNullInt64(0), Forks: dbutils.NewNullInt64(0)},
+	}, stats)
+
+	stats = mergeLanguageStats(trending, newRepositories, nil, nil, false, 0)
+
+	assert.Len(t, stats, 4)
+	assert.Equal(t, "Rust", stats[1].Language)
+	assert.False(t, stats[1].Stars.Valid)
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	// GitHub logins are alphanumeric with single hyphens, up to 39 characters.
+	githubLoginPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9]|-[A-Za-z0-9]){0,38}$`)
+	// GitHub repository names are letters, digits, dots, hyphens and underscores, up to 100 characters.
+	repositoryNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+)
+
+var (
+	ErrInvalidLogin          = errors.New("invalid GitHub login")
+	ErrInvalidRepositoryName = errors.New("invalid GitHub repository name")
+)
+
+func ParseGithubLogin(login string) (string, error) {
+	login = strings.TrimSpace(login)
+
+	if len(login) > 39 || !githubLoginPattern.MatchString(login) {
+		return "", ErrInvalidLogin
+	}
+
+	return login, nil
+}
+
+// Returns the full name of a repository, e.g. liweiyi88/trendshift-backend.
+func ParseRepositoryFullName(owner, repo string) (string, error) {
+	owner, err := ParseGithubLogin(owner)
+	if err != nil {
+		return "", err
+	}
+
+	repo = strings.TrimSpace(repo)
+
+	if repo == "." || repo == ".." || !repositoryNamePattern.MatchString(repo) {
+		return "", ErrInvalidRepositoryName
+	}
+
+	return owner + "/" + repo, nil
+}
+
+// Parse a repository name formatted as owner/name and returns its full name.
+func ParseRepositoryName(name string) (string, error) {
+	owner, repo, ok := strings.Cut(strings.TrimSpace(name), "/")
+	if !ok {
+		return "", ErrInvalidRepositoryName
+	}
+
+	return ParseRepositoryFullName(owner, repo)
+}
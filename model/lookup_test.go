@@ -0,0 +1,50 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGithubLogin(t *testing.T) {
+	for _, login := range []string{"liweiyi88", "a", "some-user", strings.Repeat("a", 39)} {
+		parsed, err := ParseGithubLogin(login)
+		assert.NoError(t, err)
+		assert.Equal(t, login, parsed)
+	}
+
+	for _, login := range []string{"", "-user", "user-", "some--user", "some_user", "some.user", strings.Repeat("a", 40)} {
+		_, err := ParseGithubLogin(login)
+		assert.ErrorIs(t, err, ErrInvalidLogin)
+	}
+}
+
+func TestParseRepositoryFullName(t *testing.T) {
+	fullName, err := ParseRepositoryFullName("liweiyi88", "trendshift-backend")
+	assert.NoError(t, err)
+	assert.Equal(t, "liweiyi88/trendshift-backend", fullName)
+
+	fullName, err = ParseRepositoryFullName("vercel", "next.js")
+	assert.NoError(t, err)
+	assert.Equal(t, "vercel/next.js", fullName)
+
+	_, err = ParseRepositoryFullName("-owner", "repo")
+	assert.ErrorIs(t, err, ErrInvalidLogin)
+
+	for _, repo := range []string{"", ".", "..", "repo/name", "repo name", strings.Repeat("a", 101)} {
+		_, err := ParseRepositoryFullName("owner", repo)
+		assert.ErrorIs(t, err, ErrInvalidRepositoryName)
+	}
+}
+
+func TestParseRepositoryName(t *testing.T) {
+	fullName, err := ParseRepositoryName(" liweiyi88/trendshift-backend ")
+	assert.NoError(t, err)
+	assert.Equal(t, "liweiyi88/trendshift-backend", fullName)
+
+	for _, name := range []string{"", "trendshift-backend", "liweiyi88/trendshift/backend", "/trendshift-backend"} {
+		_, err := ParseRepositoryName(name)
+		assert.Error(t, err)
+	}
+}
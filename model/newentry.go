@@ -0,0 +1,20 @@
+package model
+
+import "fmt"
+
+// Number of days of each period the new entries feeds can be filtered by, today included.
+var newEntryPeriods = map[string]int{
+	"day":   1,
+	"week":  7,
+	"month": 30,
+}
+
+// Returns the number of days of a new entries period, expected day, week or month.
+func ParseNewEntryPeriod(period string) (int, error) {
+	days, ok := newEntryPeriods[period]
+	if !ok {
+		return 0, fmt.Errorf("invalid period, expected: day, week or month, passed %s", period)
+	}
+
+	return days, nil
+}
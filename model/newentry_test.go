@@ -0,0 +1,20 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNewEntryPeriod(t *testing.T) {
+	tests := map[string]int{"day": 1, "week": 7, "month": 30}
+
+	for period, want := range tests {
+		days, err := ParseNewEntryPeriod(period)
+		assert.NoError(t, err)
+		assert.Equal(t, want, days)
+	}
+
+	_, err := ParseNewEntryPeriod("year")
+	assert.Error(t, err)
+}
@@ -6,6 +6,8 @@ type Options struct {
 	Limit     int
 	Start     string
 	End       string
+	Tags      []string
+	TagMode   string
 }
 
 func ExtractOptions(opts ...any) Options {
@@ -31,6 +33,14 @@ func ExtractOptions(opts ...any) Options {
 		if v, ok := option.(*EndOption); ok {
 			options.End = v.Get()
 		}
+
+		if v, ok := option.(*TagsOption); ok {
+			options.Tags = v.Get()
+		}
+
+		if v, ok := option.(*TagModeOption); ok {
+			options.TagMode = v.Get()
+		}
 	}
 
 	return options
@@ -47,6 +47,8 @@ func TestExtractOptionsWithArgs(t *testing.T) {
 		Start("2023-10-04 00:00:00"),
 		End("2023-10-04 23:59:59"),
 		Limit(24),
+		Tags("AI", " ", "Web "),
+		TagMode(" AND"),
 	)
 
 	expcts := []struct {
@@ -73,6 +75,14 @@ func TestExtractOptionsWithArgs(t *testing.T) {
 			actual: options.Limit,
 			want:   24,
 		},
+		{
+			actual: options.TagMode,
+			want:   "and",
+		},
+	}
+
+	if len(options.Tags) != 2 || options.Tags[0] != "AI" || options.Tags[1] != "Web" {
+		t.Errorf("unexpected tags: %v", options.Tags)
 	}
 
 	for _, test := range expcts {
@@ -0,0 +1,43 @@
+package opt
+
+import "strings"
+
+type TagsOption struct {
+	values []string
+}
+
+func Tags(values ...string) *TagsOption {
+	return &TagsOption{values}
+}
+
+func (t *TagsOption) Get() []string {
+	if t == nil {
+		return nil
+	}
+
+	tags := make([]string, 0, len(t.values))
+
+	for _, value := range t.values {
+		if value = strings.TrimSpace(value); value != "" {
+			tags = append(tags, value)
+		}
+	}
+
+	return tags
+}
+
+type TagModeOption struct {
+	value string
+}
+
+func TagMode(value string) *TagModeOption {
+	return &TagModeOption{value}
+}
+
+func (t *TagModeOption) Get() string {
+	if t == nil {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(t.value))
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/model/opt"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+type Organization struct {
+	Id              int                `json:"organization_id"` // primary key saved in DB.
+	GhId            int                `json:"id"`              // id from github organization api response.
+	Login           string             `json:"login"`
+	AvatarUrl       string             `json:"avatar_url"`
+	Name            dbutils.NullString `json:"name"`
+	Description     dbutils.NullString `json:"description"`
+	Blog            dbutils.NullString `json:"blog"`
+	Location        dbutils.NullString `json:"location"`
+	Email           dbutils.NullString `json:"email"`
+	TwitterUsername dbutils.NullString `json:"twitter_username"`
+	IsVerified      bool               `json:"is_verified"`
+	PublicRepos     int                `json:"public_repos"`
+	Followers       int                `json:"followers"`
+	CreatedAt       time.Time          `json:"created_at"` // It is the datetime the organization was created on GitHub.
+	UpdatedAt       time.Time          `json:"updated_at"` // It is the datetime we update the DB record, not when organization info updated on GitHub
+}
+
+type TrendingOrganizationResponse struct {
+	Organization
+	FeaturedCount        int `json:"featured_count"`        // non db column field
+	BestRanking          int `json:"best_ranking"`          // non db column field
+	TrendingRepositories int `json:"trending_repositories"` // non db column field, number of distinct repositories that trended.
+}
+
+type OrganizationRepo struct {
+	db database.DB
+}
+
+func NewOrganizationRepo(db database.DB) *OrganizationRepo {
+	return &OrganizationRepo{db}
+}
+
+// Insert or update an organization by its GitHub id and return the primary key.
+func (ogr *OrganizationRepo) Save(ctx context.Context, organization Organization) (int, error) {
+	query := "INSERT INTO `organizations` (`gh_id`, `login`, `avatar_url`, `name`, `description`, `blog`, `location`, `email`, `twitter_username`, `is_verified`, `public_repos`, `followers`, `created_at`, `updated_at`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `id` = LAST_INSERT_ID(`id`), `login` = VALUES(`login`), `avatar_url` = VALUES(`avatar_url`), `name` = VALUES(`name`), `description` = VALUES(`description`), `blog` = VALUES(`blog`), `location` = VALUES(`location`), " +
+		"`email` = VALUES(`email`), `twitter_username` = VALUES(`twitter_username`), `is_verified` = VALUES(`is_verified`), `public_repos` = VALUES(`public_repos`), `followers` = VALUES(`followers`), `updated_at` = VALUES(`updated_at`)"
+
+	result, err := ogr.db.ExecContext(ctx, query,
+		organization.GhId,
+		organization.Login,
+		organization.AvatarUrl,
+		organization.Name,
+		organization.Description,
+		organization.Blog,
+		organization.Location,
+		organization.Email,
+		organization.TwitterUsername,
+		organization.IsVerified,
+		organization.PublicRepos,
+		organization.Followers,
+		organization.CreatedAt.Format(time.DateTime),
+		time.Now().Format(time.DateTime),
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to exec upsert organizations query to db, organization: %s, error: %v", organization.Login, err)
+	}
+
+	lastInsertId, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get organizations last insert id after upsert, error: %v", err)
+	}
+
+	return int(lastInsertId), nil
+}
+
+// Aggregate trending repositories appearances by the organization that owns them.
+func (ogr *OrganizationRepo) FindTrendingOrganizations(ctx context.Context, opts ...any) ([]TrendingOrganizationResponse, error) {
+	query := "select organizations.*, count(*) as count, min(trending_repositories.`rank`) as best_ranking, count(distinct repositories.id) as trending_repositories from organizations join repositories on organizations.id = repositories.organization_id join trending_repositories on repositories.id = trending_repositories.repository_id"
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(query)
+
+	qb.OrderBy("count", "DESC")
+	qb.OrderBy("best_ranking", "ASC")
+	qb.OrderBy("organizations.id", "ASC")
+
+	options := opt.ExtractOptions(opts...)
+	lang, dateRange, limit := options.Language, options.DateRange, options.Limit
+
+	if lang != "" {
+		qb.Where("`trending_repositories`.`language` = ?", lang)
+	} else {
+		qb.Where("`trending_repositories`.`language` is null", nil)
+	}
+
+	if dateRange > 0 {
+		since := time.Now().AddDate(0, 0, -dateRange)
+		qb.Where("`trending_repositories`.`trend_date` > ?", since.Format("2006-01-02"))
+	}
+
+	if limit > 0 {
+		qb.Limit(limit)
+	}
+
+	qb.GroupBy("organizations.id")
+
+	q, args := qb.GetQuery()
+
+	rows, err := ogr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trending organizations: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "organizationRepo.FindTrendingOrganizations"))
+		}
+	}()
+
+	organizations := make([]TrendingOrganizationResponse, 0)
+
+	for rows.Next() {
+		var org TrendingOrganizationResponse
+
+		if err := rows.Scan(
+			&org.Id,
+			&org.GhId,
+			&org.Login,
+			&org.AvatarUrl,
+			&org.Name,
+			&org.Description,
+			&org.Blog,
+			&org.Location,
+			&org.Email,
+			&org.TwitterUsername,
+			&org.IsVerified,
+			&org.PublicRepos,
+			&org.Followers,
+			&org.CreatedAt,
+			&org.UpdatedAt,
+			&org.FeaturedCount,
+			&org.BestRanking,
+			&org.TrendingRepositories,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan trending organizations, error: %v", err)
+		}
+
+		organizations = append(organizations, org)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("organizationRepo.FindTrendingOrganizations, rows error: %v", err)
+	}
+
+	return organizations, nil
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/database"
+)
+
+// Entity types of the tables which reference either a repository or a developer.
+const (
+	EntityRepository = "repository"
+	EntityDeveloper  = "developer"
+)
+
+// PageViewRepo counts the daily views of the repository and developer pages.
+type PageViewRepo struct {
+	db database.DB
+}
+
+func NewPageViewRepo(db database.DB) *PageViewRepo {
+	return &PageViewRepo{
+		db: db,
+	}
+}
+
+// Count a view of the page of an entity today.
+func (pvr *PageViewRepo) Increment(ctx context.Context, entityType string, entityId int) error {
+	query := "INSERT INTO `page_views` (`entity_type`, `entity_id`, `view_date`, `views`) VALUES (?, ?, ?, 1) ON DUPLICATE KEY UPDATE `views` = `views` + 1"
+
+	if _, err := pvr.db.ExecContext(ctx, query, entityType, entityId, time.Now().Format(time.DateOnly)); err != nil {
+		return fmt.Errorf("failed to run upsert page_views query, %s id: %d, error: %v", entityType, entityId, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// Format of datetime sort values in cursors, it keeps the milliseconds of DATETIME(3) columns.
+const cursorTimeFormat = "2006-01-02 15:04:05.000"
+
+// Value used in cursors and sort expressions instead of null datetimes, nulls sort as the oldest values.
+const cursorNullTime = "1000-01-01 00:00:00.000"
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points to the last item of a page, the next page starts right after it.
+// Clients must treat it as an opaque string.
+type Cursor struct {
+	Sort  string `json:"s"` // a cursor is only valid for the sort and order it was issued for.
+	Order string `json:"o"`
+	Value string `json:"v"` // sort value of the last item.
+	Id    int    `json:"id"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(cursorStr string) (Cursor, error) {
+	var cursor Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(cursorStr)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id <= 0 {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+type Page[T any] struct {
+	Data       []T                `json:"data"`
+	NextCursor dbutils.NullString `json:"next_cursor"` // null on the last page.
+}
+
+// A column lists can be sorted by.
+type sortColumn struct {
+	expression string // SQL expression, nullable columns are coalesced so the keyset comparison works.
+	numeric    bool
+}
+
+// Pagination parameters shared by the lists.
+type PageParams struct {
+	Sort   string
+	Order  string
+	Limit  int
+	Cursor *Cursor
+}
+
+func newPageParams(sorts map[string]sortColumn, sortStr, orderStr, limitStr, cursorStr string) (PageParams, error) {
+	params := PageParams{
+		Sort:  sortStr,
+		Order: strings.ToLower(orderStr),
+		Limit: defaultPageLimit,
+	}
+
+	if _, ok := sorts[params.Sort]; !ok {
+		return params, fmt.Errorf("invalid sort, passed %s", sortStr)
+	}
+
+	if params.Order == "" {
+		params.Order = SortOrderDesc
+	}
+
+	if params.Order != SortOrderAsc && params.Order != SortOrderDesc {
+		return params, fmt.Errorf("invalid order, expected: asc or desc, passed %s", orderStr)
+	}
+
+	if limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return params, errors.New("invalid limit")
+		}
+
+		params.Limit = min(limit, maxPageLimit)
+	}
+
+	if cursorStr != "" {
+		cursor, err := DecodeCursor(cursorStr)
+		if err != nil {
+			return params, err
+		}
+
+		if cursor.Sort != params.Sort || cursor.Order != params.Order {
+			return params, ErrInvalidCursor
+		}
+
+		if sorts[params.Sort].numeric {
+			if _, err := strconv.Atoi(cursor.Value); err != nil {
+				return params, ErrInvalidCursor
+			}
+		}
+
+		params.Cursor = &cursor
+	}
+
+	return params, nil
+}
+
+// Apply the order and the keyset condition of the page, the id breaks ties so the order is stable.
+// One more row than the limit is fetched to know whether there is a next page.
+func applyPage(qb *dbutils.QueryBuilder, sorts map[string]sortColumn, idColumn string, params PageParams) {
+	column := sorts[params.Sort]
+
+	if params.Cursor != nil {
+		operator := "<"
+		if params.Order == SortOrderAsc {
+			operator = ">"
+		}
+
+		var value any = params.Cursor.Value
+		if column.numeric {
+			value, _ = strconv.Atoi(params.Cursor.Value)
+		}
+
+		qb.WhereArgs(fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", column.expression, operator, column.expression, idColumn, operator), value, value, params.Cursor.Id)
+	}
+
+	order := strings.ToUpper(params.Order)
+	qb.OrderBy(column.expression, order)
+	qb.OrderBy(idColumn, order)
+	qb.Limit(params.Limit + 1)
+}
+
+// Trim the extra row fetched by applyPage and issue the cursor of the next page.
+func newPage[T any](items []T, params PageParams, sortValue func(T) string, id func(T) int) Page[T] {
+	page := Page[T]{Data: items}
+
+	if len(items) > params.Limit {
+		page.Data = items[:params.Limit]
+		last := page.Data[len(page.Data)-1]
+
+		cursor := Cursor{Sort: params.Sort, Order: params.Order, Value: sortValue(last), Id: id(last)}
+		page.NextCursor = dbutils.NewNullString(cursor.Encode())
+	}
+
+	return page
+}
+
+func cursorTime(t time.Time) string {
+	return t.Format(cursorTimeFormat)
+}
+
+func cursorNullableTime(t dbutils.NullTime) string {
+	if !t.Valid {
+		return cursorNullTime
+	}
+
+	return cursorTime(t.Time)
+}
+
+// Parse an optional boolean filter, nil means no filter.
+func parseBoolFilter(name, value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+
+	return &b, nil
+}
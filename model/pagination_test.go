@@ -0,0 +1,118 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{Sort: "stars", Order: SortOrderDesc, Value: "100", Id: 42}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	for _, invalid := range []string{"not base64!", "e30", "bm90IGpzb24"} {
+		_, err := DecodeCursor(invalid)
+		assert.ErrorIs(t, err, ErrInvalidCursor, invalid)
+	}
+}
+
+func TestNewRepositoryListParams(t *testing.T) {
+	params, err := NewRepositoryListParams("stars", "", "", "", " Go ", "MIT", "10", "100", "false", "true")
+	assert.NoError(t, err)
+	assert.Equal(t, PageParams{Sort: "stars", Order: SortOrderDesc, Limit: defaultPageLimit}, params.PageParams)
+	assert.Equal(t, "Go", params.Language)
+	assert.Equal(t, "mit", params.License)
+	assert.Equal(t, 10, params.MinStars)
+	assert.Equal(t, 100, params.MaxStars)
+	assert.False(t, *params.Skipped)
+	assert.True(t, *params.Archived)
+
+	cursor := Cursor{Sort: "created_at", Order: SortOrderAsc, Value: "2025-01-01 00:00:00.000", Id: 3}
+	params, err = NewRepositoryListParams("created_at", "ASC", "500", cursor.Encode(), "", "", "", "", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, maxPageLimit, params.Limit)
+	assert.Equal(t, &cursor, params.Cursor)
+	assert.Nil(t, params.Skipped)
+
+	invalid := [][]string{
+		{"name", "", "", ""},
+		{"stars", "up", "", ""},
+		{"stars", "", "0", ""},
+		{"stars", "", "", "abc"},
+		{"stars", "asc", "", cursor.Encode()}, // cursor issued for another sort.
+		{"stars", "desc", "", Cursor{Sort: "stars", Order: SortOrderDesc, Value: "many", Id: 1}.Encode()},
+	}
+
+	for _, args := range invalid {
+		_, err := NewRepositoryListParams(args[0], args[1], args[2], args[3], "", "", "", "", "", "")
+		assert.Error(t, err, args)
+	}
+
+	_, err = NewRepositoryListParams("stars", "", "", "", "", "", "100", "10", "", "")
+	assert.Error(t, err)
+
+	_, err = NewRepositoryListParams("stars", "", "", "", "", "", "", "", "maybe", "")
+	assert.Error(t, err)
+}
+
+func TestApplyPage(t *testing.T) {
+	params, err := newPageParams(repositorySorts, "stars", "desc", "2", Cursor{Sort: "stars", Order: SortOrderDesc, Value: "100", Id: 7}.Encode())
+	assert.NoError(t, err)
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query("select * from repositories")
+	applyPage(qb, repositorySorts, "repositories.id", params)
+
+	query, args := qb.GetQuery()
+	assert.Equal(t, "select * from repositories WHERE (repositories.stars < ? OR (repositories.stars = ? AND repositories.id < ?)) ORDER BY repositories.stars DESC, repositories.id DESC LIMIT ?", query)
+	assert.Equal(t, []any{100, 100, 7, 3}, args)
+
+	params, err = newPageParams(repositorySorts, "last_commit_at", "asc", "", "")
+	assert.NoError(t, err)
+
+	qb.Query("select * from repositories")
+	applyPage(qb, repositorySorts, "repositories.id", params)
+
+	query, args = qb.GetQuery()
+	assert.Equal(t, "select * from repositories ORDER BY COALESCE(repositories.last_commit_at, '1000-01-01 00:00:00.000') ASC, repositories.id ASC LIMIT ?", query)
+	assert.Equal(t, []any{defaultPageLimit + 1}, args)
+}
+
+func TestNewPage(t *testing.T) {
+	created := time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)
+	repositories := []GhRepository{{Id: 1, CreatedAt: created}, {Id: 2, CreatedAt: created}, {Id: 3, CreatedAt: created}}
+
+	id := func(r GhRepository) int { return r.Id }
+	sortValue := func(r GhRepository) string { return repositorySortValue("created_at", r) }
+
+	params := PageParams{Sort: "created_at", Order: SortOrderDesc, Limit: 2}
+	page := newPage(repositories, params, sortValue, id)
+
+	assert.Len(t, page.Data, 2)
+	assert.True(t, page.NextCursor.Valid)
+
+	cursor, err := DecodeCursor(page.NextCursor.String)
+	assert.NoError(t, err)
+	assert.Equal(t, Cursor{Sort: "created_at", Order: SortOrderDesc, Value: "2025-06-01 10:30:00.000", Id: 2}, cursor)
+
+	params.Limit = 3
+	page = newPage(repositories, params, sortValue, id)
+	assert.Len(t, page.Data, 3)
+	assert.False(t, page.NextCursor.Valid)
+}
+
+func TestRepositorySortValue(t *testing.T) {
+	repository := GhRepository{Stars: 10, Forks: 2}
+
+	assert.Equal(t, "10", repositorySortValue("stars", repository))
+	assert.Equal(t, "2", repositorySortValue("forks", repository))
+	assert.Equal(t, cursorNullTime, repositorySortValue("last_commit_at", repository))
+
+	repository.LastCommitAt = dbutils.NewNullTime(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2025-06-01 00:00:00.000", repositorySortValue("last_commit_at", repository))
+}
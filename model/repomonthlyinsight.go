@@ -11,6 +11,7 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/model/opt"
 	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 )
 
@@ -61,6 +62,8 @@ type ListEngagementParams struct {
 	Language     string
 	Limit        int
 	CreatedAfter time.Time
+	Tags         []string
+	TagMode      string
 }
 
 func NewListEngagementParams(metricStr, yearStr, monthStr, languageStr, limitStr, createdAfterStr string) (*ListEngagementParams, error) {
@@ -105,6 +108,19 @@ func NewListEngagementParams(metricStr, yearStr, monthStr, languageStr, limitStr
 	return params, nil
 }
 
+// Filter engagements by tag names, mode is either and or or.
+func (params *ListEngagementParams) SetTags(tags []string, mode string) error {
+	tagMode, err := ParseTagMode(mode)
+	if err != nil {
+		return err
+	}
+
+	params.Tags = opt.Tags(tags...).Get()
+	params.TagMode = tagMode
+
+	return nil
+}
+
 func (params ListEngagementParams) ValidateMetric() error {
 	valid := []string{"stars", "forks", "merged_prs", "issues", "closed_issues"}
 	for _, v := range valid {
@@ -165,6 +181,57 @@ func (rr *RepositoryMonthlyInsightRepo) CreateMonthlyInsightsIfNotExist(ctx cont
 	return lastInsertId, nil
 }
 
+// Number of months, the current one included, backfilled for a newly tracked repository.
+const MonthlyInsightBackfillMonths = 12
+
+type yearMonth struct {
+	Year  int
+	Month int
+}
+
+// Returns the months to backfill, from the current month of now back to the oldest one.
+func backfillMonths(now time.Time, months int) []yearMonth {
+	result := make([]yearMonth, 0, months)
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	for i := range months {
+		month := start.AddDate(0, -i, 0)
+		result = append(result, yearMonth{Year: month.Year(), Month: int(month.Month())})
+	}
+
+	return result
+}
+
+// Create the empty monthly insights of a repository for the last months,
+// the monthly ingestion fetches them as they have never been ingested.
+func (rr *RepositoryMonthlyInsightRepo) CreateBackfill(ctx context.Context, repositoryId int, months int) error {
+	if months <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	createdAt := now.Format(time.DateTime)
+
+	qb := sq.Insert("repository_monthly_insights").
+		Options("IGNORE").
+		Columns("repository_id", "year", "month", "created_at", "updated_at")
+
+	for _, month := range backfillMonths(now, months) {
+		qb = qb.Values(repositoryId, month.Year, month.Month, createdAt, createdAt)
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build repository_monthly_insights backfill query: %v", err)
+	}
+
+	if _, err := rr.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to exec repository_monthly_insights backfill query, repository id: %d, error: %v", repositoryId, err)
+	}
+
+	return nil
+}
+
 func (rr *RepositoryMonthlyInsightRepo) Update(ctx context.Context, data RepositoryMonthlyInsightWithName) error {
 	query := "UPDATE `repository_monthly_insights` SET year = ?, month = ?, stars = ?, forks = ?, merged_prs = ?, issues = ?, closed_issues = ?, completed_at = ?, last_ingested_at = ?, updated_at = ? WHERE id = ?"
 
@@ -333,6 +400,15 @@ func (rr *RepositoryMonthlyInsightRepo) FindRepositoryMonthlyEngagements(ctx con
 		qb = qb.Where("repo.language = ?", params.Language)
 	}
 
+	if len(params.Tags) > 0 {
+		condition, args, err := findTagCondition(ctx, rr.db, "repo.id", params.Tags, params.TagMode)
+		if err != nil {
+			return nil, err
+		}
+
+		qb = qb.Where(condition, args...)
+	}
+
 	query, args, err := qb.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get SQL when find repo monthly engagements, error: %v", err)
@@ -384,6 +460,35 @@ func (rr *RepositoryMonthlyInsightRepo) FindRepositoryMonthlyEngagements(ctx con
 	return data, nil
 }
 
+// Find a monthly insight with the name of its repository, it returns sql.ErrNoRows if it does not exist.
+func (rr *RepositoryMonthlyInsightRepo) FindWithNameById(ctx context.Context, id int) (RepositoryMonthlyInsightWithName, error) {
+	query := "select ri.*, repositories.full_name from repository_monthly_insights as ri JOIN repositories ON ri.repository_id = repositories.id where ri.id = ?"
+
+	var repoInsight RepositoryMonthlyInsightWithName
+
+	row := rr.db.QueryRowContext(ctx, query, id)
+
+	if err := row.Scan(
+		&repoInsight.Id,
+		&repoInsight.Year,
+		&repoInsight.Month,
+		&repoInsight.Stars,
+		&repoInsight.Forks,
+		&repoInsight.MergedPrs,
+		&repoInsight.Issues,
+		&repoInsight.ClosedIssues,
+		&repoInsight.CompletedAt,
+		&repoInsight.LastIngestedAt,
+		&repoInsight.CreatedAt,
+		&repoInsight.UpdatedAt,
+		&repoInsight.RepositoryId,
+		&repoInsight.RepositoryName); err != nil {
+		return repoInsight, err
+	}
+
+	return repoInsight, nil
+}
+
 func (rr *RepositoryMonthlyInsightRepo) FindIncompletedLastIngestedBefore(ctx context.Context, before time.Time, limit int) ([]RepositoryMonthlyInsightWithName, error) {
 	query := "select ri.*, repositories.full_name from repository_monthly_insights as ri JOIN repositories ON ri.repository_id = repositories.id where ri.completed_at is null AND repositories.skipped = false AND (ri.last_ingested_at is null OR ri.last_ingested_at < ?) order by ri.month ASC, ri.last_ingested_at ASC limit ?"
 	args := []any{before.Format(time.DateTime), limit}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,3 +22,12 @@ func TestListEngagementParamsValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestBackfillMonths(t *testing.T) {
+	now := time.Date(2025, 2, 15, 10, 0, 0, 0, time.UTC)
+
+	months := backfillMonths(now, 3)
+	assert.Equal(t, []yearMonth{{2025, 2}, {2025, 1}, {2024, 12}}, months)
+
+	assert.Empty(t, backfillMonths(now, 0))
+}
@@ -2,22 +2,30 @@ package model
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/model/opt"
 	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 )
 
 const maxDescriptionLength = 900
 
+var ErrRepositoryNotFound = errors.New("repository not found")
+
 type Owner struct {
 	Name      string `json:"login"`
 	AvatarUrl string `json:"avatar_url"`
+	Type      string `json:"-"` // non db column field, "User" or "Organization" from the GitHub API.
 }
 
 type License struct {
@@ -31,6 +39,7 @@ type License struct {
 type RepositoryWithActivities struct {
 	GhRepository
 	MonthlyActivities []RepositoryMonthlyInsight `json:"monthly_activities"`
+	TopContributors   []RepositoryContributor    `json:"top_contributors"`
 }
 
 type Trending struct {
@@ -61,12 +70,38 @@ type GhRepository struct {
 	License              License            `json:"license"`
 	LicenseKey           string             `json:"license_key"`
 	LicenseName          string             `json:"license_name"`
+	OrganizationId       dbutils.NullInt64  `json:"organization_id"` // set when the repository is owned by an organization.
+	Archived             bool               `json:"archived"`
 	Tags                 []Tag              `json:"tags"`
+	Topics               []string           `json:"topics"` // non db column field, topics are saved in the repository_topics table.
 	Trendings            []Trending         `json:"trendings"`
 	CreatedAt            time.Time          `json:"created_at"` // It is the datetime the repository was created on GitHub.
 	UpdatedAt            time.Time          `json:"updated_at"` // It is the datetime we update the DB record, not when repository info updated on GitHub
 }
 
+// The columns a sync or a fetch writes, compared by dry runs.
+func (gr GhRepository) DryRunFields() []dryrun.Field {
+	return []dryrun.Field{
+		{Name: "full_name", Value: gr.FullName},
+		{Name: "ghr_id", Value: gr.GhrId},
+		{Name: "stars", Value: gr.Stars},
+		{Name: "forks", Value: gr.Forks},
+		{Name: "language", Value: gr.Language},
+		{Name: "owner", Value: gr.Owner.Name},
+		{Name: "owner_avatar_url", Value: gr.Owner.AvatarUrl},
+		{Name: "description", Value: gr.Description},
+		{Name: "default_branch", Value: gr.DefaultBranch},
+		{Name: "homepage", Value: gr.Homepage},
+		{Name: "license_key", Value: gr.License.Key},
+		{Name: "license_name", Value: gr.License.Name},
+		{Name: "last_commit_at", Value: gr.LastCommitAt},
+		{Name: "last_user_commit_at", Value: gr.LastUserCommitAt},
+		{Name: "archived", Value: gr.Archived},
+		{Name: "skipped", Value: gr.Skipped},
+		{Name: "created_at", Value: gr.CreatedAt},
+	}
+}
+
 func (gr GhRepository) GetDescription() string {
 	var description []rune
 	suffix := []rune("...")
@@ -91,6 +126,89 @@ type TrendingRepositoryResponse struct {
 	FeaturedCount int `json:"featured_count"` // non db column field
 }
 
+type NewTrendingRepositoryResponse struct {
+	TrendingRepositoryResponse
+	FirstTrendDate time.Time `json:"first_trend_date"` // non db column field, first date the repository trended on any trending page.
+}
+
+type RisingRepositoryResponse struct {
+	GhRepository
+	StarDelta      int       `json:"star_delta"`       // non db column field
+	ForkDelta      int       `json:"fork_delta"`       // non db column field
+	StarGrowthRate float64   `json:"star_growth_rate"` // non db column field
+	ForkGrowthRate float64   `json:"fork_growth_rate"` // non db column field
+	Since          time.Time `json:"since"`            // time of the snapshot the deltas are computed against
+}
+
+var risingWindows = map[string]int{
+	"1d":  1,
+	"7d":  7,
+	"30d": 30,
+}
+
+var risingSorts = map[string]string{
+	"stars":      "star_delta",
+	"forks":      "fork_delta",
+	"stars_rate": "star_growth_rate",
+	"forks_rate": "fork_growth_rate",
+}
+
+// Days before a rising window the baseline snapshot is looked up, the least synced repositories are synced weekly.
+const risingBaselineLookback = 7
+
+type RisingRepositoriesParams struct {
+	Days         int
+	Sort         string
+	Language     string
+	MinStars     int
+	MinStarDelta int
+	Limit        int
+}
+
+func NewRisingRepositoriesParams(windowStr, sortStr, languageStr, minStarsStr, minStarDeltaStr, limitStr string) (*RisingRepositoriesParams, error) {
+	days, ok := risingWindows[windowStr]
+	if !ok {
+		return nil, fmt.Errorf("invalid window, expected: 1d, 7d or 30d, passed %s", windowStr)
+	}
+
+	if _, ok := risingSorts[sortStr]; !ok {
+		return nil, fmt.Errorf("invalid sort, expected: stars, forks, stars_rate or forks_rate, passed %s", sortStr)
+	}
+
+	params := &RisingRepositoriesParams{
+		Days:     days,
+		Sort:     sortStr,
+		Language: strings.TrimSpace(languageStr),
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return nil, errors.New("invalid limit")
+	}
+
+	params.Limit = min(limit, 100)
+
+	if minStarsStr != "" {
+		minStars, err := strconv.Atoi(minStarsStr)
+		if err != nil {
+			return nil, errors.New("invalid min_stars")
+		}
+
+		params.MinStars = minStars
+	}
+
+	if minStarDeltaStr != "" {
+		minStarDelta, err := strconv.Atoi(minStarDeltaStr)
+		if err != nil {
+			return nil, errors.New("invalid min_star_delta")
+		}
+
+		params.MinStarDelta = minStarDelta
+	}
+
+	return params, nil
+}
+
 type GhRepositoryRepo struct {
 	db database.DB
 }
@@ -143,6 +261,8 @@ func (gr *GhRepositoryRepo) FindById(ctx context.Context, id int) (GhRepository,
 			&ghr.LastUserCommitAt,
 			&ghr.License.Key,
 			&ghr.License.Name,
+			&ghr.OrganizationId,
+			&ghr.Archived,
 			&trending.TrendDate,
 			&trending.Rank,
 			&trending.TrendingLanguage,
@@ -199,6 +319,8 @@ func (gr *GhRepositoryRepo) FindByName(ctx context.Context, name string) (GhRepo
 		&ghr.LastUserCommitAt,
 		&ghr.License.Key,
 		&ghr.License.Name,
+		&ghr.OrganizationId,
+		&ghr.Archived,
 	); err != nil {
 		return ghr, err
 	}
@@ -263,6 +385,8 @@ func (gr *GhRepositoryRepo) FindAll(ctx context.Context, opts ...any) ([]GhRepos
 			&ghr.LastUserCommitAt,
 			&ghr.License.Key,
 			&ghr.License.Name,
+			&ghr.OrganizationId,
+			&ghr.Archived,
 		); err != nil {
 			return nil, err
 		}
@@ -277,30 +401,163 @@ func (gr *GhRepositoryRepo) FindAll(ctx context.Context, opts ...any) ([]GhRepos
 	return repositories, nil
 }
 
-func (gr *GhRepositoryRepo) FindAllWithTags(ctx context.Context, filter string) ([]*GhRepository, error) {
-	var query string
-	var args []any
+// Find the ids of the entities to sync after afterId in id order, it pages through the candidates with a keyset.
+func (gr *GhRepositoryRepo) FindIdsAfter(ctx context.Context, afterId int, size int, opts ...any) ([]int, error) {
+	qb := dbutils.NewQueryBuilder()
+	qb.Query("SELECT id FROM repositories")
 
-	if filter == "today" {
-		query = "select repositories.*, tags.id as tag_id, tags.`name` as tag_name  from repositories left join repositories_tags ON repositories.id = repositories_tags.repository_id left join tags on repositories_tags.tag_id = tags.id join trending_repositories on repositories.id = trending_repositories.repository_id where trend_date = ? group by repositories.full_name, tags.id"
-		args = append(args, time.Now().Format("2006-01-02"))
-	} else {
-		query = "select repositories.*, tags.id as tag_id, tags.`name` as tag_name from repositories left join repositories_tags ON repositories.id = repositories_tags.repository_id left join tags on repositories_tags.tag_id = tags.id"
+	options := opt.ExtractOptions(opts...)
+	start, end := options.Start, options.End
+
+	qb.Where("skipped = ?", false)
+	qb.Where("id > ?", afterId)
+
+	if start != "" {
+		qb.Where("updated_at > ?", start)
+	}
+
+	if end != "" {
+		qb.Where("updated_at <= ?", end)
+	}
+
+	qb.OrderBy("id", "ASC")
+	qb.Limit(size)
+
+	q, args := qb.GetQuery()
+
+	rows, err := gr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repositories ids: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "ghRepositoryRepo.FindIdsAfter"))
+		}
+	}()
+
+	ids := make([]int, 0, size)
+
+	for rows.Next() {
+		var id int
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan repositories table, error: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ghRepositoryRepo.FindIdsAfter, rows error: %v", err)
+	}
+
+	return ids, nil
+}
+
+// Find the entities after afterId in id order with the signals of their sync schedule since the given time.
+func (gr *GhRepositoryRepo) FindSyncCandidatesAfter(ctx context.Context, afterId int, size int, since time.Time) ([]SyncCandidate, error) {
+	query := "SELECT r.id, r.full_name, r.stars, r.updated_at, " +
+		"(SELECT COUNT(DISTINCT t.trend_date) FROM trending_repositories t WHERE t.repository_id = r.id AND t.trend_date >= ?), " +
+		"(SELECT COALESCE(SUM(p.views), 0) FROM page_views p WHERE p.entity_type = ? AND p.entity_id = r.id AND p.view_date >= ?) " +
+		"FROM repositories r WHERE r.skipped = ? AND r.id > ? ORDER BY r.id ASC LIMIT ?"
+
+	sinceDate := since.Format(time.DateOnly)
+
+	rows, err := gr.db.QueryContext(ctx, query, sinceDate, EntityRepository, sinceDate, false, afterId, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repositories sync candidates: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "ghRepositoryRepo.FindSyncCandidatesAfter"))
+		}
+	}()
+
+	candidates := make([]SyncCandidate, 0, size)
+
+	for rows.Next() {
+		var candidate SyncCandidate
+
+		if err := rows.Scan(
+			&candidate.Id,
+			&candidate.Name,
+			&candidate.Stars,
+			&candidate.LastSyncedAt,
+			&candidate.TrendingDays,
+			&candidate.PageViews,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan repositories sync candidates, error: %v", err)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ghRepositoryRepo.FindSyncCandidatesAfter, rows error: %v", err)
 	}
 
+	return candidates, nil
+}
+
+// Find a page of repositories with their tags.
+func (gr *GhRepositoryRepo) FindPageWithTags(ctx context.Context, params *RepositoryListParams) (Page[GhRepository], error) {
+	qb := dbutils.NewQueryBuilder()
+	qb.Query("select repositories.* from repositories")
+
+	if params.TrendingToday {
+		qb.Where("exists (select 1 from trending_repositories where trending_repositories.repository_id = repositories.id and trending_repositories.trend_date = ?)", time.Now().Format("2006-01-02"))
+	}
+
+	if params.Language != "" {
+		qb.Where("repositories.language = ?", params.Language)
+	}
+
+	if params.License != "" {
+		qb.Where("repositories.license_key = ?", params.License)
+	}
+
+	if params.MinStars > 0 {
+		qb.Where("repositories.stars >= ?", params.MinStars)
+	}
+
+	if params.MaxStars > 0 {
+		qb.Where("repositories.stars <= ?", params.MaxStars)
+	}
+
+	if params.Skipped != nil {
+		qb.Where("repositories.skipped = ?", *params.Skipped)
+	}
+
+	if params.Archived != nil {
+		qb.Where("repositories.archived = ?", *params.Archived)
+	}
+
+	if len(params.Tags) > 0 {
+		condition, args, err := findTagCondition(ctx, gr.db, "repositories.id", params.Tags, params.TagMode)
+		if err != nil {
+			return Page[GhRepository]{}, err
+		}
+
+		qb.WhereArgs(condition, args...)
+	}
+
+	applyPage(qb, repositorySorts, "repositories.id", params.PageParams)
+
+	query, args := qb.GetQuery()
+
 	rows, err := gr.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return Page[GhRepository]{}, fmt.Errorf("failed to query repositories page: %v", err)
 	}
 
 	defer rows.Close()
 
-	collectionMap := dbutils.NewCollectionMap[int, *GhRepository]()
+	repositories := make([]GhRepository, 0, params.Limit+1)
 
 	for rows.Next() {
 		var ghr GhRepository
-		var tagId dbutils.NullInt64
-		var tagName dbutils.NullString
 
 		if err := rows.Scan(
 			&ghr.Id,
@@ -322,31 +579,78 @@ func (gr *GhRepositoryRepo) FindAllWithTags(ctx context.Context, filter string)
 			&ghr.LastUserCommitAt,
 			&ghr.License.Key,
 			&ghr.License.Name,
-			&tagId,
-			&tagName,
+			&ghr.OrganizationId,
+			&ghr.Archived,
 		); err != nil {
-			return nil, err
+			return Page[GhRepository]{}, err
 		}
 
-		if !collectionMap.Has(ghr.Id) {
-			copy := ghr
-			copy.Tags = make([]Tag, 0)
-			collectionMap.Set(ghr.Id, &copy)
-		}
+		ghr.Tags = make([]Tag, 0)
+		repositories = append(repositories, ghr)
+	}
 
-		if tagId.Valid && tagName.Valid {
-			tag := Tag{
-				Id:   int(tagId.Int64),
-				Name: tagName.String,
-			}
+	if err = rows.Err(); err != nil {
+		return Page[GhRepository]{}, err
+	}
+
+	page := newPage(repositories, params.PageParams, func(repository GhRepository) string {
+		return repositorySortValue(params.Sort, repository)
+	}, func(repository GhRepository) int {
+		return repository.Id
+	})
+
+	if err := gr.loadTags(ctx, page.Data); err != nil {
+		return Page[GhRepository]{}, err
+	}
+
+	return page, nil
+}
+
+// Load the tags of the given repositories in place.
+func (gr *GhRepositoryRepo) loadTags(ctx context.Context, repositories []GhRepository) error {
+	if len(repositories) == 0 {
+		return nil
+	}
+
+	indexes := make(map[int]int, len(repositories))
+	ids := make([]int, 0, len(repositories))
 
-			repo := collectionMap.Get(ghr.Id)
-			repo.Tags = append(repo.Tags, tag)
-			collectionMap.Set(ghr.Id, repo)
+	for i, repository := range repositories {
+		indexes[repository.Id] = i
+		ids = append(ids, repository.Id)
+	}
+
+	query, args, err := sq.Select("repositories_tags.repository_id", "tags.id", "tags.`name`").
+		From("repositories_tags").
+		Join("tags ON tags.id = repositories_tags.tag_id").
+		Where(sq.Eq{"repositories_tags.repository_id": ids}).
+		OrderBy("tags.`name` ASC").
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("failed to get SQL when find repositories tags, error: %v", err)
+	}
+
+	rows, err := gr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to find repositories tags, error: %v", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var repositoryId int
+		var tag Tag
+
+		if err := rows.Scan(&repositoryId, &tag.Id, &tag.Name); err != nil {
+			return fmt.Errorf("failed to scan repositories tags, error: %v", err)
 		}
+
+		repository := &repositories[indexes[repositoryId]]
+		repository.Tags = append(repository.Tags, tag)
 	}
 
-	return collectionMap.All(), nil
+	return rows.Err()
 }
 
 func (gr *GhRepositoryRepo) FindTrendingRepositories(ctx context.Context, opts ...any) ([]TrendingRepositoryResponse, error) {
@@ -373,6 +677,15 @@ func (gr *GhRepositoryRepo) FindTrendingRepositories(ctx context.Context, opts .
 		qb.Where("`trending_repositories`.`trend_date` > ?", since.Format("2006-01-02"))
 	}
 
+	if len(options.Tags) > 0 {
+		condition, args, err := findTagCondition(ctx, gr.db, "repositories.id", options.Tags, options.TagMode)
+		if err != nil {
+			return nil, err
+		}
+
+		qb.WhereArgs(condition, args...)
+	}
+
 	if limit > 0 {
 		qb.Limit(limit)
 	}
@@ -414,6 +727,8 @@ func (gr *GhRepositoryRepo) FindTrendingRepositories(ctx context.Context, opts .
 			&trr.LastUserCommitAt,
 			&trr.License.Key,
 			&trr.License.Name,
+			&trr.OrganizationId,
+			&trr.Archived,
 			&trr.FeaturedCount,
 			&trr.BestRanking,
 		); err != nil {
@@ -430,6 +745,192 @@ func (gr *GhRepositoryRepo) FindTrendingRepositories(ctx context.Context, opts .
 	return repositories, nil
 }
 
+// Find repositories which trended for the first time within the date range, the most recent debutants first.
+func (gr *GhRepositoryRepo) FindNewTrendingRepositories(ctx context.Context, opts ...any) ([]NewTrendingRepositoryResponse, error) {
+	query := "select repositories.*, count(*) as count, min(trending_repositories.`rank`) as best_ranking, min(first_trendings.first_trend_date) as first_trend_date from repositories " +
+		"join trending_repositories on repositories.id = trending_repositories.repository_id " +
+		"join (select repository_id, min(trend_date) as first_trend_date from trending_repositories where repository_id is not null group by repository_id) as first_trendings on first_trendings.repository_id = repositories.id"
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(query)
+
+	qb.OrderBy("first_trend_date", "DESC")
+	qb.OrderBy("best_ranking", "ASC")
+	qb.OrderBy("repositories.id", "ASC")
+
+	options := opt.ExtractOptions(opts...)
+	lang, dateRange, limit := options.Language, options.DateRange, options.Limit
+
+	if lang != "" {
+		qb.Where("`trending_repositories`.`language` = ?", lang)
+	} else {
+		qb.Where("`trending_repositories`.`language` is null", nil)
+	}
+
+	since := time.Now().AddDate(0, 0, -dateRange).Format("2006-01-02")
+	qb.Where("`trending_repositories`.`trend_date` > ?", since)
+	qb.Where("first_trendings.first_trend_date > ?", since)
+
+	if limit > 0 {
+		qb.Limit(limit)
+	}
+
+	qb.GroupBy("repositories.id")
+
+	q, args := qb.GetQuery()
+
+	rows, err := gr.db.QueryContext(ctx, q, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query new trending repositories: %v", err)
+	}
+
+	defer rows.Close()
+
+	repositories := make([]NewTrendingRepositoryResponse, 0)
+
+	for rows.Next() {
+		var nrr NewTrendingRepositoryResponse
+
+		if err := rows.Scan(
+			&nrr.Id,
+			&nrr.GhrId,
+			&nrr.Stars,
+			&nrr.Forks,
+			&nrr.FullName,
+			&nrr.Language,
+			&nrr.Owner.Name,
+			&nrr.Owner.AvatarUrl,
+			&nrr.CreatedAt,
+			&nrr.UpdatedAt,
+			&nrr.Description,
+			&nrr.DefaultBranch,
+			&nrr.Homepage,
+			&nrr.Skipped,
+			&nrr.NumberOfContributors,
+			&nrr.LastCommitAt,
+			&nrr.LastUserCommitAt,
+			&nrr.License.Key,
+			&nrr.License.Name,
+			&nrr.OrganizationId,
+			&nrr.Archived,
+			&nrr.FeaturedCount,
+			&nrr.BestRanking,
+			&nrr.FirstTrendDate,
+		); err != nil {
+			return nil, err
+		}
+
+		repositories = append(repositories, nrr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return repositories, nil
+}
+
+// Rank repositories by how much their stars and forks grew since the start of the window.
+// The baseline is the latest snapshot taken at or before the window starts, or the first one within the window
+// for repositories with no earlier snapshot.
+func (gr *GhRepositoryRepo) FindRisingRepositories(ctx context.Context, params *RisingRepositoriesParams) ([]RisingRepositoryResponse, error) {
+	orderBy, ok := risingSorts[params.Sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort: %s", params.Sort)
+	}
+
+	since := time.Now().AddDate(0, 0, -params.Days)
+
+	// Earlier snapshots are looked up as far back as the window is long, at least as far as the least synced repositories.
+	lookbackSince := since.AddDate(0, 0, -max(params.Days, risingBaselineLookback))
+
+	qb := sq.Select(
+		"repositories.*",
+		"repositories.stars - baseline.stars as star_delta",
+		"repositories.forks - baseline.forks as fork_delta",
+		"(repositories.stars - baseline.stars) / GREATEST(baseline.stars, 1) as star_growth_rate",
+		"(repositories.forks - baseline.forks) / GREATEST(baseline.forks, 1) as fork_growth_rate",
+		"baseline.created_at",
+	).
+		From("repositories").
+		Join(`(select s.repository_id, s.stars, s.forks, s.created_at from repository_snapshots s
+join (select repository_id, coalesce(max(case when created_at <= ? then id end), min(id)) as id from repository_snapshots where created_at >= ? group by repository_id) baseline_snapshot on baseline_snapshot.id = s.id) baseline
+on baseline.repository_id = repositories.id`, since.Format(time.DateTime), lookbackSince.Format(time.DateTime)).
+		Where("repositories.skipped = false").
+		OrderBy(orderBy+" DESC", "repositories.id ASC").
+		Limit(uint64(params.Limit))
+
+	if params.Language != "" {
+		qb = qb.Where("repositories.language = ?", params.Language)
+	}
+
+	if params.MinStars > 0 {
+		qb = qb.Where("repositories.stars >= ?", params.MinStars)
+	}
+
+	if params.MinStarDelta > 0 {
+		qb = qb.Where("repositories.stars - baseline.stars >= ?", params.MinStarDelta)
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SQL when find rising repositories, error: %v", err)
+	}
+
+	rows, err := gr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rising repositories: %v", err)
+	}
+
+	defer rows.Close()
+
+	repositories := make([]RisingRepositoryResponse, 0)
+
+	for rows.Next() {
+		var rr RisingRepositoryResponse
+
+		if err := rows.Scan(
+			&rr.Id,
+			&rr.GhrId,
+			&rr.Stars,
+			&rr.Forks,
+			&rr.FullName,
+			&rr.Language,
+			&rr.Owner.Name,
+			&rr.Owner.AvatarUrl,
+			&rr.CreatedAt,
+			&rr.UpdatedAt,
+			&rr.Description,
+			&rr.DefaultBranch,
+			&rr.Homepage,
+			&rr.Skipped,
+			&rr.NumberOfContributors,
+			&rr.LastCommitAt,
+			&rr.LastUserCommitAt,
+			&rr.License.Key,
+			&rr.License.Name,
+			&rr.OrganizationId,
+			&rr.Archived,
+			&rr.StarDelta,
+			&rr.ForkDelta,
+			&rr.StarGrowthRate,
+			&rr.ForkGrowthRate,
+			&rr.Since,
+		); err != nil {
+			return nil, err
+		}
+
+		repositories = append(repositories, rr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return repositories, nil
+}
+
 func (gr *GhRepositoryRepo) FindRepositoriesByNames(ctx context.Context, names []string) ([]GhRepository, error) {
 	ghRepos := make([]GhRepository, 0)
 
@@ -470,6 +971,8 @@ func (gr *GhRepositoryRepo) FindRepositoriesByNames(ctx context.Context, names [
 			&ghr.LastUserCommitAt,
 			&ghr.License.Key,
 			&ghr.License.Name,
+			&ghr.OrganizationId,
+			&ghr.Archived,
 		); err != nil {
 			return ghRepos, err
 		}
@@ -485,7 +988,7 @@ func (gr *GhRepositoryRepo) FindRepositoriesByNames(ctx context.Context, names [
 }
 
 func (gr *GhRepositoryRepo) Save(ctx context.Context, ghRepo GhRepository) (int64, error) {
-	query := "INSERT INTO `repositories` (`full_name`, `ghr_id`, stars, forks, `language`, `owner`, `owner_avatar_url`, `description`, `default_branch`, `homepage`, `created_at`, `updated_at`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	query := "INSERT INTO `repositories` (`full_name`, `ghr_id`, stars, forks, `language`, `owner`, `owner_avatar_url`, `description`, `default_branch`, `homepage`, `organization_id`, `created_at`, `updated_at`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
 
 	var lastInsertId int64
 
@@ -503,6 +1006,7 @@ func (gr *GhRepositoryRepo) Save(ctx context.Context, ghRepo GhRepository) (int6
 		ghRepo.GetDescription(),
 		ghRepo.DefaultBranch,
 		ghRepo.Homepage,
+		ghRepo.OrganizationId,
 		createdAt.Format(time.DateTime),
 		updatedAt.Format(time.DateTime),
 	)
@@ -545,6 +1049,8 @@ func (gr *GhRepositoryRepo) Update(ctx context.Context, ghRepo GhRepository) err
 		Set("last_user_commit_at", ghRepo.LastUserCommitAt).
 		Set("license_key", ghRepo.License.Key).
 		Set("license_name", ghRepo.License.Name).
+		Set("organization_id", ghRepo.OrganizationId).
+		Set("archived", ghRepo.Archived).
 		Set("created_at", ghRepo.CreatedAt.Format(time.DateTime)).
 		Set("updated_at", updatedAt.Format(time.DateTime)).Where(sq.Eq{"id": ghRepo.Id})
 
@@ -575,6 +1081,8 @@ func (gr *GhRepositoryRepo) Update(ctx context.Context, ghRepo GhRepository) err
 	return nil
 }
 
+// Replace all tags of a repository with a manually curated list, auto assigned tags are removed as well.
+// The tags left out of the list are rejected so auto-tagging does not assign them again.
 func (gr *GhRepositoryRepo) SaveTags(ctx context.Context, ghRepo GhRepository, tags []Tag) error {
 	tx, err := gr.db.BeginTx(ctx, nil)
 
@@ -583,7 +1091,14 @@ func (gr *GhRepositoryRepo) SaveTags(ctx context.Context, ghRepo GhRepository, t
 	}
 
 	defer tx.Rollback()
-	query := "DELETE FROM `repositories_tags` WHERE repository_id = ?"
+
+	query := "INSERT IGNORE INTO `repository_rejected_tags` (`repository_id`, `tag_id`, `rejected_at`) SELECT `repository_id`, `tag_id`, ? FROM `repositories_tags` WHERE repository_id = ?"
+
+	if _, err := tx.ExecContext(ctx, query, time.Now().Format(time.DateTime), ghRepo.Id); err != nil {
+		return fmt.Errorf("failed to run insert repository_rejected_tags query, repository id: %d, error: %v", ghRepo.Id, err)
+	}
+
+	query = "DELETE FROM `repositories_tags` WHERE repository_id = ?"
 
 	_, err = tx.ExecContext(ctx, query, ghRepo.Id)
 
@@ -592,9 +1107,9 @@ func (gr *GhRepositoryRepo) SaveTags(ctx context.Context, ghRepo GhRepository, t
 	}
 
 	for _, tag := range tags {
-		query := "INSERT INTO `repositories_tags` (`repository_id`, `tag_id`) VALUES (?, ?)"
+		query := "INSERT INTO `repositories_tags` (`repository_id`, `tag_id`, `source`) VALUES (?, ?, ?)"
 
-		result, err := tx.ExecContext(ctx, query, ghRepo.Id, tag.Id)
+		result, err := tx.ExecContext(ctx, query, ghRepo.Id, tag.Id, TagSourceManual)
 
 		if err != nil {
 			return fmt.Errorf("failed to run insert repositories_tags query, repository id: %d, tag id: %d error: %v", ghRepo.Id, tag.Id, err)
@@ -605,6 +1120,201 @@ func (gr *GhRepositoryRepo) SaveTags(ctx context.Context, ghRepo GhRepository, t
 		if err != nil {
 			return fmt.Errorf("repositories_tags insert rows affected returns error: %v", err)
 		}
+
+		if err := unrejectTag(ctx, tx, ghRepo.Id, tag.Id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// A curator assigned the tag again, auto-tagging may assign it from now on.
+func unrejectTag(ctx context.Context, tx *sql.Tx, repositoryId, tagId int) error {
+	query := "DELETE FROM `repository_rejected_tags` WHERE repository_id = ? AND tag_id = ?"
+
+	if _, err := tx.ExecContext(ctx, query, repositoryId, tagId); err != nil {
+		return fmt.Errorf("failed to run delete repository_rejected_tags query, repository id: %d, tag id: %d error: %v", repositoryId, tagId, err)
+	}
+
+	return nil
+}
+
+// Manually assign a tag to many repositories at once, existing auto assignments become manual.
+// Repository ids are expected to be unique, ErrRepositoryNotFound is returned if one of them does not exist.
+func (gr *GhRepositoryRepo) AssignTag(ctx context.Context, tag Tag, repositoryIds []int) error {
+	tx, err := gr.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to begin repositories assign tag transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	query, args, err := sq.Select("COUNT(*)").From("repositories").Where(sq.Eq{"id": repositoryIds}).ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to get SQL when count repositories, error: %v", err)
+	}
+
+	var found int
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&found); err != nil {
+		return fmt.Errorf("failed to count repositories to assign tag, error: %v", err)
+	}
+
+	if found != len(repositoryIds) {
+		return ErrRepositoryNotFound
+	}
+
+	for _, repositoryId := range repositoryIds {
+		query := "INSERT INTO `repositories_tags` (`repository_id`, `tag_id`, `source`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `source` = VALUES(`source`)"
+
+		if _, err := tx.ExecContext(ctx, query, repositoryId, tag.Id, TagSourceManual); err != nil {
+			return fmt.Errorf("failed to run insert repositories_tags query, repository id: %d, tag id: %d error: %v", repositoryId, tag.Id, err)
+		}
+
+		if err := unrejectTag(ctx, tx, repositoryId, tag.Id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Remove a tag from many repositories at once, whether it was assigned manually or automatically.
+// The tag is rejected for the repositories so auto-tagging does not assign it again.
+func (gr *GhRepositoryRepo) UnassignTag(ctx context.Context, tag Tag, repositoryIds []int) error {
+	tx, err := gr.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to begin repositories unassign tag transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	rejectedAt := time.Now().Format(time.DateTime)
+
+	for _, repositoryId := range repositoryIds {
+		query := "DELETE FROM `repositories_tags` WHERE repository_id = ? AND tag_id = ?"
+
+		if _, err := tx.ExecContext(ctx, query, repositoryId, tag.Id); err != nil {
+			return fmt.Errorf("failed to run delete repositories_tags query, repository id: %d, tag id: %d error: %v", repositoryId, tag.Id, err)
+		}
+
+		query = "INSERT IGNORE INTO `repository_rejected_tags` (`repository_id`, `tag_id`, `rejected_at`) VALUES (?, ?, ?)"
+
+		if _, err := tx.ExecContext(ctx, query, repositoryId, tag.Id, rejectedAt); err != nil {
+			return fmt.Errorf("failed to run insert repository_rejected_tags query, repository id: %d, tag id: %d error: %v", repositoryId, tag.Id, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Replace the automatically assigned tags of a repository.
+// Repositories with manually curated tags are left untouched and tags a curator removed are not assigned again,
+// so auto-tagging never overrides a curator.
+func (gr *GhRepositoryRepo) SaveAutoTags(ctx context.Context, ghRepo GhRepository, tags []Tag) error {
+	tx, err := gr.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to begin repository auto tags transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	var manualTags int
+
+	query := "SELECT COUNT(*) FROM `repositories_tags` WHERE repository_id = ? AND source = ? FOR UPDATE"
+
+	if err := tx.QueryRowContext(ctx, query, ghRepo.Id, TagSourceManual).Scan(&manualTags); err != nil {
+		return fmt.Errorf("failed to count manual repositories_tags, repository id: %d, error: %v", ghRepo.Id, err)
+	}
+
+	if manualTags > 0 {
+		return nil
+	}
+
+	rejected, err := findRejectedTagIds(ctx, tx, ghRepo.Id)
+	if err != nil {
+		return err
+	}
+
+	query = "DELETE FROM `repositories_tags` WHERE repository_id = ? AND source = ?"
+
+	if _, err := tx.ExecContext(ctx, query, ghRepo.Id, TagSourceAuto); err != nil {
+		return fmt.Errorf("failed to run delete auto repositories_tags query, repository id: %d, error: %v", ghRepo.Id, err)
+	}
+
+	for _, tag := range tags {
+		if rejected[tag.Id] {
+			continue
+		}
+
+		query := "INSERT INTO `repositories_tags` (`repository_id`, `tag_id`, `source`) VALUES (?, ?, ?)"
+
+		if _, err := tx.ExecContext(ctx, query, ghRepo.Id, tag.Id, TagSourceAuto); err != nil {
+			return fmt.Errorf("failed to run insert auto repositories_tags query, repository id: %d, tag id: %d error: %v", ghRepo.Id, tag.Id, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func findRejectedTagIds(ctx context.Context, tx *sql.Tx, repositoryId int) (map[int]bool, error) {
+	query := "SELECT tag_id FROM `repository_rejected_tags` WHERE repository_id = ?"
+
+	rows, err := tx.QueryContext(ctx, query, repositoryId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository_rejected_tags, repository id: %d, error: %v", repositoryId, err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "ghRepositoryRepo.findRejectedTagIds"))
+		}
+	}()
+
+	rejected := make(map[int]bool)
+
+	for rows.Next() {
+		var tagId int
+
+		if err := rows.Scan(&tagId); err != nil {
+			return nil, fmt.Errorf("failed to scan repository_rejected_tags table, error: %v", err)
+		}
+
+		rejected[tagId] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ghRepositoryRepo.findRejectedTagIds, rows error: %v", err)
+	}
+
+	return rejected, nil
+}
+
+// Replace the GitHub topics of a repository.
+func (gr *GhRepositoryRepo) SaveTopics(ctx context.Context, ghRepo GhRepository) error {
+	tx, err := gr.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to begin repository topics transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	query := "DELETE FROM `repository_topics` WHERE repository_id = ?"
+
+	if _, err := tx.ExecContext(ctx, query, ghRepo.Id); err != nil {
+		return fmt.Errorf("failed to run delete repository_topics query, repository id: %d, error: %v", ghRepo.Id, err)
+	}
+
+	for _, topic := range ghRepo.Topics {
+		query := "INSERT IGNORE INTO `repository_topics` (`repository_id`, `topic`) VALUES (?, ?)"
+
+		if _, err := tx.ExecContext(ctx, query, ghRepo.Id, topic); err != nil {
+			return fmt.Errorf("failed to run insert repository_topics query, repository id: %d, topic: %s, error: %v", ghRepo.Id, topic, err)
+		}
 	}
 
 	return tx.Commit()
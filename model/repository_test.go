@@ -22,3 +22,28 @@ func TestGetDescription(t *testing.T) {
 		t.Errorf("want: %s but got: %s", want, gh.GetDescription())
 	}
 }
+
+func TestNewRisingRepositoriesParams(t *testing.T) {
+	params, err := NewRisingRepositoriesParams("7d", "stars", " Go ", "100", "", "500")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params.Days != 7 || params.Language != "Go" || params.MinStars != 100 || params.MinStarDelta != 0 || params.Limit != 100 {
+		t.Errorf("unexpected params: %+v", params)
+	}
+
+	invalids := [][]string{
+		{"2d", "stars", "", "", "", "20"},
+		{"7d", "watchers", "", "", "", "20"},
+		{"7d", "stars", "", "abc", "", "20"},
+		{"7d", "stars", "", "", "abc", "20"},
+		{"7d", "stars", "", "", "", "0"},
+	}
+
+	for _, args := range invalids {
+		if _, err := NewRisingRepositoriesParams(args[0], args[1], args[2], args[3], args[4], args[5]); err == nil {
+			t.Errorf("expected error for args: %v", args)
+		}
+	}
+}
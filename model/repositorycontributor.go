@@ -0,0 +1,134 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+// RepositoryContributor is a developer listed in the top contributors of a repository.
+type RepositoryContributor struct {
+	DeveloperId   int                `json:"developer_id"`
+	Username      string             `json:"login"`
+	AvatarUrl     string             `json:"avatar_url"`
+	Name          dbutils.NullString `json:"name"`
+	Contributions int                `json:"contributions"`
+}
+
+// ContributedRepository is a repository a developer is one of the top contributors of.
+type ContributedRepository struct {
+	RepositoryId  int    `json:"repository_id"`
+	FullName      string `json:"full_name"`
+	Language      string `json:"language"`
+	Stars         int    `json:"stars"`
+	Contributions int    `json:"contributions"`
+}
+
+type RepositoryContributorRepo struct {
+	db database.DB
+}
+
+func NewRepositoryContributorRepo(db database.DB) *RepositoryContributorRepo {
+	return &RepositoryContributorRepo{
+		db: db,
+	}
+}
+
+// Replace the top contributors of a repository, contributors are expected to be ordered by contributions.
+func (rcr *RepositoryContributorRepo) Save(ctx context.Context, repositoryId int, contributors []RepositoryContributor) error {
+	tx, err := rcr.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to begin repository contributors transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	query := "DELETE FROM `repository_contributors` WHERE repository_id = ?"
+
+	if _, err := tx.ExecContext(ctx, query, repositoryId); err != nil {
+		return fmt.Errorf("failed to run delete repository_contributors query, repository id: %d, error: %v", repositoryId, err)
+	}
+
+	updatedAt := time.Now().Format(time.DateTime)
+
+	for position, contributor := range contributors {
+		query := "INSERT INTO `repository_contributors` (`repository_id`, `developer_id`, `contributions`, `position`, `updated_at`) VALUES (?, ?, ?, ?, ?)"
+
+		if _, err := tx.ExecContext(ctx, query, repositoryId, contributor.DeveloperId, contributor.Contributions, position+1, updatedAt); err != nil {
+			return fmt.Errorf("failed to run insert repository_contributors query, repository id: %d, developer id: %d, error: %v", repositoryId, contributor.DeveloperId, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (rcr *RepositoryContributorRepo) FindByRepositoryId(ctx context.Context, repositoryId int) ([]RepositoryContributor, error) {
+	query := "SELECT developers.id, developers.username, developers.avatar_url, developers.name, rc.contributions FROM repository_contributors rc JOIN developers ON developers.id = rc.developer_id WHERE rc.repository_id = ? ORDER BY rc.position ASC"
+
+	rows, err := rcr.db.QueryContext(ctx, query, repositoryId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repository contributors, error: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "repositoryContributorRepo.FindByRepositoryId"))
+		}
+	}()
+
+	contributors := make([]RepositoryContributor, 0)
+
+	for rows.Next() {
+		var contributor RepositoryContributor
+
+		if err := rows.Scan(&contributor.DeveloperId, &contributor.Username, &contributor.AvatarUrl, &contributor.Name, &contributor.Contributions); err != nil {
+			return nil, fmt.Errorf("failed to scan repository_contributors table, error: %v", err)
+		}
+
+		contributors = append(contributors, contributor)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repositoryContributorRepo.FindByRepositoryId, rows error: %v", err)
+	}
+
+	return contributors, nil
+}
+
+func (rcr *RepositoryContributorRepo) FindByDeveloperId(ctx context.Context, developerId int) ([]ContributedRepository, error) {
+	query := "SELECT repositories.id, repositories.full_name, repositories.language, repositories.stars, rc.contributions FROM repository_contributors rc JOIN repositories ON repositories.id = rc.repository_id WHERE rc.developer_id = ? AND repositories.skipped = false ORDER BY rc.contributions DESC, repositories.id ASC"
+
+	rows, err := rcr.db.QueryContext(ctx, query, developerId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find contributed repositories, error: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "repositoryContributorRepo.FindByDeveloperId"))
+		}
+	}()
+
+	repositories := make([]ContributedRepository, 0)
+
+	for rows.Next() {
+		var repository ContributedRepository
+
+		if err := rows.Scan(&repository.RepositoryId, &repository.FullName, &repository.Language, &repository.Stars, &repository.Contributions); err != nil {
+			return nil, fmt.Errorf("failed to scan repository_contributors table, error: %v", err)
+		}
+
+		repositories = append(repositories, repository)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repositoryContributorRepo.FindByDeveloperId, rows error: %v", err)
+	}
+
+	return repositories, nil
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/liweiyi88/trendshift-backend/model/opt"
+)
+
+var repositorySorts = map[string]sortColumn{
+	"stars":          {expression: "repositories.stars", numeric: true},
+	"forks":          {expression: "repositories.forks", numeric: true},
+	"created_at":     {expression: "repositories.created_at"},
+	"updated_at":     {expression: "repositories.updated_at"},
+	"last_commit_at": {expression: "COALESCE(repositories.last_commit_at, '" + cursorNullTime + "')"},
+}
+
+type RepositoryListParams struct {
+	PageParams
+	TrendingToday bool // only repositories on a trending page today.
+	Language      string
+	License       string // license key, e.g. mit.
+	Tags          []string
+	TagMode       string
+	MinStars      int // no lower bound when 0.
+	MaxStars      int // no upper bound when 0.
+	Skipped       *bool
+	Archived      *bool
+}
+
+func NewRepositoryListParams(sortStr, orderStr, limitStr, cursorStr, languageStr, licenseStr, minStarsStr, maxStarsStr, skippedStr, archivedStr string) (*RepositoryListParams, error) {
+	pageParams, err := newPageParams(repositorySorts, sortStr, orderStr, limitStr, cursorStr)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &RepositoryListParams{
+		PageParams: pageParams,
+		Language:   strings.TrimSpace(languageStr),
+		License:    strings.ToLower(strings.TrimSpace(licenseStr)),
+	}
+
+	if minStarsStr != "" {
+		params.MinStars, err = strconv.Atoi(minStarsStr)
+		if err != nil || params.MinStars < 0 {
+			return nil, errors.New("invalid min_stars")
+		}
+	}
+
+	if maxStarsStr != "" {
+		params.MaxStars, err = strconv.Atoi(maxStarsStr)
+		if err != nil || params.MaxStars < 0 {
+			return nil, errors.New("invalid max_stars")
+		}
+	}
+
+	if params.MaxStars > 0 && params.MaxStars < params.MinStars {
+		return nil, errors.New("max_stars is less than min_stars")
+	}
+
+	if params.Skipped, err = parseBoolFilter("skipped", skippedStr); err != nil {
+		return nil, err
+	}
+
+	if params.Archived, err = parseBoolFilter("archived", archivedStr); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// Filter repositories by tag names, mode is either and or or.
+func (params *RepositoryListParams) SetTags(tags []string, mode string) error {
+	tagMode, err := ParseTagMode(mode)
+	if err != nil {
+		return err
+	}
+
+	params.Tags = opt.Tags(tags...).Get()
+	params.TagMode = tagMode
+
+	return nil
+}
+
+func repositorySortValue(sort string, repository GhRepository) string {
+	switch sort {
+	case "stars":
+		return strconv.Itoa(repository.Stars)
+	case "forks":
+		return strconv.Itoa(repository.Forks)
+	case "created_at":
+		return cursorTime(repository.CreatedAt)
+	case "updated_at":
+		return cursorTime(repository.UpdatedAt)
+	default:
+		return cursorNullableTime(repository.LastCommitAt)
+	}
+}
@@ -0,0 +1,296 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const similarityInsertBatchSize = 500
+
+// RepositorySimilarity is the precomputed similarity of a repository to a related one.
+type RepositorySimilarity struct {
+	RepositoryId        int
+	RelatedRepositoryId int
+	Score               float64
+	SharedTags          int
+	SharedTopics        int
+	SameLanguage        bool
+	CoTrending          int // number of trending pages both repositories appeared on.
+}
+
+// RelatedRepository is a repository recommended alongside another one.
+type RelatedRepository struct {
+	RepositoryId int                `json:"repository_id"`
+	FullName     string             `json:"full_name"`
+	Owner        Owner              `json:"owner"`
+	Description  dbutils.NullString `json:"description"`
+	Language     dbutils.NullString `json:"language"`
+	Stars        int                `json:"stars"`
+	Forks        int                `json:"forks"`
+	Score        float64            `json:"score"`
+	SharedTags   int                `json:"shared_tags"`
+	SharedTopics int                `json:"shared_topics"`
+	SameLanguage bool               `json:"same_language"`
+	CoTrending   int                `json:"co_trending"`
+}
+
+// RepositoryFeatures are the signals repositories are compared on.
+type RepositoryFeatures struct {
+	Language string
+	Tags     []int
+	Topics   []string
+}
+
+// RepositoryPair is an unordered pair of repository ids, the smaller id always comes first.
+type RepositoryPair struct {
+	First  int
+	Second int
+}
+
+func NewRepositoryPair(a, b int) RepositoryPair {
+	if a > b {
+		a, b = b, a
+	}
+
+	return RepositoryPair{First: a, Second: b}
+}
+
+type RepositorySimilarityRepo struct {
+	db database.DB
+}
+
+func NewRepositorySimilarityRepo(db database.DB) *RepositorySimilarityRepo {
+	return &RepositorySimilarityRepo{
+		db: db,
+	}
+}
+
+// Load the language, tags and topics of every repository which is not skipped.
+func (rsr *RepositorySimilarityRepo) FindFeatures(ctx context.Context) (map[int]*RepositoryFeatures, error) {
+	features := make(map[int]*RepositoryFeatures)
+
+	rows, err := rsr.db.QueryContext(ctx, "SELECT id, COALESCE(`language`, '') FROM repositories WHERE skipped = false")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository languages, error: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "repositorySimilarityRepo.FindFeatures"))
+		}
+	}()
+
+	for rows.Next() {
+		var id int
+		var language string
+
+		if err := rows.Scan(&id, &language); err != nil {
+			return nil, fmt.Errorf("failed to scan repository languages, error: %v", err)
+		}
+
+		features[id] = &RepositoryFeatures{Language: language}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repositorySimilarityRepo.FindFeatures, rows error: %v", err)
+	}
+
+	tagRows, err := rsr.db.QueryContext(ctx, "SELECT repository_id, tag_id FROM repositories_tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository tags, error: %v", err)
+	}
+
+	defer func() {
+		if err := tagRows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "repositorySimilarityRepo.FindFeatures"))
+		}
+	}()
+
+	for tagRows.Next() {
+		var id, tagId int
+
+		if err := tagRows.Scan(&id, &tagId); err != nil {
+			return nil, fmt.Errorf("failed to scan repository tags, error: %v", err)
+		}
+
+		if f, ok := features[id]; ok {
+			f.Tags = append(f.Tags, tagId)
+		}
+	}
+
+	if err = tagRows.Err(); err != nil {
+		return nil, fmt.Errorf("repositorySimilarityRepo.FindFeatures, rows error: %v", err)
+	}
+
+	topicRows, err := rsr.db.QueryContext(ctx, "SELECT repository_id, topic FROM repository_topics")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository topics, error: %v", err)
+	}
+
+	defer func() {
+		if err := topicRows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "repositorySimilarityRepo.FindFeatures"))
+		}
+	}()
+
+	for topicRows.Next() {
+		var id int
+		var topic string
+
+		if err := topicRows.Scan(&id, &topic); err != nil {
+			return nil, fmt.Errorf("failed to scan repository topics, error: %v", err)
+		}
+
+		if f, ok := features[id]; ok {
+			f.Topics = append(f.Topics, topic)
+		}
+	}
+
+	if err = topicRows.Err(); err != nil {
+		return nil, fmt.Errorf("repositorySimilarityRepo.FindFeatures, rows error: %v", err)
+	}
+
+	return features, nil
+}
+
+// Count the trending pages, a trend date and a language list, two repositories appeared on together since the given time.
+func (rsr *RepositorySimilarityRepo) FindCoTrending(ctx context.Context, since time.Time) (map[RepositoryPair]int, error) {
+	query := "SELECT a.repository_id, b.repository_id, COUNT(*) FROM trending_repositories a " +
+		"JOIN trending_repositories b ON a.trend_date = b.trend_date AND a.language <=> b.language AND a.repository_id < b.repository_id " +
+		"WHERE a.trend_date > ? AND a.repository_id IS NOT NULL AND b.repository_id IS NOT NULL " +
+		"GROUP BY a.repository_id, b.repository_id"
+
+	rows, err := rsr.db.QueryContext(ctx, query, since.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query co-trending repositories, error: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "repositorySimilarityRepo.FindCoTrending"))
+		}
+	}()
+
+	coTrending := make(map[RepositoryPair]int)
+
+	for rows.Next() {
+		var a, b, count int
+
+		if err := rows.Scan(&a, &b, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan co-trending repositories, error: %v", err)
+		}
+
+		coTrending[NewRepositoryPair(a, b)] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repositorySimilarityRepo.FindCoTrending, rows error: %v", err)
+	}
+
+	return coTrending, nil
+}
+
+// Replace all precomputed similarities, the previous ones are served until the transaction commits.
+func (rsr *RepositorySimilarityRepo) ReplaceAll(ctx context.Context, similarities []RepositorySimilarity) error {
+	tx, err := rsr.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to begin repository similarities transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM `repository_similarities`"); err != nil {
+		return fmt.Errorf("failed to run delete repository_similarities query, error: %v", err)
+	}
+
+	updatedAt := time.Now().Format(time.DateTime)
+
+	for start := 0; start < len(similarities); start += similarityInsertBatchSize {
+		end := min(start+similarityInsertBatchSize, len(similarities))
+
+		qb := sq.Insert("repository_similarities").
+			Columns("repository_id", "related_repository_id", "score", "shared_tags", "shared_topics", "same_language", "co_trending", "updated_at")
+
+		for _, similarity := range similarities[start:end] {
+			qb = qb.Values(
+				similarity.RepositoryId,
+				similarity.RelatedRepositoryId,
+				similarity.Score,
+				similarity.SharedTags,
+				similarity.SharedTopics,
+				similarity.SameLanguage,
+				similarity.CoTrending,
+				updatedAt,
+			)
+		}
+
+		query, args, err := qb.ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to get SQL when insert repository similarities, error: %v", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to run insert repository_similarities query, error: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Returns the most similar repositories of a repository, the most similar first.
+func (rsr *RepositorySimilarityRepo) FindRelated(ctx context.Context, repositoryId int, limit int) ([]RelatedRepository, error) {
+	query := "SELECT repositories.id, repositories.full_name, repositories.owner, repositories.owner_avatar_url, repositories.description, repositories.language, repositories.stars, repositories.forks, " +
+		"rs.score, rs.shared_tags, rs.shared_topics, rs.same_language, rs.co_trending FROM repository_similarities rs " +
+		"JOIN repositories ON repositories.id = rs.related_repository_id " +
+		"WHERE rs.repository_id = ? AND repositories.skipped = false ORDER BY rs.score DESC, repositories.id ASC LIMIT ?"
+
+	rows, err := rsr.db.QueryContext(ctx, query, repositoryId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find related repositories, error: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "repositorySimilarityRepo.FindRelated"))
+		}
+	}()
+
+	related := make([]RelatedRepository, 0)
+
+	for rows.Next() {
+		var repository RelatedRepository
+
+		if err := rows.Scan(
+			&repository.RepositoryId,
+			&repository.FullName,
+			&repository.Owner.Name,
+			&repository.Owner.AvatarUrl,
+			&repository.Description,
+			&repository.Language,
+			&repository.Stars,
+			&repository.Forks,
+			&repository.Score,
+			&repository.SharedTags,
+			&repository.SharedTopics,
+			&repository.SameLanguage,
+			&repository.CoTrending,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan related repositories, error: %v", err)
+		}
+
+		related = append(related, repository)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repositorySimilarityRepo.FindRelated, rows error: %v", err)
+	}
+
+	return related, nil
+}
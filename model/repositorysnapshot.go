@@ -0,0 +1,114 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/liweiyi88/trendshift-backend/database"
+)
+
+// RepositorySnapshot is the stars and forks of a repository at the time it was synced.
+type RepositorySnapshot struct {
+	Id           int       `json:"id"`
+	RepositoryId int       `json:"repository_id"`
+	Stars        int       `json:"stars"`
+	Forks        int       `json:"forks"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Longest history range in days, about ten years.
+const maxHistoryRange = 3650
+
+// Returns the start of a counter history range of the given number of days before now.
+// An empty range is the full history, i.e. a zero time.
+func ParseHistorySince(rangeStr string, now time.Time) (time.Time, error) {
+	if rangeStr == "" {
+		return time.Time{}, nil
+	}
+
+	dateRange, err := strconv.Atoi(rangeStr)
+	if err != nil || dateRange <= 0 {
+		return time.Time{}, errors.New("invalid range")
+	}
+
+	return now.AddDate(0, 0, -min(dateRange, maxHistoryRange)), nil
+}
+
+type RepositorySnapshotRepo struct {
+	db database.DB
+}
+
+func NewRepositorySnapshotRepo(db database.DB) *RepositorySnapshotRepo {
+	return &RepositorySnapshotRepo{
+		db: db,
+	}
+}
+
+func (rsr *RepositorySnapshotRepo) Save(ctx context.Context, repository GhRepository) error {
+	query := "INSERT INTO `repository_snapshots` (`repository_id`, `stars`, `forks`, `created_at`) VALUES (?, ?, ?, ?)"
+
+	createdAt := time.Now()
+
+	result, err := rsr.db.ExecContext(ctx, query, repository.Id, repository.Stars, repository.Forks, createdAt.Format(time.DateTime))
+	if err != nil {
+		return fmt.Errorf("failed to exec insert repository_snapshots query to db, repository id: %d, error: %v", repository.Id, err)
+	}
+
+	_, err = result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository_snapshots insert rows affected returns error: %v", err)
+	}
+
+	return nil
+}
+
+// Returns the stars and forks history of a repository, oldest first. Pass a zero since to get the full history.
+func (rsr *RepositorySnapshotRepo) FindByRepositoryId(ctx context.Context, repositoryId int, since time.Time) ([]RepositorySnapshot, error) {
+	qb := sq.Select("id", "repository_id", "stars", "forks", "created_at").
+		From("repository_snapshots").
+		Where("repository_id = ?", repositoryId).
+		OrderBy("created_at ASC", "id ASC")
+
+	if !since.IsZero() {
+		qb = qb.Where("created_at >= ?", since.Format(time.DateTime))
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SQL when find repository snapshots, error: %v", err)
+	}
+
+	rows, err := rsr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repository snapshots, error: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "repositorySnapshotRepo.FindByRepositoryId"))
+		}
+	}()
+
+	snapshots := make([]RepositorySnapshot, 0)
+
+	for rows.Next() {
+		var snapshot RepositorySnapshot
+
+		if err := rows.Scan(&snapshot.Id, &snapshot.RepositoryId, &snapshot.Stars, &snapshot.Forks, &snapshot.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan repository_snapshots table, error: %v", err)
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repositorySnapshotRepo.FindByRepositoryId, rows error: %v", err)
+	}
+
+	return snapshots, nil
+}
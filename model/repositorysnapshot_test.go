@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHistorySince(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	since, err := ParseHistorySince("", now)
+	assert.Nil(t, err)
+	assert.True(t, since.IsZero())
+
+	since, err = ParseHistorySince("90", now)
+	assert.Nil(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -90), since)
+
+	since, err = ParseHistorySince("1000000000", now)
+	assert.Nil(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -3650), since)
+
+	for _, invalid := range []string{"0", "-30", "abc"} {
+		_, err = ParseHistorySince(invalid, now)
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,219 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const (
+	SubmissionPending  = "pending"
+	SubmissionApproved = "approved"
+	SubmissionRejected = "rejected"
+)
+
+var ErrSubmissionReviewed = errors.New("submission is already reviewed")
+
+// RepositorySubmission is a request to track a repository which has not been on a trending page, admins approve or reject it.
+type RepositorySubmission struct {
+	Id           int                `json:"id"`
+	FullName     string             `json:"full_name"`
+	Status       string             `json:"status"`
+	SubmittedBy  dbutils.NullInt64  `json:"submitted_by"` // null when submitted from the CLI.
+	ReviewedBy   dbutils.NullInt64  `json:"reviewed_by"`
+	ReviewNote   dbutils.NullString `json:"review_note"`
+	RepositoryId dbutils.NullInt64  `json:"repository_id"` // set once approved.
+	ReviewedAt   dbutils.NullTime   `json:"reviewed_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// Parse the status filter of the submissions list, empty means all statuses.
+func ParseSubmissionStatus(status string) (string, error) {
+	switch status {
+	case "", SubmissionPending, SubmissionApproved, SubmissionRejected:
+		return status, nil
+	default:
+		return "", fmt.Errorf("invalid submission status, expected: pending, approved or rejected, passed %s", status)
+	}
+}
+
+type RepositorySubmissionRepo struct {
+	db database.DB
+}
+
+func NewRepositorySubmissionRepo(db database.DB) *RepositorySubmissionRepo {
+	return &RepositorySubmissionRepo{
+		db: db,
+	}
+}
+
+func (rsr *RepositorySubmissionRepo) Save(ctx context.Context, submission RepositorySubmission) (int, error) {
+	query := "INSERT INTO `repository_submissions` (`full_name`, `status`, `submitted_by`, `created_at`, `updated_at`) VALUES (?, ?, ?, ?, ?)"
+
+	var lastInsertId int64
+
+	createdAt, updatedAt := time.Now(), time.Now()
+
+	result, err := rsr.db.ExecContext(ctx, query,
+		submission.FullName,
+		submission.Status,
+		submission.SubmittedBy,
+		createdAt.Format(time.DateTime),
+		updatedAt.Format(time.DateTime))
+
+	if err != nil {
+		return int(lastInsertId), fmt.Errorf("failed to exec insert repository_submissions query to db, error: %v", err)
+	}
+
+	lastInsertId, err = result.LastInsertId()
+	if err != nil {
+		return int(lastInsertId), fmt.Errorf("failed to get repository_submissions last insert id after insert, error: %v", err)
+	}
+
+	return int(lastInsertId), nil
+}
+
+// Save the review of a pending submission, it fails with ErrSubmissionReviewed if the submission has been reviewed meanwhile.
+func (rsr *RepositorySubmissionRepo) Review(ctx context.Context, submission RepositorySubmission) error {
+	query := "UPDATE `repository_submissions` SET `status` = ?, `reviewed_by` = ?, `review_note` = ?, `repository_id` = ?, `reviewed_at` = ?, `updated_at` = ? WHERE id = ? AND `status` = ?"
+
+	now := time.Now().Format(time.DateTime)
+
+	result, err := rsr.db.ExecContext(ctx, query,
+		submission.Status,
+		submission.ReviewedBy,
+		submission.ReviewNote,
+		submission.RepositoryId,
+		now,
+		now,
+		submission.Id,
+		SubmissionPending)
+
+	if err != nil {
+		return fmt.Errorf("failed to run repository_submissions review query, id: %d, error: %v", submission.Id, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository_submissions review rows affected returns error: %v", err)
+	}
+
+	if n != 1 {
+		return ErrSubmissionReviewed
+	}
+
+	return nil
+}
+
+func (rsr *RepositorySubmissionRepo) FindById(ctx context.Context, id int) (RepositorySubmission, error) {
+	query := "SELECT id, full_name, status, submitted_by, reviewed_by, review_note, repository_id, reviewed_at, created_at, updated_at FROM repository_submissions WHERE id = ?"
+
+	var submission RepositorySubmission
+
+	row := rsr.db.QueryRowContext(ctx, query, id)
+
+	if err := row.Scan(
+		&submission.Id,
+		&submission.FullName,
+		&submission.Status,
+		&submission.SubmittedBy,
+		&submission.ReviewedBy,
+		&submission.ReviewNote,
+		&submission.RepositoryId,
+		&submission.ReviewedAt,
+		&submission.CreatedAt,
+		&submission.UpdatedAt,
+	); err != nil {
+		return submission, err
+	}
+
+	return submission, nil
+}
+
+// Find the pending submission of a repository, it returns sql.ErrNoRows if there is none.
+func (rsr *RepositorySubmissionRepo) FindPendingByName(ctx context.Context, fullName string) (RepositorySubmission, error) {
+	query := "SELECT id, full_name, status, submitted_by, reviewed_by, review_note, repository_id, reviewed_at, created_at, updated_at FROM repository_submissions WHERE full_name = ? AND status = ? LIMIT 1"
+
+	var submission RepositorySubmission
+
+	row := rsr.db.QueryRowContext(ctx, query, fullName, SubmissionPending)
+
+	if err := row.Scan(
+		&submission.Id,
+		&submission.FullName,
+		&submission.Status,
+		&submission.SubmittedBy,
+		&submission.ReviewedBy,
+		&submission.ReviewNote,
+		&submission.RepositoryId,
+		&submission.ReviewedAt,
+		&submission.CreatedAt,
+		&submission.UpdatedAt,
+	); err != nil {
+		return submission, err
+	}
+
+	return submission, nil
+}
+
+// Find submissions newest first, an empty status returns all of them.
+func (rsr *RepositorySubmissionRepo) FindAll(ctx context.Context, status string, limit int) ([]RepositorySubmission, error) {
+	qb := dbutils.NewQueryBuilder()
+	qb.Query("SELECT id, full_name, status, submitted_by, reviewed_by, review_note, repository_id, reviewed_at, created_at, updated_at FROM repository_submissions")
+
+	if status != "" {
+		qb.Where("status = ?", status)
+	}
+
+	qb.OrderBy("created_at", "DESC")
+	qb.OrderBy("id", "DESC")
+	qb.Limit(limit)
+
+	q, args := qb.GetQuery()
+
+	rows, err := rsr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository submissions: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "repositorySubmissionRepo.FindAll"))
+		}
+	}()
+
+	submissions := make([]RepositorySubmission, 0)
+
+	for rows.Next() {
+		var submission RepositorySubmission
+
+		if err := rows.Scan(
+			&submission.Id,
+			&submission.FullName,
+			&submission.Status,
+			&submission.SubmittedBy,
+			&submission.ReviewedBy,
+			&submission.ReviewNote,
+			&submission.RepositoryId,
+			&submission.ReviewedAt,
+			&submission.CreatedAt,
+			&submission.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan repository_submissions table, error: %v", err)
+		}
+
+		submissions = append(submissions, submission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repositorySubmissionRepo.FindAll, rows error: %v", err)
+	}
+
+	return submissions, nil
+}
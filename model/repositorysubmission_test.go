@@ -0,0 +1,18 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSubmissionStatus(t *testing.T) {
+	for _, status := range []string{"", SubmissionPending, SubmissionApproved, SubmissionRejected} {
+		parsed, err := ParseSubmissionStatus(status)
+		assert.NoError(t, err)
+		assert.Equal(t, status, parsed)
+	}
+
+	_, err := ParseSubmissionStatus("deleted")
+	assert.Error(t, err)
+}
@@ -2,15 +2,18 @@ package model
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 )
 
 type DailyStat struct {
 	Count     int       `json:"count"`
 	Name      string    `json:"name"`
-	TrendDate time.Time `json:"trend_date"`
+	TrendDate time.Time `json:"trend_date"` // first day of the period when stats are bucketed by week or month.
 }
 
 type StatsRepo struct {
@@ -23,24 +26,70 @@ func NewStatsRepo(db database.DB) *StatsRepo {
 	}
 }
 
-func (sr *StatsRepo) FindTrendingTopicsStats(ctx context.Context, dataRange int) ([]DailyStat, error) {
+// Returns the column of the tag name trending slots are counted by.
+func topicsNameColumn(level int) string {
+	if level > 0 {
+		return "tag_levels.ancestor_name"
+	}
+
+	return "tags.`name`"
+}
+
+// Returns the query joining trending slots to tags, select the tag name through topicsNameColumn.
+// Tags are counted as assigned when level is 0. Otherwise each tag is rolled up to its ancestor at the given level of the
+// hierarchy, root tags being level 1, and a trending slot is counted once per ancestor.
+func topicsQuery(selects string, level int) string {
+	from := " from trending_repositories join repositories on trending_repositories.repository_id = repositories.id join repositories_tags on repositories_tags.repository_id = repositories.id"
 
-	var query string
+	if level > 0 {
+		with := "with recursive tag_levels (id, depth, ancestor_name) as (" +
+			"select id, 1, `name` from tags where parent_id is null " +
+			fmt.Sprintf("union all select tags.id, tag_levels.depth + 1, if(tag_levels.depth + 1 <= %d, tags.`name`, tag_levels.ancestor_name) from tags join tag_levels on tags.parent_id = tag_levels.id", level) +
+			") "
 
-	if dataRange > 0 {
-		since := time.Now().AddDate(0, 0, -dataRange)
-		query = "select count(*) as count, tags.`name`, trend_date from trending_repositories JOIN repositories ON trending_repositories.repository_id = repositories.id join repositories_tags on repositories_tags.repository_id = repositories.id join tags on tags.id = repositories_tags.tag_id where trend_date >'" + since.Format("2006-01-02") + "'  group by tags.`name`, trend_date order by trend_date ASC"
-	} else {
-		query = "select count(*) as count, tags.`name`, trend_date from trending_repositories JOIN repositories ON trending_repositories.repository_id = repositories.id join repositories_tags on repositories_tags.repository_id = repositories.id join tags on tags.id = repositories_tags.tag_id group by tags.`name`, trend_date order by trend_date ASC"
+		return with + "select " + selects + from + " join tag_levels on tag_levels.id = repositories_tags.tag_id"
 	}
 
-	rows, err := sr.db.QueryContext(ctx, query)
+	return "select " + selects + from + " join tags on tags.id = repositories_tags.tag_id"
+}
+
+func applyTopicFilters(qb *dbutils.QueryBuilder, params *TopicStatsParams) {
+	if params.Range > 0 {
+		since := time.Now().AddDate(0, 0, -params.Range)
+		qb.Where("trending_repositories.trend_date > ?", since.Format("2006-01-02"))
+	}
+
+	if params.Language != "" {
+		qb.Where("trending_repositories.language = ?", params.Language)
+	}
+}
+
+// Count the trending slots of each tag per day, week or month.
+func (sr *StatsRepo) FindTrendingTopicsStats(ctx context.Context, params *TopicStatsParams) ([]DailyStat, error) {
+	bucket := statsBucket(params.Interval, "trending_repositories.trend_date")
+
+	nameColumn := topicsNameColumn(params.Level)
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(topicsQuery(fmt.Sprintf("count(distinct trending_repositories.id) as count, %s as `name`, %s as period", nameColumn, bucket), params.Level))
+	applyTopicFilters(qb, params)
+	qb.GroupBy(fmt.Sprintf("%s, %s", nameColumn, bucket))
+	qb.OrderBy("period", "ASC")
+	qb.OrderBy("`name`", "ASC")
+
+	q, args := qb.GetQuery()
+
+	rows, err := sr.db.QueryContext(ctx, q, args...)
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to query trending topics stats: %v", err)
 	}
 
-	defer rows.Close()
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "statsRepo.FindTrendingTopicsStats"))
+		}
+	}()
 
 	dailyStats := make([]DailyStat, 0)
 
@@ -48,15 +97,198 @@ func (sr *StatsRepo) FindTrendingTopicsStats(ctx context.Context, dataRange int)
 		var dailyStat DailyStat
 
 		if err := rows.Scan(&dailyStat.Count, &dailyStat.Name, &dailyStat.TrendDate); err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to scan trending topics stats, error: %v", err)
 		}
 
 		dailyStats = append(dailyStats, dailyStat)
 	}
 
 	if err = rows.Err(); err != nil {
-		return dailyStats, err
+		return dailyStats, fmt.Errorf("statsRepo.FindTrendingTopicsStats, rows error: %v", err)
 	}
 
 	return dailyStats, nil
 }
+
+// Count all trending slots per day, week or month whatever the repositories are tagged with.
+func (sr *StatsRepo) findTrendingSlots(ctx context.Context, params *TopicStatsParams) (map[time.Time]int, error) {
+	bucket := statsBucket(params.Interval, "trending_repositories.trend_date")
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(fmt.Sprintf("select count(*) as total, %s as period from trending_repositories", bucket))
+	applyTopicFilters(qb, params)
+	qb.GroupBy(bucket)
+
+	q, args := qb.GetQuery()
+
+	rows, err := sr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trending slots: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "statsRepo.findTrendingSlots"))
+		}
+	}()
+
+	totals := make(map[time.Time]int)
+
+	for rows.Next() {
+		var total int
+		var period time.Time
+
+		if err := rows.Scan(&total, &period); err != nil {
+			return nil, fmt.Errorf("failed to scan trending slots, error: %v", err)
+		}
+
+		totals[period] = total
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("statsRepo.findTrendingSlots, rows error: %v", err)
+	}
+
+	return totals, nil
+}
+
+// Returns the share of trending slots taken by each tag per day, week or month.
+func (sr *StatsRepo) FindTopicShares(ctx context.Context, params *TopicStatsParams) ([]TopicShare, error) {
+	counts, err := sr.FindTrendingTopicsStats(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := sr.findTrendingSlots(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputeTopicShares(counts, totals), nil
+}
+
+// Compare the trending slots of each tag in the last range days with the range days before.
+func (sr *StatsRepo) FindTopicMomentum(ctx context.Context, params *TopicStatsParams) ([]TopicMomentum, error) {
+	if params.Range <= 0 {
+		return nil, fmt.Errorf("a range is required to compute topic momentum")
+	}
+
+	now := time.Now()
+	currentStart := now.AddDate(0, 0, -params.Range).Format("2006-01-02")
+	previousStart := now.AddDate(0, 0, -2*params.Range).Format("2006-01-02")
+
+	nameColumn := topicsNameColumn(params.Level)
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(topicsQuery(nameColumn+" as `name`, "+
+		"count(distinct case when trending_repositories.trend_date > ? then trending_repositories.id end) as current, "+
+		"count(distinct case when trending_repositories.trend_date <= ? then trending_repositories.id end) as previous", params.Level))
+	qb.Where("trending_repositories.trend_date > ?", previousStart)
+
+	if params.Language != "" {
+		qb.Where("trending_repositories.language = ?", params.Language)
+	}
+
+	qb.GroupBy(nameColumn)
+
+	q, args := qb.GetQuery()
+
+	// The select placeholders come before the where ones.
+	args = append([]any{currentStart, currentStart}, args...)
+
+	rows, err := sr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query topic momentum: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "statsRepo.FindTopicMomentum"))
+		}
+	}()
+
+	momentum := make([]TopicMomentum, 0)
+
+	for rows.Next() {
+		var m TopicMomentum
+
+		if err := rows.Scan(&m.Name, &m.Current, &m.Previous); err != nil {
+			return nil, fmt.Errorf("failed to scan topic momentum, error: %v", err)
+		}
+
+		momentum = append(momentum, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("statsRepo.FindTopicMomentum, rows error: %v", err)
+	}
+
+	momentum = ComputeTopicMomentum(momentum)
+
+	if params.Limit > 0 && len(momentum) > params.Limit {
+		momentum = momentum[:params.Limit]
+	}
+
+	return momentum, nil
+}
+
+// Returns the most featured repositories of each tag in the period.
+// Repositories are ranked within their tag in SQL so only the top ones of each tag are loaded.
+func (sr *StatsRepo) FindTopicTopRepositories(ctx context.Context, params *TopicStatsParams) ([]TopicTopRepositories, error) {
+	nameColumn := topicsNameColumn(params.Level)
+
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(topicsQuery(nameColumn+" as `name`, repositories.id, repositories.full_name, repositories.language, repositories.stars, "+
+		"count(distinct trending_repositories.id) as featured_count, min(trending_repositories.`rank`) as best_ranking, "+
+		fmt.Sprintf("row_number() over (partition by %s order by count(distinct trending_repositories.id) desc, min(trending_repositories.`rank`) asc, repositories.id asc) as row_num", nameColumn), params.Level))
+	applyTopicFilters(qb, params)
+	qb.GroupBy(fmt.Sprintf("%s, repositories.id", nameColumn))
+
+	ranked, args := qb.GetQuery()
+
+	q := "select `name`, id, full_name, language, stars, featured_count, best_ranking from (" + ranked + ") as ranked"
+
+	if params.Limit > 0 {
+		q += " where row_num <= ?"
+		args = append(args, params.Limit)
+	}
+
+	q += " order by `name` asc, row_num asc"
+
+	rows, err := sr.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query topic top repositories: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "statsRepo.FindTopicTopRepositories"))
+		}
+	}()
+
+	topicRepositories := make([]topicRepositoryRow, 0)
+
+	for rows.Next() {
+		var row topicRepositoryRow
+
+		if err := rows.Scan(
+			&row.Name,
+			&row.RepositoryId,
+			&row.FullName,
+			&row.Language,
+			&row.Stars,
+			&row.FeaturedCount,
+			&row.BestRanking,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan topic top repositories, error: %v", err)
+		}
+
+		topicRepositories = append(topicRepositories, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("statsRepo.FindTopicTopRepositories, rows error: %v", err)
+	}
+
+	return groupTopicRepositories(topicRepositories, params.Limit), nil
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/database"
+)
+
+const (
+	baseSyncRetryDelay = 1 * time.Hour
+	maxSyncRetryDelay  = 7 * 24 * time.Hour
+)
+
+// SyncFailure tracks an entity which failed to sync, it is deleted once the entity syncs again.
+type SyncFailure struct {
+	EntityType   string    `json:"entity_type"`
+	EntityId     int       `json:"entity_id"`
+	Failures     int       `json:"failures"`
+	LastError    string    `json:"last_error"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	RetryAfter   time.Time `json:"retry_after"` // the entity is left out of syncs until then.
+}
+
+// Returns when an entity which failed to sync for the given number of consecutive runs is synced again,
+// the delay doubles from an hour after each failure up to a week.
+func SyncRetryAfter(failures int, failedAt time.Time) time.Time {
+	delay := baseSyncRetryDelay
+
+	for i := 1; i < failures && delay < maxSyncRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return failedAt.Add(min(delay, maxSyncRetryDelay))
+}
+
+type SyncFailureRepo struct {
+	db database.DB
+}
+
+func NewSyncFailureRepo(db database.DB) *SyncFailureRepo {
+	return &SyncFailureRepo{
+		db: db,
+	}
+}
+
+// Record a failed sync of an entity, it increments its consecutive failures and pushes back its next sync.
+func (sfr *SyncFailureRepo) Record(ctx context.Context, entityType string, entityId int, lastError string) (SyncFailure, error) {
+	failure := SyncFailure{
+		EntityType:   entityType,
+		EntityId:     entityId,
+		LastError:    lastError,
+		LastFailedAt: time.Now(),
+	}
+
+	tx, err := sfr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return failure, fmt.Errorf("failed to begin sync failure transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	query := "SELECT failures FROM sync_failures WHERE entity_type = ? AND entity_id = ? FOR UPDATE"
+
+	if err := tx.QueryRowContext(ctx, query, entityType, entityId).Scan(&failure.Failures); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return failure, fmt.Errorf("failed to query sync failures, %s id: %d, error: %v", entityType, entityId, err)
+	}
+
+	failure.Failures++
+	failure.RetryAfter = SyncRetryAfter(failure.Failures, failure.LastFailedAt)
+
+	query = "INSERT INTO `sync_failures` (`entity_type`, `entity_id`, `failures`, `last_error`, `last_failed_at`, `retry_after`) VALUES (?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `failures` = VALUES(`failures`), `last_error` = VALUES(`last_error`), `last_failed_at` = VALUES(`last_failed_at`), `retry_after` = VALUES(`retry_after`)"
+
+	if _, err := tx.ExecContext(
+		ctx,
+		query,
+		failure.EntityType,
+		failure.EntityId,
+		failure.Failures,
+		failure.LastError,
+		failure.LastFailedAt.Format(time.DateTime),
+		failure.RetryAfter.Format(time.DateTime),
+	); err != nil {
+		return failure, fmt.Errorf("failed to run upsert sync_failures query, %s id: %d, error: %v", entityType, entityId, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return failure, fmt.Errorf("failed to commit sync failure transaction: %v", err)
+	}
+
+	return failure, nil
+}
+
+// Clear the failures of an entity after it synced successfully.
+func (sfr *SyncFailureRepo) Clear(ctx context.Context, entityType string, entityId int) error {
+	query := "DELETE FROM sync_failures WHERE entity_type = ? AND entity_id = ?"
+
+	if _, err := sfr.db.ExecContext(ctx, query, entityType, entityId); err != nil {
+		return fmt.Errorf("failed to run delete sync_failures query, %s id: %d, error: %v", entityType, entityId, err)
+	}
+
+	return nil
+}
+
+// Find the ids of the entities which are still backing off at the given time.
+func (sfr *SyncFailureRepo) FindBackingOffIds(ctx context.Context, entityType string, at time.Time) (map[int]bool, error) {
+	query := "SELECT entity_id FROM sync_failures WHERE entity_type = ? AND retry_after > ?"
+
+	rows, err := sfr.db.QueryContext(ctx, query, entityType, at.Format(time.DateTime))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backing off sync failures: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "syncFailureRepo.FindBackingOffIds"))
+		}
+	}()
+
+	ids := make(map[int]bool)
+
+	for rows.Next() {
+		var id int
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan sync_failures table, error: %v", err)
+		}
+
+		ids[id] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("syncFailureRepo.FindBackingOffIds, rows error: %v", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncRetryAfter(t *testing.T) {
+	failedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, failedAt.Add(1*time.Hour), SyncRetryAfter(1, failedAt))
+	assert.Equal(t, failedAt.Add(2*time.Hour), SyncRetryAfter(2, failedAt))
+	assert.Equal(t, failedAt.Add(8*time.Hour), SyncRetryAfter(4, failedAt))
+	assert.Equal(t, failedAt.Add(7*24*time.Hour), SyncRetryAfter(20, failedAt))
+}
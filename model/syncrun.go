@@ -0,0 +1,122 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const (
+	SyncRunRunning   = "running" // also the status of a run which was interrupted, it can be resumed.
+	SyncRunCompleted = "completed"
+	SyncRunAbandoned = "abandoned" // interrupted and superseded by a new run.
+)
+
+// SyncRun is the checkpoint of a sync, the candidates are synced in id order so a run resumes after its last id.
+type SyncRun struct {
+	Id         int64            `json:"id"`
+	Action     string           `json:"action"`
+	Status     string           `json:"status"`
+	LastId     int              `json:"last_id"` // candidates up to this id are synced.
+	StartedAt  time.Time        `json:"started_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	FinishedAt dbutils.NullTime `json:"finished_at"`
+}
+
+type SyncRunRepo struct {
+	db database.DB
+}
+
+func NewSyncRunRepo(db database.DB) *SyncRunRepo {
+	return &SyncRunRepo{
+		db: db,
+	}
+}
+
+// Start a new run of the action, the unfinished runs of the action are abandoned.
+func (srr *SyncRunRepo) Start(ctx context.Context, action string) (SyncRun, error) {
+	now := time.Now()
+
+	run := SyncRun{
+		Action:    action,
+		Status:    SyncRunRunning,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+
+	tx, err := srr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return run, fmt.Errorf("failed to begin sync run transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	query := "UPDATE sync_runs SET status = ?, updated_at = ? WHERE action = ? AND status = ?"
+
+	if _, err := tx.ExecContext(ctx, query, SyncRunAbandoned, now.Format(time.DateTime), action, SyncRunRunning); err != nil {
+		return run, fmt.Errorf("failed to run abandon sync_runs query, action: %s, error: %v", action, err)
+	}
+
+	query = "INSERT INTO `sync_runs` (`action`, `status`, `last_id`, `started_at`, `updated_at`) VALUES (?, ?, ?, ?, ?)"
+
+	result, err := tx.ExecContext(ctx, query, run.Action, run.Status, run.LastId, now.Format(time.DateTime), now.Format(time.DateTime))
+	if err != nil {
+		return run, fmt.Errorf("failed to exec insert sync_runs query to db, error: %v", err)
+	}
+
+	run.Id, err = result.LastInsertId()
+	if err != nil {
+		return run, fmt.Errorf("failed to get sync_runs last insert id: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return run, fmt.Errorf("failed to commit sync run transaction: %v", err)
+	}
+
+	return run, nil
+}
+
+// Find the latest unfinished run of the action, it returns sql.ErrNoRows when the last run completed.
+func (srr *SyncRunRepo) FindResumable(ctx context.Context, action string) (SyncRun, error) {
+	var run SyncRun
+
+	query := "SELECT id, action, status, last_id, started_at, updated_at, finished_at FROM sync_runs WHERE action = ? AND status = ? ORDER BY id DESC LIMIT 1"
+
+	err := srr.db.QueryRowContext(ctx, query, action, SyncRunRunning).Scan(
+		&run.Id,
+		&run.Action,
+		&run.Status,
+		&run.LastId,
+		&run.StartedAt,
+		&run.UpdatedAt,
+		&run.FinishedAt,
+	)
+
+	return run, err
+}
+
+// Save the last id synced by a run.
+func (srr *SyncRunRepo) Checkpoint(ctx context.Context, id int64, lastId int) error {
+	query := "UPDATE sync_runs SET last_id = ?, updated_at = ? WHERE id = ?"
+
+	if _, err := srr.db.ExecContext(ctx, query, lastId, time.Now().Format(time.DateTime), id); err != nil {
+		return fmt.Errorf("failed to run checkpoint sync_runs query, id: %d, error: %v", id, err)
+	}
+
+	return nil
+}
+
+func (srr *SyncRunRepo) Complete(ctx context.Context, id int64) error {
+	query := "UPDATE sync_runs SET status = ?, updated_at = ?, finished_at = ? WHERE id = ?"
+
+	now := time.Now().Format(time.DateTime)
+
+	if _, err := srr.db.ExecContext(ctx, query, SyncRunCompleted, now, now, id); err != nil {
+		return fmt.Errorf("failed to run complete sync_runs query, id: %d, error: %v", id, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	SyncTierHot  = "hot"
+	SyncTierWarm = "warm"
+	SyncTierCold = "cold"
+)
+
+const (
+	// Trending appearances and page views are counted over this window.
+	SyncSignalWindow = 14 * 24 * time.Hour
+
+	syncHotScore  = 30
+	syncWarmScore = 15
+
+	// Each appearance scores as much as the hot tier, appearances above it do not make an entity any hotter.
+	maxScoredTrendingDays = 5
+)
+
+var syncTierIntervals = map[string]time.Duration{
+	SyncTierHot:  1 * time.Hour,
+	SyncTierWarm: 24 * time.Hour,
+	SyncTierCold: 7 * 24 * time.Hour,
+}
+
+// SyncCandidate is a repository or developer with the signals its sync schedule is computed from.
+type SyncCandidate struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name"`
+	Stars        int       `json:"stars"` // followers of a developer.
+	TrendingDays int       `json:"trending_days"`
+	PageViews    int       `json:"page_views"`
+	LastSyncedAt time.Time `json:"last_synced_at"`
+	Score        float64   `json:"score"`
+	Tier         string    `json:"tier"`
+	DueAt        time.Time `json:"due_at"`
+}
+
+// Returns the sync priority of an entity. A single recent trending appearance makes an entity hot,
+// stars and page views are on a log scale so only large counts matter.
+func SyncPriorityScore(trendingDays, stars, pageViews int) float64 {
+	score := syncHotScore*float64(min(trendingDays, maxScoredTrendingDays)) +
+		5*math.Log10(float64(max(stars, 0))+1) +
+		10*math.Log10(float64(max(pageViews, 0))+1)
+
+	return math.Round(score*100) / 100
+}
+
+// Returns the tier of a score and how often the entities of the tier are synced.
+func SyncTier(score float64) (string, time.Duration) {
+	tier := SyncTierCold
+
+	switch {
+	case score >= syncHotScore:
+		tier = SyncTierHot
+	case score >= syncWarmScore:
+		tier = SyncTierWarm
+	}
+
+	return tier, syncTierIntervals[tier]
+}
+
+// Compute the score, tier and next sync of the candidate from its signals.
+func (c *SyncCandidate) Schedule() {
+	var interval time.Duration
+
+	c.Score = SyncPriorityScore(c.TrendingDays, c.Stars, c.PageViews)
+	c.Tier, interval = SyncTier(c.Score)
+	c.DueAt = c.LastSyncedAt.Add(interval)
+}
+
+func (c SyncCandidate) Due(now time.Time) bool {
+	return !c.DueAt.After(now)
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncPriorityScore(t *testing.T) {
+	assert.Equal(t, float64(0), SyncPriorityScore(0, 0, 0))
+	assert.Equal(t, SyncPriorityScore(5, 100, 10), SyncPriorityScore(30, 100, 10))
+	assert.Greater(t, SyncPriorityScore(1, 3, 0), SyncPriorityScore(0, 3, 0))
+	assert.Greater(t, SyncPriorityScore(0, 0, 1000), SyncPriorityScore(0, 0, 10))
+}
+
+func TestSyncTier(t *testing.T) {
+	tests := []struct {
+		name                           string
+		trendingDays, stars, pageViews int
+		tier                           string
+		interval                       time.Duration
+	}{
+		{"trending today", 1, 3, 0, SyncTierHot, time.Hour},
+		{"popular and viewed", 0, 100000, 100, SyncTierHot, time.Hour},
+		{"popular", 0, 10000, 0, SyncTierWarm, 24 * time.Hour},
+		{"trended long ago", 0, 3, 0, SyncTierCold, 7 * 24 * time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tier, interval := SyncTier(SyncPriorityScore(test.trendingDays, test.stars, test.pageViews))
+			assert.Equal(t, test.tier, tier)
+			assert.Equal(t, test.interval, interval)
+		})
+	}
+}
+
+func TestSyncCandidateSchedule(t *testing.T) {
+	lastSyncedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	candidate := SyncCandidate{TrendingDays: 2, Stars: 500, LastSyncedAt: lastSyncedAt}
+	candidate.Schedule()
+
+	assert.Equal(t, SyncTierHot, candidate.Tier)
+	assert.Equal(t, lastSyncedAt.Add(time.Hour), candidate.DueAt)
+	assert.False(t, candidate.Due(lastSyncedAt.Add(30*time.Minute)))
+	assert.True(t, candidate.Due(lastSyncedAt.Add(time.Hour)))
+}
@@ -2,15 +2,191 @@ package model
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"regexp"
 	"strings"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 )
 
+const (
+	TagSourceManual = "manual"
+	TagSourceAuto   = "auto"
+)
+
+const (
+	TagModeAnd = "and"
+	TagModeOr  = "or"
+)
+
+var ErrTagCycle = errors.New("tag can not be its own ancestor")
+var ErrInvalidTagMode = errors.New("invalid tag mode, expected: and or or")
+
 type Tag struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	Id          int                `json:"id"`
+	Name        string             `json:"name"`
+	ParentId    dbutils.NullInt64  `json:"parent_id"`
+	Description dbutils.NullString `json:"description"`
+	Color       dbutils.NullString `json:"color"` // hex color such as #3b82f6.
+	Icon        dbutils.NullString `json:"icon"`
+	Aliases     []string           `json:"aliases,omitempty"` // non db column field, aliases are saved in the tag_aliases table.
+}
+
+var tagColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func IsValidTagColor(color string) bool {
+	return tagColorRegex.MatchString(color)
+}
+
+// Check whether ancestorId is tagId itself or one of its ancestors.
+func IsTagAncestor(tags []Tag, ancestorId, tagId int) bool {
+	parents := make(map[int]int, len(tags))
+
+	for _, tag := range tags {
+		if tag.ParentId.Valid {
+			parents[tag.Id] = int(tag.ParentId.Int64)
+		}
+	}
+
+	visited := make(map[int]bool)
+
+	for id := tagId; !visited[id]; {
+		if id == ancestorId {
+			return true
+		}
+
+		visited[id] = true
+
+		parentId, ok := parents[id]
+		if !ok {
+			return false
+		}
+
+		id = parentId
+	}
+
+	return false
+}
+
+// Validate a tag filter mode, repositories match any of the tags by default.
+func ParseTagMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", TagModeOr:
+		return TagModeOr, nil
+	case TagModeAnd:
+		return TagModeAnd, nil
+	default:
+		return "", ErrInvalidTagMode
+	}
+}
+
+// Resolve each tag name or alias to the ids of the tag and all its descendants.
+// An unknown name resolves to an empty group.
+func ExpandTags(tags []Tag, names []string) [][]int {
+	byName := make(map[string]int, len(tags))
+	children := make(map[int][]int, len(tags))
+
+	for _, tag := range tags {
+		byName[strings.ToLower(tag.Name)] = tag.Id
+
+		for _, alias := range tag.Aliases {
+			byName[strings.ToLower(alias)] = tag.Id
+		}
+
+		if tag.ParentId.Valid {
+			children[int(tag.ParentId.Int64)] = append(children[int(tag.ParentId.Int64)], tag.Id)
+		}
+	}
+
+	groups := make([][]int, 0, len(names))
+
+	for _, name := range names {
+		ids := make([]int, 0)
+
+		if id, ok := byName[strings.ToLower(strings.TrimSpace(name))]; ok {
+			visited := map[int]bool{id: true}
+			ids = append(ids, id)
+
+			for i := 0; i < len(ids); i++ {
+				for _, child := range children[ids[i]] {
+					if !visited[child] {
+						visited[child] = true
+						ids = append(ids, child)
+					}
+				}
+			}
+		}
+
+		groups = append(groups, ids)
+	}
+
+	return groups
+}
+
+// Build a condition on the given repository id column from tag groups returned by ExpandTags.
+// With the and mode a repository must match every group, with the or mode any of them.
+func BuildTagCondition(column string, groups [][]int, mode string) (string, []any) {
+	subquery := func(ids []int) (string, []any) {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+		args := make([]any, 0, len(ids))
+
+		for _, id := range ids {
+			args = append(args, id)
+		}
+
+		return fmt.Sprintf("%s IN (SELECT repositories_tags.repository_id FROM repositories_tags WHERE repositories_tags.tag_id IN (%s))", column, placeholders), args
+	}
+
+	if mode == TagModeAnd {
+		conditions := make([]string, 0, len(groups))
+		args := make([]any, 0)
+
+		for _, ids := range groups {
+			if len(ids) == 0 {
+				return "1 = 0", nil
+			}
+
+			condition, groupArgs := subquery(ids)
+			conditions = append(conditions, condition)
+			args = append(args, groupArgs...)
+		}
+
+		return strings.Join(conditions, " AND "), args
+	}
+
+	ids := make([]int, 0)
+	seen := make(map[int]bool)
+
+	for _, group := range groups {
+		for _, id := range group {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return "1 = 0", nil
+	}
+
+	return subquery(ids)
+}
+
+// Load the requested tags and build the condition to filter repositories by tag names, aliases and their descendants.
+func findTagCondition(ctx context.Context, db database.DB, column string, names []string, mode string) (string, []any, error) {
+	tags, err := NewTagRepo(db).findWithDescendants(ctx, names)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load tags to filter repositories, error: %v", err)
+	}
+
+	condition, args := BuildTagCondition(column, ExpandTags(tags, names), mode)
+
+	return condition, args, nil
 }
 
 type TagRepo struct {
@@ -23,6 +199,7 @@ func NewTagRepo(db database.DB) *TagRepo {
 	}
 }
 
+// Find canonical tags whose name or one of their aliases contains the given name, an empty name returns all tags.
 func (tr *TagRepo) Find(ctx context.Context, name string) ([]Tag, error) {
 	var query string
 	args := []any{}
@@ -30,8 +207,8 @@ func (tr *TagRepo) Find(ctx context.Context, name string) ([]Tag, error) {
 	if strings.TrimSpace(name) == "" {
 		query = "SELECT * FROM tags"
 	} else {
-		query = "SELECT * FROM tags WHERE name like ? LIMIT 10"
-		args = append(args, "%"+name+"%")
+		query = "SELECT DISTINCT tags.* FROM tags LEFT JOIN tag_aliases ON tag_aliases.tag_id = tags.id WHERE tags.name like ? OR tag_aliases.name like ? LIMIT 10"
+		args = append(args, "%"+name+"%", "%"+name+"%")
 	}
 
 	rows, err := tr.db.QueryContext(ctx, query, args...)
@@ -47,7 +224,59 @@ func (tr *TagRepo) Find(ctx context.Context, name string) ([]Tag, error) {
 	for rows.Next() {
 		var tag Tag
 
-		if err := rows.Scan(&tag.Id, &tag.Name); err != nil {
+		if err := rows.Scan(&tag.Id, &tag.Name, &tag.ParentId, &tag.Description, &tag.Color, &tag.Icon); err != nil {
+			return tags, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return tags, err
+	}
+
+	return tr.loadAliases(ctx, tags)
+}
+
+// Find the tags named or aliased by one of the names and all their descendants, with their aliases.
+func (tr *TagRepo) findWithDescendants(ctx context.Context, names []string) ([]Tag, error) {
+	tags := make([]Tag, 0)
+
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+
+	// Union rather than union all, a corrupted hierarchy must not loop forever.
+	query := fmt.Sprintf("WITH RECURSIVE matched (id) AS ("+
+		"SELECT id FROM tags WHERE `name` IN (%[1]s) "+
+		"UNION SELECT tag_id FROM tag_aliases WHERE `name` IN (%[1]s) "+
+		"UNION SELECT tags.id FROM tags JOIN matched ON tags.parent_id = matched.id"+
+		") SELECT tags.id, tags.`name`, tags.parent_id, tags.description, tags.color, tags.icon FROM tags JOIN matched ON matched.id = tags.id", placeholders)
+
+	args := make([]any, 0, 2*len(names))
+	for range 2 {
+		for _, name := range names {
+			args = append(args, strings.TrimSpace(name))
+		}
+	}
+
+	rows, err := tr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "tagRepo.findWithDescendants"))
+		}
+	}()
+
+	for rows.Next() {
+		var tag Tag
+
+		if err := rows.Scan(&tag.Id, &tag.Name, &tag.ParentId, &tag.Description, &tag.Color, &tag.Icon); err != nil {
 			return tags, err
 		}
 
@@ -58,17 +287,79 @@ func (tr *TagRepo) Find(ctx context.Context, name string) ([]Tag, error) {
 		return tags, err
 	}
 
+	return tr.loadAliases(ctx, tags)
+}
+
+func (tr *TagRepo) loadAliases(ctx context.Context, tags []Tag) ([]Tag, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+
+	positions := make(map[int]int, len(tags))
+	ids := make([]int, 0, len(tags))
+
+	for i, tag := range tags {
+		positions[tag.Id] = i
+		ids = append(ids, tag.Id)
+	}
+
+	query, args, err := sq.Select("tag_id", "name").From("tag_aliases").Where(sq.Eq{"tag_id": ids}).OrderBy("name ASC").ToSql()
+	if err != nil {
+		return tags, fmt.Errorf("failed to get SQL when find tag aliases, error: %v", err)
+	}
+
+	rows, err := tr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return tags, fmt.Errorf("failed to find tag aliases, error: %v", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "tagRepo.loadAliases"))
+		}
+	}()
+
+	for rows.Next() {
+		var tagId int
+		var alias string
+
+		if err := rows.Scan(&tagId, &alias); err != nil {
+			return tags, fmt.Errorf("failed to scan tag_aliases table, error: %v", err)
+		}
+
+		tags[positions[tagId]].Aliases = append(tags[positions[tagId]].Aliases, alias)
+	}
+
+	if err = rows.Err(); err != nil {
+		return tags, fmt.Errorf("tagRepo.loadAliases, rows error: %v", err)
+	}
+
 	return tags, nil
 }
 
+func (tr *TagRepo) FindById(ctx context.Context, id int) (Tag, error) {
+	query := "SELECT * FROM tags WHERE id = ?"
+
+	var tag Tag
+
+	row := tr.db.QueryRowContext(ctx, query, id)
+
+	if err := row.Scan(&tag.Id, &tag.Name, &tag.ParentId, &tag.Description, &tag.Color, &tag.Icon); err != nil {
+		return tag, err
+	}
+
+	return tag, nil
+}
+
+// Find a tag by its lower cased name, an alias resolves to its canonical tag.
 func (tr *TagRepo) FindByName(ctx context.Context, name string) (Tag, error) {
-	query := "SELECT * FROM tags WHERE LOWER(name) = ?"
+	query := "SELECT tags.* FROM tags LEFT JOIN tag_aliases ON tag_aliases.tag_id = tags.id WHERE LOWER(tags.name) = ? OR LOWER(tag_aliases.name) = ? ORDER BY LOWER(tags.name) = ? DESC LIMIT 1"
 
 	var tag Tag
 
-	row := tr.db.QueryRowContext(ctx, query, name)
+	row := tr.db.QueryRowContext(ctx, query, name, name, name)
 
-	if err := row.Scan(&tag.Id, &tag.Name); err != nil {
+	if err := row.Scan(&tag.Id, &tag.Name, &tag.ParentId, &tag.Description, &tag.Color, &tag.Icon); err != nil {
 		return tag, err
 	}
 
@@ -76,11 +367,11 @@ func (tr *TagRepo) FindByName(ctx context.Context, name string) (Tag, error) {
 }
 
 func (tr *TagRepo) Save(ctx context.Context, tag Tag) (int, error) {
-	query := "INSERT INTO `tags` (`name`) VALUES (?)"
+	query := "INSERT INTO `tags` (`name`, `parent_id`, `description`, `color`, `icon`) VALUES (?, ?, ?, ?, ?)"
 
 	var lastInsertId int64
 
-	result, err := tr.db.ExecContext(ctx, query, tag.Name)
+	result, err := tr.db.ExecContext(ctx, query, tag.Name, tag.ParentId, tag.Description, tag.Color, tag.Icon)
 
 	if err != nil {
 		return int(lastInsertId), fmt.Errorf("failed to exec insert tags query to db, error: %v", err)
@@ -99,3 +390,107 @@ func (tr *TagRepo) Save(ctx context.Context, tag Tag) (int, error) {
 
 	return int(lastInsertId), nil
 }
+
+// Update the name and the details of a tag, the hierarchy is changed through SetParent.
+func (tr *TagRepo) Update(ctx context.Context, tag Tag) error {
+	query := "UPDATE `tags` SET `name` = ?, `description` = ?, `color` = ?, `icon` = ? WHERE id = ?"
+
+	if _, err := tr.db.ExecContext(ctx, query, tag.Name, tag.Description, tag.Color, tag.Icon, tag.Id); err != nil {
+		return fmt.Errorf("failed to run update tags query, tag id: %d, error: %v", tag.Id, err)
+	}
+
+	return nil
+}
+
+// Delete a tag, its repositories assignments and aliases are deleted by cascade and its children become root tags.
+func (tr *TagRepo) Delete(ctx context.Context, tag Tag) error {
+	query := "DELETE FROM `tags` WHERE id = ?"
+
+	if _, err := tr.db.ExecContext(ctx, query, tag.Id); err != nil {
+		return fmt.Errorf("failed to run delete tags query, tag id: %d, error: %v", tag.Id, err)
+	}
+
+	return nil
+}
+
+func (tr *TagRepo) SaveAlias(ctx context.Context, tag Tag, alias string) error {
+	query := "INSERT INTO `tag_aliases` (`tag_id`, `name`) VALUES (?, ?)"
+
+	if _, err := tr.db.ExecContext(ctx, query, tag.Id, alias); err != nil {
+		return fmt.Errorf("failed to exec insert tag_aliases query to db, tag id: %d, alias: %s, error: %v", tag.Id, alias, err)
+	}
+
+	return nil
+}
+
+// Move a tag under a new parent, pass an invalid parent id to make it a root tag.
+func (tr *TagRepo) SetParent(ctx context.Context, tag Tag, parentId dbutils.NullInt64) error {
+	if parentId.Valid {
+		tags, err := tr.Find(ctx, "")
+		if err != nil {
+			return fmt.Errorf("failed to load tags to check the hierarchy, error: %v", err)
+		}
+
+		if IsTagAncestor(tags, tag.Id, int(parentId.Int64)) {
+			return ErrTagCycle
+		}
+	}
+
+	query := "UPDATE `tags` SET `parent_id` = ? WHERE id = ?"
+
+	if _, err := tr.db.ExecContext(ctx, query, parentId, tag.Id); err != nil {
+		return fmt.Errorf("failed to run update tags parent query, tag id: %d, error: %v", tag.Id, err)
+	}
+
+	return nil
+}
+
+// Merge the source tag into the target tag. Repositories, aliases and children of the source are moved to the target,
+// the source name becomes an alias of the target and the source tag is deleted.
+func (tr *TagRepo) Merge(ctx context.Context, source, target Tag) error {
+	if source.Id == target.Id {
+		return ErrTagCycle
+	}
+
+	tags, err := tr.Find(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to load tags to check the hierarchy, error: %v", err)
+	}
+
+	// Children of the source are moved to the target, it would loop if the target is one of them.
+	if IsTagAncestor(tags, source.Id, target.Id) {
+		return ErrTagCycle
+	}
+
+	tx, err := tr.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to begin merge tags transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		// A manual assignment wins over an auto one when the repository already has the target tag.
+		{"UPDATE `repositories_tags` SET `source` = ? WHERE tag_id = ? AND repository_id IN (SELECT repository_id FROM (SELECT repository_id FROM `repositories_tags` WHERE tag_id = ? AND source = ?) AS manual)", []any{TagSourceManual, target.Id, source.Id, TagSourceManual}},
+		{"INSERT IGNORE INTO `repositories_tags` (`repository_id`, `tag_id`, `source`) SELECT `repository_id`, ?, `source` FROM `repositories_tags` WHERE tag_id = ?", []any{target.Id, source.Id}},
+		{"DELETE FROM `repositories_tags` WHERE tag_id = ?", []any{source.Id}},
+		// A rejection of the source carries over unless the repository has the target tag, rejections of the source are deleted with it.
+		{"INSERT IGNORE INTO `repository_rejected_tags` (`repository_id`, `tag_id`, `rejected_at`) SELECT `repository_id`, ?, `rejected_at` FROM `repository_rejected_tags` WHERE tag_id = ? AND repository_id NOT IN (SELECT repository_id FROM `repositories_tags` WHERE tag_id = ?)", []any{target.Id, source.Id, target.Id}},
+		{"UPDATE `tag_aliases` SET `tag_id` = ? WHERE tag_id = ?", []any{target.Id, source.Id}},
+		{"UPDATE `tags` SET `parent_id` = ? WHERE parent_id = ?", []any{target.Id, source.Id}},
+		{"DELETE FROM `tags` WHERE id = ?", []any{source.Id}},
+		{"INSERT IGNORE INTO `tag_aliases` (`tag_id`, `name`) VALUES (?, ?)", []any{target.Id, source.Name}},
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			return fmt.Errorf("failed to merge tag %d into tag %d, query: %s, error: %v", source.Id, target.Id, statement.query, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTagAncestor(t *testing.T) {
+	// Web -> Rust web frameworks -> Axum, AI is a root tag.
+	tags := []Tag{
+		{Id: 1, Name: "Web"},
+		{Id: 2, Name: "Rust web frameworks", ParentId: dbutils.NewNullInt64(1)},
+		{Id: 3, Name: "Axum", ParentId: dbutils.NewNullInt64(2)},
+		{Id: 4, Name: "AI"},
+	}
+
+	assert.True(t, IsTagAncestor(tags, 1, 3))
+	assert.True(t, IsTagAncestor(tags, 2, 3))
+	assert.True(t, IsTagAncestor(tags, 3, 3))
+	assert.False(t, IsTagAncestor(tags, 3, 1))
+	assert.False(t, IsTagAncestor(tags, 4, 3))
+
+	// A corrupted hierarchy must not loop forever.
+	cyclic := []Tag{
+		{Id: 1, Name: "a", ParentId: dbutils.NewNullInt64(2)},
+		{Id: 2, Name: "b", ParentId: dbutils.NewNullInt64(1)},
+	}
+
+	assert.False(t, IsTagAncestor(cyclic, 3, 1))
+}
+
+func TestIsValidTagColor(t *testing.T) {
+	assert.True(t, IsValidTagColor("#3b82F6"))
+	assert.False(t, IsValidTagColor("3b82f6"))
+	assert.False(t, IsValidTagColor("#fff"))
+	assert.False(t, IsValidTagColor("#3b82f6 "))
+}
+
+func TestExpandTags(t *testing.T) {
+	tags := []Tag{
+		{Id: 1, Name: "Web"},
+		{Id: 2, Name: "Rust web frameworks", ParentId: dbutils.NewNullInt64(1)},
+		{Id: 3, Name: "Axum", ParentId: dbutils.NewNullInt64(2)},
+		{Id: 4, Name: "AI", Aliases: []string{"LLM", "Large Language Model"}},
+	}
+
+	groups := ExpandTags(tags, []string{"web", "llm", "unknown", "Rust web frameworks"})
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {4}, {}, {2, 3}}, groups)
+}
+
+func TestBuildTagCondition(t *testing.T) {
+	subquery := "repositories.id IN (SELECT repositories_tags.repository_id FROM repositories_tags WHERE repositories_tags.tag_id IN (%s))"
+
+	condition, args := BuildTagCondition("repositories.id", [][]int{{1, 2}, {2, 3}}, TagModeOr)
+	assert.Equal(t, fmt.Sprintf(subquery, "?, ?, ?"), condition)
+	assert.Equal(t, []any{1, 2, 3}, args)
+
+	condition, args = BuildTagCondition("repositories.id", [][]int{{1, 2}, {3}}, TagModeAnd)
+	assert.Equal(t, fmt.Sprintf(subquery, "?, ?")+" AND "+fmt.Sprintf(subquery, "?"), condition)
+	assert.Equal(t, []any{1, 2, 3}, args)
+
+	condition, args = BuildTagCondition("repositories.id", [][]int{{1}, {}}, TagModeAnd)
+	assert.Equal(t, "1 = 0", condition)
+	assert.Empty(t, args)
+
+	condition, _ = BuildTagCondition("repositories.id", [][]int{{}}, TagModeOr)
+	assert.Equal(t, "1 = 0", condition)
+}
+
+func TestParseTagMode(t *testing.T) {
+	mode, err := ParseTagMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, TagModeOr, mode)
+
+	mode, err = ParseTagMode("AND")
+	assert.NoError(t, err)
+	assert.Equal(t, TagModeAnd, mode)
+
+	_, err = ParseTagMode("xor")
+	assert.ErrorIs(t, err, ErrInvalidTagMode)
+}
@@ -0,0 +1,181 @@
+package model
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const (
+	StatsIntervalDay   = "day"
+	StatsIntervalWeek  = "week"
+	StatsIntervalMonth = "month"
+)
+
+// Formats of the expressions returning the first day of the bucket a date column belongs to, weeks start on Monday.
+var statsIntervals = map[string]string{
+	StatsIntervalDay:   "%[1]s",
+	StatsIntervalWeek:  "DATE_SUB(%[1]s, INTERVAL WEEKDAY(%[1]s) DAY)",
+	StatsIntervalMonth: "DATE_SUB(%[1]s, INTERVAL DAYOFMONTH(%[1]s) - 1 DAY)",
+}
+
+func statsBucket(interval, column string) string {
+	return fmt.Sprintf(statsIntervals[interval], column)
+}
+
+type TopicStatsParams struct {
+	Range    int    // number of days, 0 means no date limit.
+	Interval string // day, week or month.
+	Level    int    // 0 counts tags as assigned, otherwise tags roll up to their ancestor at this level.
+	Language string // trending page language, all trending pages are counted when empty.
+	Limit    int
+}
+
+func NewTopicStatsParams(rangeStr, intervalStr, levelStr, languageStr, limitStr string) (*TopicStatsParams, error) {
+	params := &TopicStatsParams{
+		Interval: StatsIntervalDay,
+		Language: strings.TrimSpace(languageStr),
+	}
+
+	if rangeStr != "" {
+		dateRange, err := strconv.Atoi(rangeStr)
+		if err != nil || dateRange < 0 {
+			return nil, errors.New("invalid range")
+		}
+
+		params.Range = dateRange
+	}
+
+	if intervalStr != "" {
+		if _, ok := statsIntervals[intervalStr]; !ok {
+			return nil, fmt.Errorf("invalid interval, expected: day, week or month, passed %s", intervalStr)
+		}
+
+		params.Interval = intervalStr
+	}
+
+	if levelStr != "" {
+		level, err := strconv.Atoi(levelStr)
+		if err != nil || level < 0 {
+			return nil, errors.New("invalid level")
+		}
+
+		params.Level = level
+	}
+
+	if limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return nil, errors.New("invalid limit")
+		}
+
+		params.Limit = min(limit, 100)
+	}
+
+	return params, nil
+}
+
+type TopicShare struct {
+	Name   string    `json:"name"`
+	Period time.Time `json:"period"`
+	Count  int       `json:"count"`
+	Total  int       `json:"total"` // number of trending slots in the period.
+	Share  float64   `json:"share"`
+}
+
+type TopicMomentum struct {
+	Name       string   `json:"name"`
+	Current    int      `json:"current"`
+	Previous   int      `json:"previous"`
+	Change     int      `json:"change"`
+	ChangeRate *float64 `json:"change_rate"` // null when the tag did not trend in the previous period.
+}
+
+type TopicRepository struct {
+	RepositoryId  int                `json:"repository_id"`
+	FullName      string             `json:"full_name"`
+	Language      dbutils.NullString `json:"language"`
+	Stars         int                `json:"stars"`
+	FeaturedCount int                `json:"featured_count"`
+	BestRanking   int                `json:"best_ranking"`
+}
+
+type TopicTopRepositories struct {
+	Name         string            `json:"name"`
+	Repositories []TopicRepository `json:"repositories"`
+}
+
+// Compute the share of trending slots of each tag within its period.
+func ComputeTopicShares(counts []DailyStat, totals map[time.Time]int) []TopicShare {
+	shares := make([]TopicShare, 0, len(counts))
+
+	for _, count := range counts {
+		share := TopicShare{
+			Name:   count.Name,
+			Period: count.TrendDate,
+			Count:  count.Count,
+			Total:  totals[count.TrendDate],
+		}
+
+		if share.Total > 0 {
+			share.Share = float64(share.Count) / float64(share.Total)
+		}
+
+		shares = append(shares, share)
+	}
+
+	return shares
+}
+
+// Compute the period over period change of each tag, the fastest growing tags first.
+func ComputeTopicMomentum(momentum []TopicMomentum) []TopicMomentum {
+	result := make([]TopicMomentum, 0, len(momentum))
+
+	for _, m := range momentum {
+		m.Change = m.Current - m.Previous
+		m.ChangeRate = nil
+
+		if m.Previous > 0 {
+			rate := float64(m.Change) / float64(m.Previous)
+			m.ChangeRate = &rate
+		}
+
+		result = append(result, m)
+	}
+
+	slices.SortStableFunc(result, func(a, b TopicMomentum) int {
+		return cmp.Or(cmp.Compare(b.Change, a.Change), cmp.Compare(b.Current, a.Current), strings.Compare(a.Name, b.Name))
+	})
+
+	return result
+}
+
+type topicRepositoryRow struct {
+	Name string
+	TopicRepository
+}
+
+// Group rows ordered by tag then rank into the top repositories of each tag.
+func groupTopicRepositories(rows []topicRepositoryRow, limit int) []TopicTopRepositories {
+	groups := make([]TopicTopRepositories, 0)
+
+	for _, row := range rows {
+		if len(groups) == 0 || groups[len(groups)-1].Name != row.Name {
+			groups = append(groups, TopicTopRepositories{Name: row.Name, Repositories: make([]TopicRepository, 0)})
+		}
+
+		group := &groups[len(groups)-1]
+
+		if limit <= 0 || len(group.Repositories) < limit {
+			group.Repositories = append(group.Repositories, row.TopicRepository)
+		}
+	}
+
+	return groups
+}
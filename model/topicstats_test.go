@@ -0,0 +1,81 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopicStatsParams(t *testing.T) {
+	params, err := NewTopicStatsParams("", "", "", " Go ", "")
+	assert.NoError(t, err)
+	assert.Equal(t, &TopicStatsParams{Interval: StatsIntervalDay, Language: "Go"}, params)
+
+	params, err = NewTopicStatsParams("90", "week", "1", "", "500")
+	assert.NoError(t, err)
+	assert.Equal(t, &TopicStatsParams{Range: 90, Interval: StatsIntervalWeek, Level: 1, Limit: 100}, params)
+
+	invalid := [][]string{
+		{"-1", "", "", "", ""},
+		{"", "year", "", "", ""},
+		{"", "", "-1", "", ""},
+		{"", "", "", "", "0"},
+		{"abc", "", "", "", ""},
+	}
+
+	for _, args := range invalid {
+		_, err := NewTopicStatsParams(args[0], args[1], args[2], args[3], args[4])
+		assert.Error(t, err, args)
+	}
+}
+
+func TestComputeTopicShares(t *testing.T) {
+	week := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	nextWeek := week.AddDate(0, 0, 7)
+
+	counts := []DailyStat{
+		{Count: 25, Name: "AI", TrendDate: week},
+		{Count: 10, Name: "Web", TrendDate: week},
+		{Count: 5, Name: "AI", TrendDate: nextWeek},
+	}
+
+	shares := ComputeTopicShares(counts, map[time.Time]int{week: 100})
+
+	assert.Equal(t, []TopicShare{
+		{Name: "AI", Period: week, Count: 25, Total: 100, Share: 0.25},
+		{Name: "Web", Period: week, Count: 10, Total: 100, Share: 0.1},
+		{Name: "AI", Period: nextWeek, Count: 5, Total: 0, Share: 0},
+	}, shares)
+}
+
+func TestComputeTopicMomentum(t *testing.T) {
+	momentum := ComputeTopicMomentum([]TopicMomentum{
+		{Name: "Web", Current: 10, Previous: 20},
+		{Name: "AI", Current: 30, Previous: 20},
+		{Name: "Rust", Current: 10, Previous: 0},
+	})
+
+	assert.Equal(t, []string{"AI", "Rust", "Web"}, []string{momentum[0].Name, momentum[1].Name, momentum[2].Name})
+	assert.Equal(t, 10, momentum[0].Change)
+	assert.InDelta(t, 0.5, *momentum[0].ChangeRate, 0.0001)
+	assert.Nil(t, momentum[1].ChangeRate)
+	assert.Equal(t, -10, momentum[2].Change)
+	assert.InDelta(t, -0.5, *momentum[2].ChangeRate, 0.0001)
+}
+
+func TestGroupTopicRepositories(t *testing.T) {
+	rows := []topicRepositoryRow{
+		{Name: "AI", TopicRepository: TopicRepository{RepositoryId: 1}},
+		{Name: "AI", TopicRepository: TopicRepository{RepositoryId: 2}},
+		{Name: "AI", TopicRepository: TopicRepository{RepositoryId: 3}},
+		{Name: "Web", TopicRepository: TopicRepository{RepositoryId: 4}},
+	}
+
+	groups := groupTopicRepositories(rows, 2)
+
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "AI", groups[0].Name)
+	assert.Equal(t, []TopicRepository{{RepositoryId: 1}, {RepositoryId: 2}}, groups[0].Repositories)
+	assert.Equal(t, []TopicRepository{{RepositoryId: 4}}, groups[1].Repositories)
+}
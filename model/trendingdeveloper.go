@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 )
 
@@ -20,6 +21,16 @@ type TrendingDeveloper struct {
 	DeveloperId dbutils.NullInt64
 }
 
+// The columns a scrape or a link writes, compared by dry runs.
+func (t TrendingDeveloper) DryRunFields() []dryrun.Field {
+	return []dryrun.Field{
+		{Name: "username", Value: t.Username},
+		{Name: "language", Value: t.Language},
+		{Name: "rank", Value: t.Rank},
+		{Name: "developer_id", Value: t.DeveloperId},
+	}
+}
+
 type RankedTrendingDevelopers = map[int]TrendingDeveloper
 
 type TrendingDeveloperRepo struct {
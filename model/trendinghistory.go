@@ -0,0 +1,239 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const topTrendingRank = 10
+
+// TrendingEntry is one appearance on a trending page.
+type TrendingEntry struct {
+	Language  dbutils.NullString
+	TrendDate time.Time
+	Rank      int
+}
+
+type TrendingStreak struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Days      int       `json:"days"`
+}
+
+type LanguageBestRank struct {
+	Language    dbutils.NullString `json:"language"` // null for the trending page of all languages.
+	BestRank    int                `json:"best_rank"`
+	TrendDate   time.Time          `json:"trend_date"` // first date the best rank was reached.
+	Appearances int                `json:"appearances"`
+}
+
+type TrendingHistory struct {
+	FirstTrendDate dbutils.NullTime   `json:"first_trend_date"`
+	LastTrendDate  dbutils.NullTime   `json:"last_trend_date"`
+	TrendingDays   int                `json:"trending_days"`  // distinct days on any trending page.
+	DaysInTop10    int                `json:"days_in_top_10"` // distinct days ranked in the top 10 of any trending page.
+	LongestStreak  *TrendingStreak    `json:"longest_streak"`
+	Streaks        []TrendingStreak   `json:"streaks"` // consecutive trending days, oldest first.
+	BestRanks      []LanguageBestRank `json:"best_ranks"`
+}
+
+type TrendingHistoryParams struct {
+	From time.Time // inclusive, no lower bound when zero.
+	To   time.Time // inclusive, no upper bound when zero.
+}
+
+// Dates are formatted as 2006-01-02, both are optional.
+func NewTrendingHistoryParams(fromStr, toStr string) (*TrendingHistoryParams, error) {
+	params := &TrendingHistoryParams{}
+
+	if fromStr != "" {
+		from, err := time.Parse(time.DateOnly, fromStr)
+		if err != nil {
+			return nil, errors.New("invalid from date")
+		}
+
+		params.From = from
+	}
+
+	if toStr != "" {
+		to, err := time.Parse(time.DateOnly, toStr)
+		if err != nil {
+			return nil, errors.New("invalid to date")
+		}
+
+		params.To = to
+	}
+
+	if !params.From.IsZero() && !params.To.IsZero() && params.To.Before(params.From) {
+		return nil, errors.New("to date is before from date")
+	}
+
+	return params, nil
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Summarise trending appearances into streaks, first and last trend dates, best rank per language and days in the top 10.
+func SummariseTrendingHistory(entries []TrendingEntry) TrendingHistory {
+	history := TrendingHistory{
+		Streaks:   make([]TrendingStreak, 0),
+		BestRanks: make([]LanguageBestRank, 0),
+	}
+
+	days := make(map[time.Time]bool)
+	topDays := make(map[time.Time]bool)
+	bestRanks := make(map[string]*LanguageBestRank)
+
+	for _, entry := range entries {
+		day := dateOnly(entry.TrendDate)
+		days[day] = true
+
+		if entry.Rank <= topTrendingRank {
+			topDays[day] = true
+		}
+
+		key := entry.Language.String
+		if !entry.Language.Valid {
+			// Trending page of all languages, it can not clash with a language name.
+			key = "\x00"
+		}
+
+		best, ok := bestRanks[key]
+		if !ok {
+			best = &LanguageBestRank{Language: entry.Language, BestRank: entry.Rank, TrendDate: day}
+			bestRanks[key] = best
+		}
+
+		best.Appearances++
+
+		if entry.Rank < best.BestRank || (entry.Rank == best.BestRank && day.Before(best.TrendDate)) {
+			best.BestRank = entry.Rank
+			best.TrendDate = day
+		}
+	}
+
+	if len(days) == 0 {
+		return history
+	}
+
+	sortedDays := slices.SortedFunc(maps.Keys(days), time.Time.Compare)
+
+	history.FirstTrendDate = dbutils.NewNullTime(sortedDays[0])
+	history.LastTrendDate = dbutils.NewNullTime(sortedDays[len(sortedDays)-1])
+	history.TrendingDays = len(sortedDays)
+	history.DaysInTop10 = len(topDays)
+
+	for _, day := range sortedDays {
+		last := len(history.Streaks) - 1
+
+		if last >= 0 && history.Streaks[last].EndDate.AddDate(0, 0, 1).Equal(day) {
+			history.Streaks[last].EndDate = day
+			history.Streaks[last].Days++
+			continue
+		}
+
+		history.Streaks = append(history.Streaks, TrendingStreak{StartDate: day, EndDate: day, Days: 1})
+	}
+
+	for i, streak := range history.Streaks {
+		if history.LongestStreak == nil || streak.Days > history.LongestStreak.Days {
+			history.LongestStreak = &history.Streaks[i]
+		}
+	}
+
+	for _, best := range bestRanks {
+		history.BestRanks = append(history.BestRanks, *best)
+	}
+
+	// The trending page of all languages comes first, then languages by name.
+	slices.SortFunc(history.BestRanks, func(a, b LanguageBestRank) int {
+		if a.Language.Valid != b.Language.Valid {
+			if !a.Language.Valid {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(a.Language.String, b.Language.String)
+	})
+
+	return history
+}
+
+// Find the trending appearances of a repository or a developer, column is the foreign key of the trending table.
+func findTrendingEntries(ctx context.Context, db database.DB, table, column string, id int, params *TrendingHistoryParams) ([]TrendingEntry, error) {
+	qb := dbutils.NewQueryBuilder()
+	qb.Query(fmt.Sprintf("select `language`, `trend_date`, `rank` from `%s`", table))
+	qb.Where(fmt.Sprintf("`%s` = ?", column), id)
+
+	if !params.From.IsZero() {
+		qb.Where("`trend_date` >= ?", params.From.Format(time.DateOnly))
+	}
+
+	if !params.To.IsZero() {
+		qb.Where("`trend_date` <= ?", params.To.Format(time.DateOnly))
+	}
+
+	qb.OrderBy("`trend_date`", "ASC")
+
+	q, args := qb.GetQuery()
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s history: %v", table, err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows", slog.Any("error", err), slog.String("action", "findTrendingEntries"))
+		}
+	}()
+
+	entries := make([]TrendingEntry, 0)
+
+	for rows.Next() {
+		var entry TrendingEntry
+
+		if err := rows.Scan(&entry.Language, &entry.TrendDate, &entry.Rank); err != nil {
+			return nil, fmt.Errorf("failed to scan %s history, error: %v", table, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("findTrendingEntries, rows error: %v", err)
+	}
+
+	return entries, nil
+}
+
+func (tr *TrendingRepositoryRepo) FindHistoryByRepositoryId(ctx context.Context, repositoryId int, params *TrendingHistoryParams) (TrendingHistory, error) {
+	entries, err := findTrendingEntries(ctx, tr.db, "trending_repositories", "repository_id", repositoryId, params)
+	if err != nil {
+		return TrendingHistory{}, err
+	}
+
+	return SummariseTrendingHistory(entries), nil
+}
+
+func (tdr *TrendingDeveloperRepo) FindHistoryByDeveloperId(ctx context.Context, developerId int, params *TrendingHistoryParams) (TrendingHistory, error) {
+	entries, err := findTrendingEntries(ctx, tdr.db, "trending_developers", "developer_id", developerId, params)
+	if err != nil {
+		return TrendingHistory{}, err
+	}
+
+	return SummariseTrendingHistory(entries), nil
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTrendingHistoryParams(t *testing.T) {
+	params, err := NewTrendingHistoryParams("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, &TrendingHistoryParams{}, params)
+
+	params, err = NewTrendingHistoryParams("2025-01-01", "2025-06-30")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), params.From)
+	assert.Equal(t, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), params.To)
+
+	invalid := [][]string{
+		{"2025-13-01", ""},
+		{"", "yesterday"},
+		{"2025-06-30", "2025-01-01"},
+	}
+
+	for _, args := range invalid {
+		_, err := NewTrendingHistoryParams(args[0], args[1])
+		assert.Error(t, err, args)
+	}
+}
+
+func TestSummariseTrendingHistory(t *testing.T) {
+	history := SummariseTrendingHistory(nil)
+	assert.False(t, history.FirstTrendDate.Valid)
+	assert.Nil(t, history.LongestStreak)
+	assert.Empty(t, history.Streaks)
+	assert.Empty(t, history.BestRanks)
+
+	day := func(d int) time.Time {
+		return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	entries := []TrendingEntry{
+		{TrendDate: day(1), Rank: 12},
+		{TrendDate: day(2), Rank: 3},
+		{Language: dbutils.NewNullString("Go"), TrendDate: day(2), Rank: 1},
+		{TrendDate: day(3), Rank: 3},
+		{Language: dbutils.NewNullString("Go"), TrendDate: day(5), Rank: 4},
+		{Language: dbutils.NewNullString("Go"), TrendDate: day(6), Rank: 1},
+		{TrendDate: day(10), Rank: 20},
+	}
+
+	history = SummariseTrendingHistory(entries)
+
+	assert.Equal(t, dbutils.NewNullTime(day(1)), history.FirstTrendDate)
+	assert.Equal(t, dbutils.NewNullTime(day(10)), history.LastTrendDate)
+	assert.Equal(t, 6, history.TrendingDays)
+	assert.Equal(t, 4, history.DaysInTop10)
+
+	assert.Equal(t, []TrendingStreak{
+		{StartDate: day(1), EndDate: day(3), Days: 3},
+		{StartDate: day(5), EndDate: day(6), Days: 2},
+		{StartDate: day(10), EndDate: day(10), Days: 1},
+	}, history.Streaks)
+	assert.Equal(t, &TrendingStreak{StartDate: day(1), EndDate: day(3), Days: 3}, history.LongestStreak)
+
+	assert.Equal(t, []LanguageBestRank{
+		{BestRank: 3, TrendDate: day(2), Appearances: 4},
+		{Language: dbutils.NewNullString("Go"), BestRank: 1, TrendDate: day(2), Appearances: 3},
+	}, history.BestRanks)
+}
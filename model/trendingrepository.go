@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 )
 
@@ -20,6 +21,16 @@ type TrendingRepository struct {
 	RepositoryId dbutils.NullInt64
 }
 
+// The columns a scrape or a link writes, compared by dry runs.
+func (t TrendingRepository) DryRunFields() []dryrun.Field {
+	return []dryrun.Field{
+		{Name: "full_name", Value: t.RepoFullName},
+		{Name: "language", Value: t.Language},
+		{Name: "rank", Value: t.Rank},
+		{Name: "repository_id", Value: t.RepositoryId},
+	}
+}
+
 type RankedTrendingRepository = map[int]TrendingRepository
 
 type TrendingRepositoryRepo struct {
@@ -3,17 +3,50 @@ package model
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/liweiyi88/trendshift-backend/database"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Parse a comma separated list of roles, e.g. user,admin, each role must be a known one.
+func ParseRoles(roleStr string) (string, error) {
+	roles := strings.Split(roleStr, ",")
+
+	for i, role := range roles {
+		roles[i] = strings.TrimSpace(role)
+
+		if roles[i] != RoleUser && roles[i] != RoleAdmin {
+			return "", fmt.Errorf("invalid role, expected: user or admin, passed %s", role)
+		}
+	}
+
+	return strings.Join(roles, ","), nil
+}
+
+// Reports whether a comma separated list of roles includes one of the wanted roles.
+func HasRole(roleStr string, wanted ...string) bool {
+	for role := range strings.SplitSeq(roleStr, ",") {
+		if slices.Contains(wanted, strings.TrimSpace(role)) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type User struct {
 	Id        int
 	Username  string
 	Password  string
-	Role      string
+	Role      string // comma separated, e.g. user,admin
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -12,3 +12,28 @@ func TestSetPassowrdAndComparePassword(t *testing.T) {
 		t.Error("expect valid password but got invalid")
 	}
 }
+
+func TestHasRole(t *testing.T) {
+	if !HasRole("user,admin", RoleAdmin) {
+		t.Error("expect user,admin to have the admin role")
+	}
+
+	if !HasRole("admin", RoleUser, RoleAdmin) {
+		t.Error("expect admin to have one of the user and admin roles")
+	}
+
+	if HasRole("user", RoleAdmin) {
+		t.Error("expect user not to have the admin role")
+	}
+}
+
+func TestParseRoles(t *testing.T) {
+	roles, err := ParseRoles("user, admin")
+	if err != nil || roles != "user,admin" {
+		t.Errorf("expect user,admin but got %s, error: %v", roles, err)
+	}
+
+	if _, err := ParseRoles("superuser"); err == nil {
+		t.Error("expect an error for an unknown role")
+	}
+}
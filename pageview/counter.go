@@ -0,0 +1,92 @@
+package pageview
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+)
+
+const (
+	// Views waiting to be written, views beyond it are dropped rather than slowing the pages down.
+	bufferSize = 1000
+
+	// Distinct client and entity pairs remembered per day, it bounds the memory of the counter.
+	maxSeen = 500_000
+)
+
+type view struct {
+	entityType string
+	entityId   int
+}
+
+// Counter counts the page views of the repositories and developers off the request path.
+// A client counts once per entity and day, so a single client can not raise the sync priority of an entity.
+type Counter struct {
+	pvr   *model.PageViewRepo
+	views chan view
+
+	mu   sync.Mutex
+	day  string
+	seen map[string]bool
+}
+
+func NewCounter(pvr *model.PageViewRepo) *Counter {
+	return &Counter{
+		pvr:   pvr,
+		views: make(chan view, bufferSize),
+		seen:  make(map[string]bool),
+	}
+}
+
+// Count a view of the page of an entity by a client, it never blocks.
+func (c *Counter) Count(client, entityType string, entityId int) {
+	if !c.firstView(client, entityType, entityId, time.Now()) {
+		return
+	}
+
+	select {
+	case c.views <- view{entityType, entityId}:
+	default:
+		slog.Warn("page view dropped, the buffer is full", slog.String("type", entityType), slog.Int("id", entityId))
+	}
+}
+
+// Reports whether it is the first view of the entity by the client today.
+// Once too many views have been seen today, the others are not counted until tomorrow.
+func (c *Counter) firstView(client, entityType string, entityId int, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if day := now.Format(time.DateOnly); day != c.day {
+		c.day = day
+		c.seen = make(map[string]bool)
+	}
+
+	key := fmt.Sprintf("%s:%s:%d", client, entityType, entityId)
+
+	if c.seen[key] || len(c.seen) >= maxSeen {
+		return false
+	}
+
+	c.seen[key] = true
+	return true
+}
+
+// Write the counted views until the context is done, the views still buffered are dropped.
+func (c *Counter) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case v := <-c.views:
+			// Page views raise the sync priority of the entity, failing to count one is only logged.
+			if err := c.pvr.Increment(ctx, v.entityType, v.entityId); err != nil && ctx.Err() == nil {
+				slog.Error(err.Error())
+			}
+		}
+	}
+}
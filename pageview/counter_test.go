@@ -0,0 +1,24 @@
+package pageview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirstView(t *testing.T) {
+	counter := NewCounter(nil)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.True(t, counter.firstView("1.1.1.1", model.EntityRepository, 1, now))
+	assert.False(t, counter.firstView("1.1.1.1", model.EntityRepository, 1, now.Add(time.Hour)))
+	assert.True(t, counter.firstView("1.1.1.1", model.EntityDeveloper, 1, now))
+	assert.True(t, counter.firstView("1.1.1.1", model.EntityRepository, 2, now))
+	assert.True(t, counter.firstView("2.2.2.2", model.EntityRepository, 1, now))
+
+	// The views are counted again the next day.
+	assert.True(t, counter.firstView("1.1.1.1", model.EntityRepository, 1, now.AddDate(0, 0, 1)))
+	assert.False(t, counter.firstView("1.1.1.1", model.EntityRepository, 1, now.AddDate(0, 0, 1)))
+}
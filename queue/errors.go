@@ -0,0 +1,46 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+)
+
+var errPermanent = errors.New("permanent failure")
+
+type deferredError struct {
+	err error
+	at  time.Time
+}
+
+func (e *deferredError) Error() string {
+	return fmt.Sprintf("deferred until %s: %v", e.at.Format(time.DateTime), e.err)
+}
+
+func (e *deferredError) Unwrap() error {
+	return e.err
+}
+
+// Defer a job until at without counting the attempt, e.g. when all GitHub tokens are rate limited.
+func Defer(err error, at time.Time) error {
+	return &deferredError{err: err, at: at}
+}
+
+// Fail a job without retrying it, it goes straight to the dead letter.
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", errPermanent, err)
+}
+
+// Reports whether a job failed with err is moved to the dead letter instead of being retried or deferred.
+// Handlers use it to act on the final outcome of a job, e.g. to record a failure once per run.
+func Final(job model.Job, err error) bool {
+	var deferred *deferredError
+
+	if err == nil || errors.As(err, &deferred) {
+		return false
+	}
+
+	return errors.Is(err, errPermanent) || job.Attempts >= job.MaxAttempts
+}
@@ -0,0 +1,227 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/utils/datetime"
+)
+
+const (
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 1 * time.Hour
+)
+
+// Handler runs a job, a returned error fails the attempt.
+type Handler func(ctx context.Context, job model.Job) error
+
+type Options struct {
+	Workers      int
+	PollInterval time.Duration // how long an idle worker waits before looking for due jobs again.
+	LockTimeout  time.Duration // running jobs locked for longer are claimed again, their worker is assumed to be gone.
+	DrainDue     bool          // Drain returns once no job is due, jobs retried or deferred to a later time are left pending.
+}
+
+var DefaultOptions = Options{
+	Workers:      4,
+	PollInterval: 5 * time.Second,
+	LockTimeout:  15 * time.Minute,
+}
+
+// Stats counts the jobs handled by a queue.
+type Stats struct {
+	Done     int
+	Retried  int
+	Deferred int
+	Dead     int
+}
+
+// Queue runs the jobs stored in the jobs table with a bounded pool of workers.
+// Failed jobs are retried with an exponential backoff and moved to the dead letter after their max attempts.
+type Queue struct {
+	jobs     *model.JobRepo
+	handlers map[string]Handler
+	options  Options
+
+	done, retried, deferred, dead atomic.Int64
+}
+
+func New(jobs *model.JobRepo, options Options) *Queue {
+	return &Queue{
+		jobs:     jobs,
+		handlers: make(map[string]Handler),
+		options:  options,
+	}
+}
+
+// Register the handler of a kind of job, the queue only claims the kinds it has a handler for.
+func (q *Queue) Handle(kind string, handler Handler) {
+	q.handlers[kind] = handler
+}
+
+func (q *Queue) Stats() Stats {
+	return Stats{
+		Done:     int(q.done.Load()),
+		Retried:  int(q.retried.Load()),
+		Deferred: int(q.deferred.Load()),
+		Dead:     int(q.dead.Load()),
+	}
+}
+
+// Run jobs until the context is done, it is used by long running workers.
+// Database errors are logged and the workers carry on, only an invalid setup is returned.
+func (q *Queue) Work(ctx context.Context) error {
+	return q.run(ctx, false)
+}
+
+// Run jobs until no pending or running job of the handled kinds is left, retries included.
+// With the DrainDue option, it returns once no job is due and leaves the delayed ones to a later run.
+// It is used by commands which enqueue their work and exit once it is done.
+func (q *Queue) Drain(ctx context.Context) (Stats, error) {
+	err := q.run(ctx, true)
+	return q.Stats(), err
+}
+
+func (q *Queue) run(ctx context.Context, drain bool) error {
+	kinds := slices.Sorted(maps.Keys(q.handlers))
+
+	if len(kinds) == 0 {
+		return errors.New("no job handler registered")
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, max(q.options.Workers, 1))
+
+	for i := range len(errs) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			errs[i] = q.work(ctx, kinds, drain)
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (q *Queue) work(ctx context.Context, kinds []string, drain bool) error {
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		job, err := q.jobs.Claim(ctx, kinds, time.Now().Add(-q.options.LockTimeout))
+
+		if errors.Is(err, sql.ErrNoRows) {
+			if drain {
+				active, err := q.countActive(ctx, kinds)
+				if err != nil {
+					return err
+				}
+
+				if active == 0 {
+					return nil
+				}
+			}
+
+			if err := datetime.SleepWithContext(ctx, q.options.PollInterval); err != nil {
+				return nil
+			}
+
+			continue
+		}
+
+		if err == nil {
+			err = q.process(ctx, job)
+		}
+
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+
+		if drain {
+			return err
+		}
+
+		// A long running worker outlives a database outage, it waits and carries on.
+		slog.Error("job queue worker failed, waiting before the next job", slog.Any("error", err))
+
+		if err := datetime.SleepWithContext(ctx, q.options.PollInterval); err != nil {
+			return nil
+		}
+	}
+}
+
+// Count the jobs a drain waits for.
+func (q *Queue) countActive(ctx context.Context, kinds []string) (int, error) {
+	if q.options.DrainDue {
+		return q.jobs.CountDue(ctx, kinds, time.Now())
+	}
+
+	return q.jobs.CountActive(ctx, kinds)
+}
+
+// Run a claimed job and record its outcome, only failures to record the outcome are returned.
+// The outcome of a job claimed again by another worker meanwhile is left to that worker.
+func (q *Queue) process(ctx context.Context, job model.Job) error {
+	err := q.record(ctx, job, q.handlers[job.Kind](ctx, job))
+
+	if errors.Is(err, model.ErrJobLockLost) {
+		slog.Warn("job ran past its lock timeout and was claimed again, its outcome is dropped", slog.String("kind", job.Kind), slog.String("payload", job.Payload))
+		return nil
+	}
+
+	return err
+}
+
+func (q *Queue) record(ctx context.Context, job model.Job, err error) error {
+	interrupted := ctx.Err() != nil
+
+	// The outcome is recorded even if the worker is shutting down.
+	ctx = context.WithoutCancel(ctx)
+
+	var deferred *deferredError
+
+	switch {
+	case err == nil:
+		q.done.Add(1)
+		return q.jobs.Complete(ctx, job)
+	case errors.As(err, &deferred):
+		q.deferred.Add(1)
+		slog.Info("job deferred", slog.String("kind", job.Kind), slog.String("payload", job.Payload), slog.Time("run_at", deferred.at), slog.Any("error", err))
+		return q.jobs.Defer(ctx, job, deferred.at)
+	case interrupted:
+		// Interrupted by a shutdown, the job is run again by the next worker.
+		return q.jobs.Defer(ctx, job, time.Now())
+	case Final(job, err):
+		slog.Error("job failed, moved to the dead letter", slog.String("kind", job.Kind), slog.String("payload", job.Payload), slog.Int("attempts", job.Attempts), slog.Any("error", err))
+		q.dead.Add(1)
+		return q.jobs.Bury(ctx, job, err.Error())
+	default:
+		runAt := time.Now().Add(Backoff(job.Attempts))
+		slog.Warn("job failed, retrying", slog.String("kind", job.Kind), slog.String("payload", job.Payload), slog.Int("attempts", job.Attempts), slog.Time("run_at", runAt), slog.Any("error", err))
+		q.retried.Add(1)
+		return q.jobs.Retry(ctx, job, runAt, err.Error())
+	}
+}
+
+// Returns how long to wait before the next attempt, it doubles from 30 seconds after each attempt up to an hour.
+func Backoff(attempts int) time.Duration {
+	backoff := baseBackoff
+
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxBackoff)
+}
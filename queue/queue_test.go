@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(0))
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, 1*time.Minute, Backoff(2))
+	assert.Equal(t, 4*time.Minute, Backoff(4))
+	assert.Equal(t, 1*time.Hour, Backoff(10))
+	assert.Equal(t, 1*time.Hour, Backoff(100))
+}
+
+func TestErrors(t *testing.T) {
+	cause := errors.New("not found")
+
+	permanent := Permanent(cause)
+	assert.ErrorIs(t, permanent, errPermanent)
+	assert.ErrorIs(t, permanent, cause)
+
+	at := time.Now().Add(time.Hour)
+
+	var deferred *deferredError
+	assert.ErrorAs(t, Defer(cause, at), &deferred)
+	assert.Equal(t, at, deferred.at)
+	assert.ErrorIs(t, Defer(cause, at), cause)
+}
+
+func TestFinal(t *testing.T) {
+	cause := errors.New("timeout")
+	job := model.Job{Attempts: 1, MaxAttempts: 3}
+
+	assert.False(t, Final(job, nil))
+	assert.False(t, Final(job, cause))
+	assert.True(t, Final(job, Permanent(cause)))
+
+	job.Attempts = 3
+	assert.True(t, Final(job, cause))
+	assert.False(t, Final(job, Defer(cause, time.Now())))
+}
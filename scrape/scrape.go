@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"log/slog"
 
 	"github.com/liweiyi88/trendshift-backend/config"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/github"
 	"github.com/liweiyi88/trendshift-backend/global"
 	"github.com/liweiyi88/trendshift-backend/scrape/scraper"
@@ -24,6 +26,7 @@ const (
 
 type Scraper interface {
 	Scrape(ctx context.Context, language string) error
+	Preview(ctx context.Context, language string, report *dryrun.Report) ([]string, error)
 	GetType() string
 }
 
@@ -52,6 +55,38 @@ func (s *ScrapeHandler) Handle(ctx context.Context, action string) error {
 	}
 }
 
+// Scrape the trending pages and report how saving and linking them would change the DB, nothing is written.
+func (s *ScrapeHandler) DryRun(ctx context.Context, action string, report *dryrun.Report) error {
+	switch action {
+	case repository:
+		names, err := preview(scraper.NewTrendingRepositoryScraper(s.repositories.TrendingRepositoryRepo), ctx, report)
+		if err != nil {
+			return err
+		}
+
+		unlinked, err := s.repositories.TrendingRepositoryRepo.FindUnlinkedRepositories(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to query unlinked repositories: %v", err)
+		}
+
+		return s.githubFetcher.PreviewRepositoryNames(ctx, unique(append(unlinked, names...)), report)
+	case developer:
+		names, err := preview(scraper.NewTrendingDeveloperScraper(s.repositories.TrendingDeveloperRepo), ctx, report)
+		if err != nil {
+			return err
+		}
+
+		unlinked, err := s.repositories.TrendingDeveloperRepo.FindUnlinkedDevelopers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to query unlinked developers: %v", err)
+		}
+
+		return s.githubFetcher.PreviewDeveloperNames(ctx, unique(append(unlinked, names...)), report)
+	default:
+		return errors.New("invalid search action")
+	}
+}
+
 func (s *ScrapeHandler) saveTrendingRepositories(ctx context.Context) error {
 	scraper := scraper.NewTrendingRepositoryScraper(s.repositories.TrendingRepositoryRepo)
 
@@ -112,3 +147,47 @@ func save(scraper Scraper, ctx context.Context) error {
 
 	return nil
 }
+
+// Scrape repositories or developers rank from GitHub Trending page and report the changes, it returns the scraped names.
+func preview(scraper Scraper, ctx context.Context, report *dryrun.Report) ([]string, error) {
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	var mu sync.Mutex
+	names := make([]string, 0)
+
+	for _, language := range config.LanguageToScrape {
+		group.Go(func() error {
+			scraped, err := scraper.Preview(groupCtx, language, report)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			names = append(names, scraped...)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to scrape and preview trending %s: %v", scraper.GetType(), err)
+	}
+
+	return names, nil
+}
+
+// Remove the duplicated names, GitHub names are case insensitive.
+func unique(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	uniques := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			uniques = append(uniques, name)
+		}
+	}
+
+	return uniques
+}
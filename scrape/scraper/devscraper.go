@@ -2,13 +2,13 @@ package scraper
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/model"
 	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 )
@@ -90,20 +90,7 @@ func (ds *TrendingDeveloperScraper) saveDevelopers(ctx context.Context, language
 				ScrapedAt: now,
 				TrendDate: now,
 				Rank:      rank,
-			}
-
-			if language != "" {
-				trendingDeveloper.Language = dbutils.NullString{
-					NullString: sql.NullString{String: strings.ToLower(language),
-						Valid: true,
-					},
-				}
-			} else {
-				trendingDeveloper.Language = dbutils.NullString{
-					NullString: sql.NullString{
-						String: "",
-						Valid:  false,
-					}}
+				Language:  trendingLanguage(language),
 			}
 
 			err = ds.trendingDeveloperRepo.Save(ctx, trendingDeveloper)
@@ -117,6 +104,55 @@ func (ds *TrendingDeveloperScraper) saveDevelopers(ctx context.Context, language
 	return nil
 }
 
+// Report how saving the trending developers of a language would change the DB, nothing is written.
+// It returns the scraped developers so the caller can preview their linking.
+func (ds *TrendingDeveloperScraper) Preview(ctx context.Context, language string, report *dryrun.Report) ([]string, error) {
+	developers := ds.scrape(language)
+
+	if len(developers) == 0 {
+		return developers, fmt.Errorf("could not scrape any trending developer data for language: %s ", language)
+	}
+
+	rankedTrendingDevelopers, err := ds.trendingDeveloperRepo.FindRankedTrendingDevelopersByDate(ctx, time.Now(), language)
+
+	if err != nil {
+		return developers, fmt.Errorf("failed to retrieve ranked trending developers: %v", err)
+	}
+
+	for index, developer := range developers {
+		rank := index + 1
+		key := trendingRowKey(language, rank)
+
+		trendingDeveloper, ok := rankedTrendingDevelopers[rank]
+
+		if ok {
+			updated := trendingDeveloper
+			updated.Username = developer
+
+			// A different developer at the same rank is unlinked, then linked again by the link service.
+			if !strings.EqualFold(trendingDeveloper.Username, developer) {
+				updated.DeveloperId = dbutils.NullInt64{}
+			}
+
+			report.Update(ds.entity(), key, trendingDeveloper.DryRunFields(), updated.DryRunFields())
+		} else {
+			trendingDeveloper := model.TrendingDeveloper{
+				Username: developer,
+				Rank:     rank,
+				Language: trendingLanguage(language),
+			}
+
+			report.Insert(ds.entity(), key, trendingDeveloper.DryRunFields())
+		}
+	}
+
+	return developers, nil
+}
+
+func (ds *TrendingDeveloperScraper) entity() string {
+	return "trending_developer"
+}
+
 // Scrape and save trending developers to DB.
 func (ds *TrendingDeveloperScraper) Scrape(ctx context.Context, language string) error {
 	developers := ds.scrape(language)
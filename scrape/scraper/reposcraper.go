@@ -2,7 +2,6 @@ package scraper
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"log/slog"
 	"net/url"
@@ -10,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/model"
 	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 )
@@ -90,20 +90,7 @@ func (gh *TrendingRepositoryScraper) saveRepositories(ctx context.Context, langu
 				ScrapedAt:    now,
 				TrendDate:    now,
 				Rank:         rank,
-			}
-
-			if language != "" {
-				trendingRepo.Language = dbutils.NullString{
-					NullString: sql.NullString{String: strings.ToLower(language),
-						Valid: true,
-					},
-				}
-			} else {
-				trendingRepo.Language = dbutils.NullString{
-					NullString: sql.NullString{
-						String: "",
-						Valid:  false,
-					}}
+				Language:     trendingLanguage(language),
 			}
 
 			err = gh.trendRepo.Save(ctx, trendingRepo)
@@ -117,6 +104,56 @@ func (gh *TrendingRepositoryScraper) saveRepositories(ctx context.Context, langu
 	return nil
 }
 
+// Report how saving the trending repositories of a language would change the DB, nothing is written.
+// It returns the scraped repositories so the caller can preview their linking.
+func (gh *TrendingRepositoryScraper) Preview(ctx context.Context, language string, report *dryrun.Report) ([]string, error) {
+	repos := gh.scrape(language)
+
+	if len(repos) == 0 {
+		slog.Error("could not scrape any trending repository data.", slog.Any("language", language))
+		return repos, nil
+	}
+
+	rankedTrendingRepo, err := gh.trendRepo.FindRankedTrendingRepoByDate(ctx, time.Now(), language)
+
+	if err != nil {
+		return repos, fmt.Errorf("failed to retrieve ranked trending repositoris: %v", err)
+	}
+
+	for index, repo := range repos {
+		rank := index + 1
+		key := trendingRowKey(language, rank)
+
+		trendingRepo, ok := rankedTrendingRepo[rank]
+
+		if ok && trendingRepo.RepoFullName != "" {
+			updated := trendingRepo
+			updated.RepoFullName = repo
+
+			// A different repository at the same rank is unlinked, then linked again by the link service.
+			if !strings.EqualFold(trendingRepo.RepoFullName, repo) {
+				updated.RepositoryId = dbutils.NullInt64{}
+			}
+
+			report.Update(gh.entity(), key, trendingRepo.DryRunFields(), updated.DryRunFields())
+		} else {
+			trendingRepo := model.TrendingRepository{
+				RepoFullName: repo,
+				Rank:         rank,
+				Language:     trendingLanguage(language),
+			}
+
+			report.Insert(gh.entity(), key, trendingRepo.DryRunFields())
+		}
+	}
+
+	return repos, nil
+}
+
+func (gh *TrendingRepositoryScraper) entity() string {
+	return "trending_repository"
+}
+
 func (gh *TrendingRepositoryScraper) Scrape(ctx context.Context, language string) error {
 	repos := gh.scrape(language)
 
@@ -131,3 +168,21 @@ func (gh *TrendingRepositoryScraper) Scrape(ctx context.Context, language string
 func (gh *TrendingRepositoryScraper) GetType() string {
 	return "repository"
 }
+
+// Trending pages are scraped in lower case, the page of all languages has no language.
+func trendingLanguage(language string) dbutils.NullString {
+	if language == "" {
+		return dbutils.NullString{}
+	}
+
+	return dbutils.NewNullString(strings.ToLower(language))
+}
+
+// Key of a trending row in dry run reports, e.g. "go #3" or "all #1".
+func trendingRowKey(language string, rank int) string {
+	if language == "" {
+		language = "all"
+	}
+
+	return fmt.Sprintf("%s #%d", strings.ToLower(language), rank)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/liweiyi88/trendshift-backend/config"
 	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/stretchr/testify/assert"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -57,3 +58,11 @@ func TestScrape(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestTrendingRow(t *testing.T) {
+	assert.Equal(t, "all #1", trendingRowKey("", 1))
+	assert.Equal(t, "go #3", trendingRowKey("Go", 3))
+
+	assert.False(t, trendingLanguage("").Valid)
+	assert.Equal(t, "php", trendingLanguage("PHP").String)
+}
@@ -0,0 +1,47 @@
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+)
+
+// Builder precomputes the related repositories of every repository.
+type Builder struct {
+	scorer         *Scorer
+	similarityRepo *model.RepositorySimilarityRepo
+}
+
+func NewBuilder(scorer *Scorer, similarityRepo *model.RepositorySimilarityRepo) *Builder {
+	return &Builder{
+		scorer:         scorer,
+		similarityRepo: similarityRepo,
+	}
+}
+
+// Build related repositories from the current tags and topics and the trending pages since the given time,
+// then replace the previously computed ones. It returns the number of similarities saved.
+func (b *Builder) Build(ctx context.Context, since time.Time, limit int) (int, error) {
+	features, err := b.similarityRepo.FindFeatures(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	coTrending, err := b.similarityRepo.FindCoTrending(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+
+	similarities := b.scorer.Related(features, coTrending, limit)
+
+	slog.Info(fmt.Sprintf("computed %d similarities for %d repositories", len(similarities), len(features)))
+
+	if err := b.similarityRepo.ReplaceAll(ctx, similarities); err != nil {
+		return 0, err
+	}
+
+	return len(similarities), nil
+}
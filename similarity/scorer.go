@@ -0,0 +1,212 @@
+package similarity
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+)
+
+// Weights of each signal in a similarity score, they add up to 1 so scores range from 0 to 1.
+type Weights struct {
+	Tags       float64
+	Topics     float64
+	Language   float64
+	CoTrending float64
+}
+
+var DefaultWeights = Weights{
+	Tags:       0.35,
+	Topics:     0.35,
+	Language:   0.1,
+	CoTrending: 0.2,
+}
+
+// Number of shared trending pages from which co-trending counts fully.
+const coTrendingSaturation = 5
+
+// Tags and topics shared by more repositories than this, e.g. python, are too broad to make repositories candidates
+// of each other, every pair of their repositories would be scored. They still count in the score of other candidates.
+const maxGroupSize = 100
+
+// Scorer scores how similar repositories are from their shared tags, GitHub topics, language and co-trending.
+type Scorer struct {
+	weights      Weights
+	maxGroupSize int
+}
+
+func NewScorer(weights Weights) *Scorer {
+	return &Scorer{weights: weights, maxGroupSize: maxGroupSize}
+}
+
+// Score two repositories, tags and topics are compared with the Jaccard index.
+func (s *Scorer) Score(repositoryId, relatedRepositoryId int, a, b *model.RepositoryFeatures, coTrending int) model.RepositorySimilarity {
+	return s.score(repositoryId, relatedRepositoryId, normaliseFeatures(a), normaliseFeatures(b), coTrending)
+}
+
+func (s *Scorer) score(repositoryId, relatedRepositoryId int, a, b *model.RepositoryFeatures, coTrending int) model.RepositorySimilarity {
+	sharedTags, tagsIndex := jaccard(a.Tags, b.Tags)
+	sharedTopics, topicsIndex := jaccard(a.Topics, b.Topics)
+	sameLanguage := a.Language != "" && strings.EqualFold(a.Language, b.Language)
+
+	score := s.weights.Tags*tagsIndex + s.weights.Topics*topicsIndex + s.weights.CoTrending*float64(min(coTrending, coTrendingSaturation))/coTrendingSaturation
+
+	if sameLanguage {
+		score += s.weights.Language
+	}
+
+	return model.RepositorySimilarity{
+		RepositoryId:        repositoryId,
+		RelatedRepositoryId: relatedRepositoryId,
+		Score:               score,
+		SharedTags:          sharedTags,
+		SharedTopics:        sharedTopics,
+		SameLanguage:        sameLanguage,
+		CoTrending:          coTrending,
+	}
+}
+
+// Returns the limit most similar repositories of every repository.
+// Only repositories sharing a tag, a topic or a trending page are candidates, a shared language alone is not enough.
+// Candidates are collected and scored one repository at a time so memory stays bounded by the largest candidate set.
+func (s *Scorer) Related(features map[int]*model.RepositoryFeatures, coTrending map[model.RepositoryPair]int, limit int) []model.RepositorySimilarity {
+	normalised := make(map[int]*model.RepositoryFeatures, len(features))
+	byTag := make(map[int][]int)
+	byTopic := make(map[string][]int)
+
+	for id, f := range features {
+		n := normaliseFeatures(f)
+		normalised[id] = n
+
+		for _, tag := range n.Tags {
+			byTag[tag] = append(byTag[tag], id)
+		}
+
+		for _, topic := range n.Topics {
+			byTopic[topic] = append(byTopic[topic], id)
+		}
+	}
+
+	coTrendingWith := make(map[int][]int)
+
+	for pair := range coTrending {
+		if features[pair.First] != nil && features[pair.Second] != nil {
+			coTrendingWith[pair.First] = append(coTrendingWith[pair.First], pair.Second)
+			coTrendingWith[pair.Second] = append(coTrendingWith[pair.Second], pair.First)
+		}
+	}
+
+	similarities := make([]model.RepositorySimilarity, 0)
+
+	for _, id := range slices.Sorted(maps.Keys(normalised)) {
+		candidates := make(map[int]bool)
+
+		addCandidates := func(ids []int) {
+			for _, relatedId := range ids {
+				if relatedId != id {
+					candidates[relatedId] = true
+				}
+			}
+		}
+
+		for _, tag := range normalised[id].Tags {
+			if len(byTag[tag]) <= s.maxGroupSize {
+				addCandidates(byTag[tag])
+			}
+		}
+
+		for _, topic := range normalised[id].Topics {
+			if len(byTopic[topic]) <= s.maxGroupSize {
+				addCandidates(byTopic[topic])
+			}
+		}
+
+		addCandidates(coTrendingWith[id])
+
+		top := make([]model.RepositorySimilarity, 0, min(len(candidates), max(limit, 0)))
+
+		for relatedId := range candidates {
+			similarity := s.score(id, relatedId, normalised[id], normalised[relatedId], coTrending[model.NewRepositoryPair(id, relatedId)])
+			top = keepTop(top, similarity, limit)
+		}
+
+		similarities = append(similarities, top...)
+	}
+
+	return similarities
+}
+
+func compareSimilarities(a, b model.RepositorySimilarity) int {
+	return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.RelatedRepositoryId, b.RelatedRepositoryId))
+}
+
+// Insert a similarity into the top similarities sorted best first, at most limit of them are kept when limit is positive.
+func keepTop(top []model.RepositorySimilarity, similarity model.RepositorySimilarity, limit int) []model.RepositorySimilarity {
+	if limit > 0 && len(top) == limit && compareSimilarities(similarity, top[len(top)-1]) >= 0 {
+		return top
+	}
+
+	i, _ := slices.BinarySearchFunc(top, similarity, compareSimilarities)
+	top = slices.Insert(top, i, similarity)
+
+	if limit > 0 && len(top) > limit {
+		top = top[:limit]
+	}
+
+	return top
+}
+
+// Returns the features with deduplicated tags and normalised topics, as compared by the scorer.
+func normaliseFeatures(f *model.RepositoryFeatures) *model.RepositoryFeatures {
+	return &model.RepositoryFeatures{
+		Language: f.Language,
+		Tags:     slices.Compact(slices.Sorted(slices.Values(f.Tags))),
+		Topics:   normaliseTopics(f.Topics),
+	}
+}
+
+func normaliseTopics(topics []string) []string {
+	normalised := make([]string, 0, len(topics))
+
+	for _, topic := range topics {
+		normalised = append(normalised, strings.ToLower(strings.TrimSpace(topic)))
+	}
+
+	slices.Sort(normalised)
+
+	return slices.Compact(normalised)
+}
+
+// Returns the number of shared items and the Jaccard index of two sets.
+func jaccard[T comparable](a, b []T) (int, float64) {
+	set := make(map[T]bool, len(a))
+	for _, item := range a {
+		set[item] = true
+	}
+
+	shared := 0
+	union := len(set)
+
+	seen := make(map[T]bool, len(b))
+	for _, item := range b {
+		if seen[item] {
+			continue
+		}
+
+		seen[item] = true
+
+		if set[item] {
+			shared++
+		} else {
+			union++
+		}
+	}
+
+	if union == 0 {
+		return 0, 0
+	}
+
+	return shared, float64(shared) / float64(union)
+}
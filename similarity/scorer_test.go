@@ -0,0 +1,102 @@
+package similarity
+
+import (
+	"testing"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	scorer := NewScorer(DefaultWeights)
+
+	a := &model.RepositoryFeatures{Language: "Go", Tags: []int{1, 2}, Topics: []string{"ORM", "database"}}
+	b := &model.RepositoryFeatures{Language: "go", Tags: []int{2, 3}, Topics: []string{"orm", "sql", "database"}}
+
+	similarity := scorer.Score(1, 2, a, b, 10)
+
+	assert.Equal(t, 1, similarity.RepositoryId)
+	assert.Equal(t, 2, similarity.RelatedRepositoryId)
+	assert.Equal(t, 1, similarity.SharedTags)
+	assert.Equal(t, 2, similarity.SharedTopics)
+	assert.True(t, similarity.SameLanguage)
+	assert.Equal(t, 10, similarity.CoTrending)
+	assert.InDelta(t, 0.35*1/3+0.35*2/3+0.1+0.2, similarity.Score, 1e-9)
+
+	similarity = scorer.Score(1, 2, &model.RepositoryFeatures{}, &model.RepositoryFeatures{}, 0)
+	assert.False(t, similarity.SameLanguage)
+	assert.Equal(t, 0.0, similarity.Score)
+}
+
+func TestRelated(t *testing.T) {
+	scorer := NewScorer(DefaultWeights)
+
+	features := map[int]*model.RepositoryFeatures{
+		1: {Language: "Go", Tags: []int{1}, Topics: []string{"orm"}},
+		2: {Language: "Go", Tags: []int{1}, Topics: []string{"orm"}},
+		3: {Language: "Go", Tags: []int{1}},
+		4: {Language: "Go"},
+		5: {Language: "Rust"},
+	}
+
+	coTrending := map[model.RepositoryPair]int{
+		model.NewRepositoryPair(5, 1): 5,
+		model.NewRepositoryPair(6, 1): 5, // unknown repository is ignored.
+	}
+
+	similarities := scorer.Related(features, coTrending, 2)
+
+	related := make(map[int][]int)
+	for _, similarity := range similarities {
+		related[similarity.RepositoryId] = append(related[similarity.RepositoryId], similarity.RelatedRepositoryId)
+	}
+
+	assert.Equal(t, map[int][]int{
+		1: {2, 3},
+		2: {1, 3},
+		3: {1, 2},
+		5: {1},
+	}, related)
+}
+
+func TestRelatedSkipsBroadGroups(t *testing.T) {
+	scorer := NewScorer(DefaultWeights)
+	scorer.maxGroupSize = 3
+
+	features := map[int]*model.RepositoryFeatures{
+		1: {Topics: []string{"python", "orm"}},
+		2: {Topics: []string{"python", "orm"}},
+		3: {Topics: []string{"python"}},
+		4: {Topics: []string{"Python"}},
+	}
+
+	similarities := scorer.Related(features, map[model.RepositoryPair]int{}, 0)
+
+	related := make(map[int][]int)
+	for _, similarity := range similarities {
+		related[similarity.RepositoryId] = append(related[similarity.RepositoryId], similarity.RelatedRepositoryId)
+	}
+
+	// python is shared by 4 repositories, only orm makes candidates, python still counts in their score.
+	assert.Equal(t, map[int][]int{1: {2}, 2: {1}}, related)
+	assert.Equal(t, 2, similarities[0].SharedTopics)
+}
+
+func TestKeepTop(t *testing.T) {
+	top := make([]model.RepositorySimilarity, 0)
+
+	for id, score := range []float64{0.1, 0.5, 0.3, 0.5, 0.9} {
+		top = keepTop(top, model.RepositorySimilarity{RelatedRepositoryId: id, Score: score}, 3)
+	}
+
+	ids := make([]int, 0, len(top))
+	for _, similarity := range top {
+		ids = append(ids, similarity.RelatedRepositoryId)
+	}
+
+	assert.Equal(t, []int{4, 1, 3}, ids)
+}
+
+func TestNewRepositoryPair(t *testing.T) {
+	assert.Equal(t, model.NewRepositoryPair(1, 2), model.NewRepositoryPair(2, 1))
+}
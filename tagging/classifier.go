@@ -0,0 +1,140 @@
+package tagging
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Rule maps GitHub topics and description keywords to one of our curated tags.
+type Rule struct {
+	Tag      string
+	Topics   []string
+	Keywords []string
+}
+
+// DefaultRules is the rule set used during sync, tags which are not curated in the tags table are ignored.
+// Descriptions mention common words in passing, e.g. "a CLI for ..." or "security fixes", so keywords are phrases
+// or terms with a single meaning and the broad words are left to the topics.
+var DefaultRules = []Rule{
+	{
+		Tag:      "AI",
+		Topics:   []string{"ai", "artificial-intelligence", "llm", "llms", "rag", "gpt", "chatgpt", "openai", "langchain", "agent", "ai-agents", "generative-ai", "genai", "large-language-models", "stable-diffusion", "diffusion-models", "mcp", "machine-learning", "deep-learning"},
+		Keywords: []string{"llm", "llms", "large language model", "large language models", "artificial intelligence", "generative ai", "ai agent", "ai agents", "chatgpt", "model context protocol", "machine learning", "deep learning"},
+	},
+	{
+		Tag:      "Database",
+		Topics:   []string{"database", "sql", "nosql", "postgres", "postgresql", "mysql", "sqlite", "redis", "vector-database", "key-value"},
+		Keywords: []string{"database engine", "database management system", "vector database", "key value store", "sql engine"},
+	},
+	{
+		Tag:      "DevOps",
+		Topics:   []string{"devops", "kubernetes", "k8s", "docker", "ci-cd", "terraform", "infrastructure-as-code", "observability", "monitoring", "helm"},
+		Keywords: []string{"kubernetes", "devops", "infrastructure as code", "ci cd", "observability"},
+	},
+	{
+		Tag:      "Security",
+		Topics:   []string{"security", "cybersecurity", "pentest", "penetration-testing", "vulnerability", "infosec", "red-team", "malware"},
+		Keywords: []string{"pentest", "penetration testing", "vulnerability scanner", "security scanner", "exploit framework"},
+	},
+	{
+		Tag:      "Web",
+		Topics:   []string{"web", "react", "vue", "nextjs", "svelte", "frontend", "web-framework", "tailwindcss", "angular"},
+		Keywords: []string{"web framework", "frontend framework", "react framework", "nextjs"},
+	},
+	{
+		Tag:      "Mobile",
+		Topics:   []string{"android", "ios", "flutter", "react-native", "mobile", "swiftui"},
+		Keywords: []string{"android app", "ios app", "mobile app", "flutter app"},
+	},
+	{
+		Tag:      "CLI",
+		Topics:   []string{"cli", "command-line", "terminal", "tui", "shell"},
+		Keywords: []string{"command line tool", "command line interface", "terminal ui", "tui"},
+	},
+	{
+		Tag:      "Game",
+		Topics:   []string{"game", "game-engine", "gamedev", "game-development", "godot", "unity"},
+		Keywords: []string{"game engine", "video game", "game development"},
+	},
+}
+
+type Classifier struct {
+	rules []Rule
+}
+
+// Create a classifier, topics and keywords are normalised so rules can be written in any case.
+func NewClassifier(rules []Rule) *Classifier {
+	normalised := make([]Rule, 0, len(rules))
+
+	for _, rule := range rules {
+		r := Rule{Tag: rule.Tag}
+
+		for _, topic := range rule.Topics {
+			r.Topics = append(r.Topics, strings.ToLower(strings.TrimSpace(topic)))
+		}
+
+		for _, keyword := range rule.Keywords {
+			if keyword := normalise(keyword); strings.TrimSpace(keyword) != "" {
+				r.Keywords = append(r.Keywords, keyword)
+			}
+		}
+
+		normalised = append(normalised, r)
+	}
+
+	return &Classifier{normalised}
+}
+
+// Returns the names of the tags matching the topics or the description, in the order of the rules.
+func (c *Classifier) Classify(topics []string, description string) []string {
+	repositoryTopics := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		repositoryTopics = append(repositoryTopics, strings.ToLower(strings.TrimSpace(topic)))
+	}
+
+	text := normalise(description)
+	tags := make([]string, 0)
+
+	for _, rule := range c.rules {
+		if slices.Contains(tags, rule.Tag) {
+			continue
+		}
+
+		if c.matchTopics(rule, repositoryTopics) || c.matchKeywords(rule, text) {
+			tags = append(tags, rule.Tag)
+		}
+	}
+
+	return tags
+}
+
+func (c *Classifier) matchTopics(rule Rule, topics []string) bool {
+	for _, topic := range rule.Topics {
+		if slices.Contains(topics, topic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Classifier) matchKeywords(rule Rule, text string) bool {
+	for _, keyword := range rule.Keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lower case the text and keep only words separated by single spaces, with a leading and trailing space
+// so keywords only match whole words, e.g. "rag" does not match "storage".
+func normalise(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return " " + strings.Join(words, " ") + " "
+}
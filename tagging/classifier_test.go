@@ -0,0 +1,37 @@
+package tagging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	classifier := NewClassifier([]Rule{
+		{Tag: "AI", Topics: []string{"LLM", "rag"}, Keywords: []string{"large language model"}},
+		{Tag: "Database", Topics: []string{"database"}, Keywords: []string{"database"}},
+		{Tag: "CLI", Keywords: []string{"cli"}},
+		{Tag: "Game", Keywords: []string{"", " - "}},
+	})
+
+	tests := []struct {
+		name        string
+		topics      []string
+		description string
+		want        []string
+	}{
+		{"topic match is case insensitive", []string{"llm"}, "", []string{"AI"}},
+		{"keyword phrase", nil, "Run a Large-Language Model locally", []string{"AI"}},
+		{"keyword must be a whole word", []string{"storage"}, "A fast storage engine for clients", []string{}},
+		{"several tags keep rule order", []string{"database", "rag"}, "a cli", []string{"AI", "Database", "CLI"}},
+		{"tag is not duplicated", []string{"rag"}, "large language model", []string{"AI"}},
+		{"no match", []string{"go"}, "", []string{}},
+		{"empty keyword matches nothing", nil, "", []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, classifier.Classify(test.topics, test.description))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/liweiyi88/trendshift-backend/model"
+)
+
+// Tagger assigns curated tags to repositories based on their GitHub topics and description.
+type Tagger struct {
+	classifier     *Classifier
+	tagRepo        *model.TagRepo
+	repositoryRepo *model.GhRepositoryRepo
+
+	mu       sync.Mutex
+	tags     map[string]model.Tag
+	loadedAt time.Time
+}
+
+// Curated tags are reloaded after this long so a long running worker sees tags created, renamed or merged since.
+const tagsTTL = 5 * time.Minute
+
+func NewTagger(classifier *Classifier, tagRepo *model.TagRepo, repositoryRepo *model.GhRepositoryRepo) *Tagger {
+	return &Tagger{
+		classifier:     classifier,
+		tagRepo:        tagRepo,
+		repositoryRepo: repositoryRepo,
+	}
+}
+
+// Curated tags are cached for tagsTTL, a failed load is not cached so the next repository tries again.
+func (t *Tagger) loadTags(ctx context.Context) (map[string]model.Tag, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tags != nil && time.Since(t.loadedAt) < tagsTTL {
+		return t.tags, nil
+	}
+
+	tags, err := t.tagRepo.Find(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tags: %v", err)
+	}
+
+	curated := make(map[string]model.Tag, len(tags))
+	for _, tag := range tags {
+		curated[strings.ToLower(tag.Name)] = tag
+
+		for _, alias := range tag.Aliases {
+			curated[strings.ToLower(alias)] = tag
+		}
+	}
+
+	t.tags, t.loadedAt = curated, time.Now()
+
+	return t.tags, nil
+}
+
+// Classify a repository and save the matching tags as auto assigned tags.
+func (t *Tagger) Tag(ctx context.Context, repository model.GhRepository) error {
+	curated, err := t.loadTags(ctx)
+	if err != nil {
+		return err
+	}
+
+	tags := make([]model.Tag, 0)
+	assigned := make(map[int]bool)
+
+	for _, name := range t.classifier.Classify(repository.Topics, repository.Description.String) {
+		// Several rules can resolve to the same canonical tag through aliases.
+		if tag, ok := curated[strings.ToLower(name)]; ok && !assigned[tag.Id] {
+			assigned[tag.Id] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return t.repositoryRepo.SaveAutoTags(ctx, repository, tags)
+}
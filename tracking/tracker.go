@@ -0,0 +1,173 @@
+package tracking
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/liweiyi88/trendshift-backend/github"
+	"github.com/liweiyi88/trendshift-backend/global"
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/trending"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+var (
+	ErrAlreadyTracked   = errors.New("repository is already tracked")
+	ErrAlreadySubmitted = errors.New("repository is already submitted")
+	ErrNotOnGitHub      = errors.New("repository does not exist on GitHub")
+)
+
+// Tracker handles submissions of repositories which have never been on a trending page.
+// A submission is validated against GitHub, the repository is only tracked once an admin approves it.
+type Tracker struct {
+	gh           *github.Client
+	fetcher      *trending.GithubFetcher
+	repositories global.Repositories
+}
+
+func NewTracker(gh *github.Client, fetcher *trending.GithubFetcher, repositories global.Repositories) *Tracker {
+	return &Tracker{
+		gh, fetcher, repositories,
+	}
+}
+
+// Submit a repository by its full name, submittedBy is the id of the user or null from the CLI.
+func (t *Tracker) Submit(ctx context.Context, fullName string, submittedBy dbutils.NullInt64) (model.RepositorySubmission, error) {
+	grr, rsr := t.repositories.GhRepositoryRepo, t.repositories.RepositorySubmissionRepo
+
+	var submission model.RepositorySubmission
+
+	repository, err := t.gh.GetRepository(ctx, fullName)
+	if errors.Is(err, github.ErrNotFound) {
+		return submission, ErrNotOnGitHub
+	}
+
+	if err != nil {
+		return submission, fmt.Errorf("failed to validate repository %s on GitHub: %v", fullName, err)
+	}
+
+	// GitHub returns the canonical name, which differs when the repository was renamed or transferred.
+	fullName = repository.FullName
+
+	if _, err := grr.FindByName(ctx, fullName); err == nil {
+		return submission, ErrAlreadyTracked
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return submission, fmt.Errorf("failed to find repository by name: %v", err)
+	}
+
+	if _, err := rsr.FindPendingByName(ctx, fullName); err == nil {
+		return submission, ErrAlreadySubmitted
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return submission, fmt.Errorf("failed to find pending submission: %v", err)
+	}
+
+	submission.FullName = fullName
+	submission.Status = model.SubmissionPending
+	submission.SubmittedBy = submittedBy
+
+	id, err := rsr.Save(ctx, submission)
+	if err != nil {
+		return submission, err
+	}
+
+	return rsr.FindById(ctx, id)
+}
+
+// Approve a pending submission: the repository is saved, linked to its trending appearances, indexed in search
+// and its monthly insights are queued for the monthly ingestion.
+func (t *Tracker) Approve(ctx context.Context, id int, reviewedBy dbutils.NullInt64) (model.RepositorySubmission, error) {
+	rsr := t.repositories.RepositorySubmissionRepo
+
+	submission, err := t.findPending(ctx, id)
+	if err != nil {
+		return submission, err
+	}
+
+	repository, err := t.track(ctx, submission.FullName)
+	if err != nil {
+		return submission, err
+	}
+
+	submission.Status = model.SubmissionApproved
+	submission.ReviewedBy = reviewedBy
+	submission.RepositoryId = dbutils.NewNullInt64(repository.Id)
+
+	if err := rsr.Review(ctx, submission); err != nil {
+		return submission, err
+	}
+
+	return rsr.FindById(ctx, id)
+}
+
+func (t *Tracker) Reject(ctx context.Context, id int, reviewedBy dbutils.NullInt64, note string) (model.RepositorySubmission, error) {
+	rsr := t.repositories.RepositorySubmissionRepo
+
+	submission, err := t.findPending(ctx, id)
+	if err != nil {
+		return submission, err
+	}
+
+	submission.Status = model.SubmissionRejected
+	submission.ReviewedBy = reviewedBy
+
+	if note = strings.TrimSpace(note); note != "" {
+		submission.ReviewNote = dbutils.NewNullString(note)
+	}
+
+	if err := rsr.Review(ctx, submission); err != nil {
+		return submission, err
+	}
+
+	return rsr.FindById(ctx, id)
+}
+
+// Submit and approve a repository at once, it is used by operators from the CLI.
+func (t *Tracker) Track(ctx context.Context, fullName string) (model.RepositorySubmission, error) {
+	submission, err := t.Submit(ctx, fullName, dbutils.NullInt64{})
+	if err != nil {
+		return submission, err
+	}
+
+	return t.Approve(ctx, submission.Id, dbutils.NullInt64{})
+}
+
+func (t *Tracker) findPending(ctx context.Context, id int) (model.RepositorySubmission, error) {
+	submission, err := t.repositories.RepositorySubmissionRepo.FindById(ctx, id)
+	if err != nil {
+		return submission, err
+	}
+
+	if submission.Status != model.SubmissionPending {
+		return submission, model.ErrSubmissionReviewed
+	}
+
+	return submission, nil
+}
+
+func (t *Tracker) track(ctx context.Context, fullName string) (model.GhRepository, error) {
+	grr, rmr := t.repositories.GhRepositoryRepo, t.repositories.RepositoryMonthlyInsightRepo
+
+	// The repository may have been on a trending page since it was submitted.
+	repository, err := grr.FindByName(ctx, fullName)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		repository, err = t.fetcher.FetchRepository(ctx, fullName)
+
+		if errors.Is(err, github.ErrNotFound) {
+			return repository, ErrNotOnGitHub
+		}
+	}
+
+	if err != nil {
+		return repository, fmt.Errorf("failed to track repository %s: %v", fullName, err)
+	}
+
+	if err := rmr.CreateBackfill(ctx, repository.Id, model.MonthlyInsightBackfillMonths); err != nil {
+		return repository, err
+	}
+
+	return repository, nil
+}
@@ -2,16 +2,28 @@ package trending
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 
+	"github.com/liweiyi88/trendshift-backend/dryrun"
 	"github.com/liweiyi88/trendshift-backend/github"
 	"github.com/liweiyi88/trendshift-backend/global"
 	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/queue"
 	"github.com/liweiyi88/trendshift-backend/search"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
 	"golang.org/x/sync/errgroup"
 )
 
+// Entities of the trending rows in dry run reports.
+const (
+	trendingRepositoryEntity = "trending_repository"
+	trendingDeveloperEntity  = "trending_developer"
+)
+
 type GithubFetcher struct {
 	gh           *github.Client
 	search       search.Search
@@ -24,86 +36,105 @@ func NewGithubFetcher(gh *github.Client, search search.Search, repositories glob
 	}
 }
 
-func (fetcher *GithubFetcher) FetchDevelopers(ctx context.Context) error {
-	tdr, dr := fetcher.repositories.TrendingDeveloperRepo, fetcher.repositories.DeveloperRepo
-
-	unlinkedDevelopers, err := tdr.FindUnlinkedDevelopers(ctx)
-
-	if err != nil {
-		return fmt.Errorf("failed to query unlinked developers: %v", err)
-	}
-
-	developers, err := dr.FindDevelopersByUsernames(ctx, unlinkedDevelopers)
+// Split developer usernames into the developers already saved and the usernames which are not.
+func (fetcher *GithubFetcher) partitionDevelopers(ctx context.Context, usernames []string) ([]model.Developer, []string, error) {
+	developers, err := fetcher.repositories.DeveloperRepo.FindDevelopersByUsernames(ctx, usernames)
 
 	if err != nil {
-		return fmt.Errorf("failed to query developers by names: %v", err)
+		return nil, nil, fmt.Errorf("failed to query developers by names: %v", err)
 	}
 
+	existing := make([]model.Developer, 0, len(developers))
 	devNamesNotExist := make([]string, 0)
 
-	// if developer exist in DB, then we update the relationship
-	for _, unlinkedDeveloper := range unlinkedDevelopers {
+	for _, username := range usernames {
 		exist := false
 
 		for _, developer := range developers {
-			if strings.EqualFold(developer.Username, unlinkedDeveloper) {
+			if strings.EqualFold(developer.Username, username) {
 				exist = true
-
-				err := tdr.LinkDeveloper(ctx, developer)
-
-				if err != nil {
-					return err
-				}
+				existing = append(existing, developer)
 			}
 		}
 
 		if !exist {
-			devNamesNotExist = append(devNamesNotExist, unlinkedDeveloper)
+			devNamesNotExist = append(devNamesNotExist, username)
 		}
 	}
 
-	group, ctx := errgroup.WithContext(ctx)
+	return existing, devNamesNotExist, nil
+}
+
+// Split repository full names into the repositories already saved and the names which are not.
+func (fetcher *GithubFetcher) partitionRepositories(ctx context.Context, fullNames []string) ([]model.GhRepository, []string, error) {
+	repos, err := fetcher.repositories.GhRepositoryRepo.FindRepositoriesByNames(ctx, fullNames)
 
-	developersNotExist := make([]model.Developer, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query repositories by names: %v", err)
+	}
 
-	for _, dev := range devNamesNotExist {
-		devName := dev
+	existing := make([]model.GhRepository, 0, len(repos))
+	repoNamesNotExist := make([]string, 0)
 
-		group.Go(func() error {
-			developer, err := fetcher.gh.GetDeveloper(ctx, devName)
+	for _, fullName := range fullNames {
+		exist := false
 
-			if err != nil {
-				return err
+		for _, repo := range repos {
+			if strings.EqualFold(repo.FullName, fullName) {
+				exist = true
+				existing = append(existing, repo)
 			}
+		}
+
+		if !exist {
+			repoNamesNotExist = append(repoNamesNotExist, fullName)
+		}
+	}
 
-			lastInsertId, err := dr.Save(ctx, developer)
-			developer.Id = int(lastInsertId)
+	return existing, repoNamesNotExist, nil
+}
 
-			if err != nil {
-				return fmt.Errorf("failed to save developer: %v", err)
-			}
+func (fetcher *GithubFetcher) FetchDevelopers(ctx context.Context) error {
+	tdr := fetcher.repositories.TrendingDeveloperRepo
 
-			err = tdr.LinkDeveloper(ctx, developer)
+	unlinkedDevelopers, err := tdr.FindUnlinkedDevelopers(ctx)
 
-			if err != nil {
-				return fmt.Errorf("failed to link developer: %v", err)
-			}
+	if err != nil {
+		return fmt.Errorf("failed to query unlinked developers: %v", err)
+	}
 
-			developersNotExist = append(developersNotExist, developer)
-			return nil
-		})
+	developers, devNamesNotExist, err := fetcher.partitionDevelopers(ctx, unlinkedDevelopers)
+
+	if err != nil {
+		return err
 	}
 
-	if err := group.Wait(); err != nil {
+	// if developer exist in DB, then we update the relationship
+	for _, developer := range developers {
+		if err := tdr.LinkDeveloper(ctx, developer); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fetcher.repositories.JobRepo.Enqueue(ctx, model.JobFetchDeveloper, devNamesNotExist...); err != nil {
+		return fmt.Errorf("failed to enqueue developers fetch: %v", err)
+	}
+
+	q := queue.New(fetcher.repositories.JobRepo, queue.DefaultOptions)
+	q.Handle(model.JobFetchDeveloper, fetcher.HandleDeveloperJob)
+
+	stats, err := q.Drain(ctx)
+	if err != nil {
 		return fmt.Errorf("failed to fetch and save github developer details: %v", err)
 	}
 
-	return fetcher.search.UpsertDevelopers(developersNotExist...)
+	slog.Info("developers fetch completed.", slog.Int("done", stats.Done), slog.Int("retried", stats.Retried), slog.Int("dead", stats.Dead))
+	return nil
 }
 
 // Fetch repositories details from github rest api and save the relationship between trending_repositories and repositories.
 func (fetcher *GithubFetcher) FetchRepositories(ctx context.Context) error {
-	trr, grr := fetcher.repositories.TrendingRepositoryRepo, fetcher.repositories.GhRepositoryRepo
+	trr := fetcher.repositories.TrendingRepositoryRepo
 
 	unlinkedRepositories, err := trr.FindUnlinkedRepositories(ctx)
 
@@ -111,67 +142,256 @@ func (fetcher *GithubFetcher) FetchRepositories(ctx context.Context) error {
 		return fmt.Errorf("failed to query unlinked repositories: %v", err)
 	}
 
-	repos, err := grr.FindRepositoriesByNames(ctx, unlinkedRepositories)
+	repos, repoNamesNotExist, err := fetcher.partitionRepositories(ctx, unlinkedRepositories)
 
 	if err != nil {
-		return fmt.Errorf("failed to query repositories by names: %v", err)
+		return err
 	}
 
-	repoNamesNotExist := make([]string, 0)
-
 	// if repository exist in DB, then we update the relationship
-	for _, unlinkedRepo := range unlinkedRepositories {
-		exist := false
+	for _, repo := range repos {
+		if err := trr.LinkRepository(ctx, repo); err != nil {
+			return err
+		}
+	}
 
-		for _, repo := range repos {
-			if strings.EqualFold(repo.FullName, unlinkedRepo) {
-				exist = true
-				err := trr.LinkRepository(ctx, repo)
+	if _, err := fetcher.repositories.JobRepo.Enqueue(ctx, model.JobFetchRepository, repoNamesNotExist...); err != nil {
+		return fmt.Errorf("failed to enqueue repositories fetch: %v", err)
+	}
 
-				if err != nil {
-					return err
-				}
-			}
-		}
+	q := queue.New(fetcher.repositories.JobRepo, queue.DefaultOptions)
+	q.Handle(model.JobFetchRepository, fetcher.HandleRepositoryJob)
 
-		if !exist {
-			repoNamesNotExist = append(repoNamesNotExist, unlinkedRepo)
-		}
+	stats, err := q.Drain(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch and save github repository details: %v", err)
 	}
 
-	group, ctx := errgroup.WithContext(ctx)
+	slog.Info("repositories fetch completed.", slog.Int("done", stats.Done), slog.Int("retried", stats.Retried), slog.Int("dead", stats.Dead))
+	return nil
+}
 
-	repositoriesNotExist := make([]model.GhRepository, 0)
+// Report how linking the unlinked trending developers would change the DB, nothing is written.
+func (fetcher *GithubFetcher) PreviewDevelopers(ctx context.Context, report *dryrun.Report) error {
+	unlinkedDevelopers, err := fetcher.repositories.TrendingDeveloperRepo.FindUnlinkedDevelopers(ctx)
 
-	for _, repo := range repoNamesNotExist {
-		group.Go(func() error {
-			repository, err := fetcher.gh.GetRepository(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("failed to query unlinked developers: %v", err)
+	}
 
-			if err != nil {
-				return err
-			}
+	return fetcher.PreviewDeveloperNames(ctx, unlinkedDevelopers, report)
+}
 
-			lastInsertId, err := grr.Save(ctx, repository)
-			repository.Id = int(lastInsertId)
+// Report how linking trending developers by username would change the DB: developers already saved are linked,
+// the others are fetched from GitHub and reported as inserts. Nothing is written.
+func (fetcher *GithubFetcher) PreviewDeveloperNames(ctx context.Context, usernames []string, report *dryrun.Report) error {
+	developers, devNamesNotExist, err := fetcher.partitionDevelopers(ctx, usernames)
 
-			if err != nil {
-				return fmt.Errorf("failed to save repository: %v", err)
+	if err != nil {
+		return err
+	}
+
+	for _, developer := range developers {
+		report.Update(trendingDeveloperEntity, developer.Username, linkFields("developer_id", dbutils.NullInt64{}), linkFields("developer_id", dbutils.NewNullInt64(developer.Id)))
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(queue.DefaultOptions.Workers)
+
+	for _, username := range devNamesNotExist {
+		group.Go(func() error {
+			developer, err := fetcher.gh.GetDeveloper(groupCtx, username)
+
+			switch {
+			case groupCtx.Err() != nil:
+				return groupCtx.Err()
+			case errors.Is(err, github.ErrNotFound) || errors.Is(err, github.ErrAccessBlocked):
+				report.Skip(model.EntityDeveloper, username, "not found on GitHub or access blocked")
+			case err != nil:
+				report.Skip(model.EntityDeveloper, username, err.Error())
+			default:
+				report.Insert(model.EntityDeveloper, developer.Username, developer.DryRunFields())
 			}
 
-			err = trr.LinkRepository(ctx, repository)
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// Report how linking the unlinked trending repositories would change the DB, nothing is written.
+func (fetcher *GithubFetcher) PreviewRepositories(ctx context.Context, report *dryrun.Report) error {
+	unlinkedRepositories, err := fetcher.repositories.TrendingRepositoryRepo.FindUnlinkedRepositories(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to query unlinked repositories: %v", err)
+	}
+
+	return fetcher.PreviewRepositoryNames(ctx, unlinkedRepositories, report)
+}
+
+// Report how linking trending repositories by full name would change the DB: repositories already saved are linked,
+// the others are fetched from GitHub and reported as inserts. Nothing is written.
+func (fetcher *GithubFetcher) PreviewRepositoryNames(ctx context.Context, fullNames []string, report *dryrun.Report) error {
+	repos, repoNamesNotExist, err := fetcher.partitionRepositories(ctx, fullNames)
 
-			if err != nil {
-				return fmt.Errorf("failed to link repository: %v", err)
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		report.Update(trendingRepositoryEntity, repo.FullName, linkFields("repository_id", dbutils.NullInt64{}), linkFields("repository_id", dbutils.NewNullInt64(repo.Id)))
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(queue.DefaultOptions.Workers)
+
+	for _, fullName := range repoNamesNotExist {
+		group.Go(func() error {
+			repository, err := fetcher.gh.GetRepository(groupCtx, fullName)
+
+			switch {
+			case groupCtx.Err() != nil:
+				return groupCtx.Err()
+			case errors.Is(err, github.ErrNotFound) || errors.Is(err, github.ErrAccessBlocked):
+				report.Skip(model.EntityRepository, fullName, "not found on GitHub or access blocked")
+			case err != nil:
+				report.Skip(model.EntityRepository, fullName, err.Error())
+			default:
+				report.Insert(model.EntityRepository, repository.FullName, repository.DryRunFields())
 			}
 
-			repositoriesNotExist = append(repositoriesNotExist, repository)
 			return nil
 		})
 	}
 
-	if err := group.Wait(); err != nil {
-		return fmt.Errorf("failed to fetch and save github repository details: %v", err)
+	return group.Wait()
+}
+
+// Fields of the trending rows written by a link, a newly fetched entity is linked once it is inserted.
+func linkFields(column string, id dbutils.NullInt64) []dryrun.Field {
+	return []dryrun.Field{{Name: column, Value: id}}
+}
+
+// Register the fetch job handlers in a queue run by a long running worker.
+func (fetcher *GithubFetcher) Register(q *queue.Queue) {
+	q.Handle(model.JobFetchRepository, fetcher.HandleRepositoryJob)
+	q.Handle(model.JobFetchDeveloper, fetcher.HandleDeveloperJob)
+}
+
+// Job handler of model.JobFetchDeveloper.
+func (fetcher *GithubFetcher) HandleDeveloperJob(ctx context.Context, job model.Job) error {
+	tdr, dr := fetcher.repositories.TrendingDeveloperRepo, fetcher.repositories.DeveloperRepo
+
+	// The developer may have been fetched since the job was enqueued.
+	developer, err := dr.FindByUsername(ctx, job.Payload)
+	if err == nil {
+		return tdr.LinkDeveloper(ctx, developer)
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to find developer by username: %v", err)
+	}
+
+	_, err = fetcher.FetchDeveloper(ctx, job.Payload)
+	if errors.Is(err, github.ErrNotFound) || errors.Is(err, github.ErrAccessBlocked) {
+		return queue.Permanent(err)
+	}
+
+	return fetcher.gh.DeferRateLimited(err)
+}
+
+// Job handler of model.JobFetchRepository.
+func (fetcher *GithubFetcher) HandleRepositoryJob(ctx context.Context, job model.Job) error {
+	trr, grr := fetcher.repositories.TrendingRepositoryRepo, fetcher.repositories.GhRepositoryRepo
+
+	// The repository may have been fetched since the job was enqueued.
+	repository, err := grr.FindByName(ctx, job.Payload)
+	if err == nil {
+		return trr.LinkRepository(ctx, repository)
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to find repository by name: %v", err)
+	}
+
+	_, err = fetcher.FetchRepository(ctx, job.Payload)
+	if errors.Is(err, github.ErrNotFound) || errors.Is(err, github.ErrAccessBlocked) {
+		return queue.Permanent(err)
+	}
+
+	return fetcher.gh.DeferRateLimited(err)
+}
+
+// Fetch a single developer from github rest api, save it with its profile, link its trending appearances and index it in search.
+func (fetcher *GithubFetcher) FetchDeveloper(ctx context.Context, username string) (model.Developer, error) {
+	tdr, dr, dpr := fetcher.repositories.TrendingDeveloperRepo, fetcher.repositories.DeveloperRepo, fetcher.repositories.DeveloperProfileRepo
+
+	developer, err := fetcher.gh.GetDeveloper(ctx, username)
+
+	if err != nil {
+		return developer, err
+	}
+
+	lastInsertId, err := dr.Save(ctx, developer)
+	developer.Id = int(lastInsertId)
+
+	if err != nil {
+		return developer, fmt.Errorf("failed to save developer: %v", err)
+	}
+
+	err = tdr.LinkDeveloper(ctx, developer)
+
+	if err != nil {
+		return developer, fmt.Errorf("failed to link developer: %v", err)
+	}
+
+	profile, err := fetcher.gh.GetDeveloperProfile(ctx, username)
+
+	if err != nil && !errors.Is(err, github.ErrNotFound) {
+		return developer, fmt.Errorf("failed to fetch developer profile: %w", err)
+	}
+
+	if err == nil {
+		if err := dpr.Save(ctx, developer.Id, profile); err != nil {
+			return developer, fmt.Errorf("failed to save developer profile: %v", err)
+		}
+	}
+
+	return developer, fetcher.search.UpsertDevelopers(developer)
+}
+
+// Fetch a single repository from github rest api, save it, link its trending appearances and index it in search.
+func (fetcher *GithubFetcher) FetchRepository(ctx context.Context, fullName string) (model.GhRepository, error) {
+	trr, grr := fetcher.repositories.TrendingRepositoryRepo, fetcher.repositories.GhRepositoryRepo
+
+	repository, err := fetcher.gh.GetRepository(ctx, fullName)
+
+	if err != nil {
+		return repository, err
+	}
+
+	// Link the organization right away, the repository might not be synced again for a week.
+	if repository.Owner.Type == "Organization" {
+		repository.OrganizationId, err = fetcher.gh.SaveOrganization(ctx, fetcher.repositories.OrganizationRepo, repository.Owner.Name)
+		if err != nil {
+			return repository, err
+		}
+	}
+
+	lastInsertId, err := grr.Save(ctx, repository)
+	repository.Id = int(lastInsertId)
+
+	if err != nil {
+		return repository, fmt.Errorf("failed to save repository: %v", err)
+	}
+
+	err = trr.LinkRepository(ctx, repository)
+
+	if err != nil {
+		return repository, fmt.Errorf("failed to link repository: %v", err)
 	}
 
-	return fetcher.search.UpsertRepositories(repositoriesNotExist...)
+	return repository, fetcher.search.UpsertRepositories(repository)
 }
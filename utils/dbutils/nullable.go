@@ -10,6 +10,15 @@ type NullString struct {
 	sql.NullString
 }
 
+func NewNullString(data string) NullString {
+	return NullString{
+		NullString: sql.NullString{
+			String: data,
+			Valid:  true,
+		},
+	}
+}
+
 func (v NullString) MarshalJSON() ([]byte, error) {
 	if v.Valid {
 		return json.Marshal(v.String)
@@ -50,6 +50,17 @@ func (qb *QueryBuilder) Where(query string, value any) *QueryBuilder {
 	return qb
 }
 
+// WhereArgs adds a criteria with any number of placeholders, e.g. an IN clause.
+func (qb *QueryBuilder) WhereArgs(query string, values ...any) *QueryBuilder {
+	qb.mu.Lock()
+	defer qb.mu.Unlock()
+
+	qb.criteria = append(qb.criteria, query)
+	qb.args = append(qb.args, values...)
+
+	return qb
+}
+
 func (qb *QueryBuilder) GroupBy(condition string) *QueryBuilder {
 	qb.mu.Lock()
 	defer qb.mu.Unlock()
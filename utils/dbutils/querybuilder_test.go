@@ -49,3 +49,23 @@ func TestReset(t *testing.T) {
 		t.Errorf("query builder has not been rest: %+v", qb)
 	}
 }
+
+func TestWhereArgs(t *testing.T) {
+	qb := NewQueryBuilder()
+
+	qb.Query("select * from repositories")
+	qb.Where("`repositories`.`language` = ?", "Go")
+	qb.WhereArgs("`repositories`.`id` IN (?, ?)", 1, 2)
+	qb.WhereArgs("`repositories`.`skipped` = false")
+
+	query, args := qb.GetQuery()
+
+	want := "select * from repositories WHERE `repositories`.`language` = ? AND `repositories`.`id` IN (?, ?) AND `repositories`.`skipped` = false"
+	if query != want {
+		t.Errorf("want: %s, but got: %s", want, query)
+	}
+
+	if len(args) != 3 || args[0] != "Go" || args[1] != 1 || args[2] != 2 {
+		t.Errorf("unexpected args: %v", args...)
+	}
+}
@@ -7,18 +7,64 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/liweiyi88/trendshift-backend/model"
 	"github.com/liweiyi88/trendshift-backend/model/opt"
+	"github.com/liweiyi88/trendshift-backend/pageview"
+	"github.com/liweiyi88/trendshift-backend/web/middleware"
 )
 
 type DeveloperController struct {
-	dr *model.DeveloperRepo
+	dr    *model.DeveloperRepo
+	dsr   *model.DeveloperSnapshotRepo
+	dpr   *model.DeveloperProfileRepo
+	rcr   *model.RepositoryContributorRepo
+	tdr   *model.TrendingDeveloperRepo
+	jr    *model.JobRepo
+	views *pageview.Counter
 }
 
-func NewDeveloperController(dr *model.DeveloperRepo) *DeveloperController {
-	return &DeveloperController{dr}
+func NewDeveloperController(dr *model.DeveloperRepo, dsr *model.DeveloperSnapshotRepo, dpr *model.DeveloperProfileRepo, rcr *model.RepositoryContributorRepo, tdr *model.TrendingDeveloperRepo, jr *model.JobRepo, views *pageview.Counter) *DeveloperController {
+	return &DeveloperController{dr, dsr, dpr, rcr, tdr, jr, views}
+}
+
+// Cursor paginated, valid query parameter example: ?sort=followers&order=desc&limit=20&cursor=...&location=Berlin&company=github&trending_language=Go
+func (dc *DeveloperController) List(c *gin.Context) {
+	params, err := model.NewDeveloperListParams(
+		c.DefaultQuery("sort", "followers"),
+		c.Query("order"),
+		c.Query("limit"),
+		c.Query("cursor"),
+		c.Query("location"),
+		c.Query("company"),
+		c.Query("trending_language"),
+	)
+
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	page, err := dc.dr.FindPage(c, params)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	response := model.Page[model.PublicDeveloper]{
+		Data:       make([]model.PublicDeveloper, 0, len(page.Data)),
+		NextCursor: page.NextCursor,
+	}
+
+	for _, developer := range page.Data {
+		response.Data = append(response.Data, model.NewPublicDeveloper(developer))
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 func (dc *DeveloperController) Get(c *gin.Context) {
@@ -32,18 +78,169 @@ func (dc *DeveloperController) Get(c *gin.Context) {
 
 	developer, err := dc.dr.FindById(c, id)
 
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	if developer.Id == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	dc.respondWithProfile(c, developer)
+}
+
+// Look up a developer by GitHub login, e.g. /api/developers/by-login/liweiyi88
+// When the developer is not tracked yet, ?fetch=true queues a fetch from GitHub for an authenticated user and responds with 202 Accepted.
+func (dc *DeveloperController) GetByLogin(c *gin.Context) {
+	login, err := model.ParseGithubLogin(c.Param("login"))
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	developer, err := dc.dr.FindByUsername(c, login)
+
 	if errors.Is(err, sql.ErrNoRows) {
+		if c.Query("fetch") != "true" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		// Fetching spends our GitHub tokens, anonymous users can only look up tracked developers.
+		if _, ok := middleware.Claims(c); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		if _, err := dc.jr.Enqueue(c, model.JobFetchDeveloper, login); err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"status": "queued"})
+		return
+	}
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	// Load the trending appearances as the lookup by id does.
+	developer, err = dc.dr.FindById(c, developer.Id)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	// The developer has been deleted since it was found by its login.
+	if developer.Id == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
 		return
 	}
 
+	dc.respondWithProfile(c, developer)
+}
+
+func (dc *DeveloperController) respondWithProfile(c *gin.Context, developer model.Developer) {
+	id := developer.Id
+
+	profile, err := dc.dpr.FindByDeveloperId(c, id)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	contributesTo, err := dc.rcr.FindByDeveloperId(c, id)
 	if err != nil {
 		slog.Error(err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
 		return
 	}
 
-	c.JSON(http.StatusOK, developer)
+	response := model.DeveloperWithProfile{
+		Developer:     developer,
+		Profile:       profile,
+		ContributesTo: contributesTo,
+	}
+
+	// Page views raise the sync priority of the developer.
+	dc.views.Count(c.ClientIP(), model.EntityDeveloper, id)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Returns the followers and public repositories history of a developer, valid query parameter example: ?range=90
+func (dc *DeveloperController) GetHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	since, err := model.ParseHistorySince(c.Query("range"), time.Now())
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	snapshots, err := dc.dsr.FindByDeveloperId(c, id, since)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshots)
+}
+
+// Developers trending for the first time, valid query parameter example: ?period=week&language=Go&limit=20
+func (dc *DeveloperController) GetNewTrendingDevelopers(c *gin.Context) {
+	language, _ := url.QueryUnescape(c.Query("language"))
+
+	dateRange, err := model.ParseNewEntryPeriod(c.DefaultQuery("period", "week"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	var limit int
+
+	if limitQuery := c.Query("limit"); limitQuery != "" {
+		limit, err = strconv.Atoi(limitQuery)
+
+		if err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+			return
+		}
+	}
+
+	developers, err := dc.dr.FindNewTrendingDevelopers(
+		c,
+		opt.Language(language),
+		opt.Limit(limit),
+		opt.DateRange(dateRange),
+	)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, developers)
 }
 
 func (dc *DeveloperController) GetTrendingDevelopers(c *gin.Context) {
@@ -88,3 +285,30 @@ func (dc *DeveloperController) GetTrendingDevelopers(c *gin.Context) {
 
 	c.JSON(http.StatusOK, developers)
 }
+
+// Returns the trending streaks and best ranks of a developer, valid query parameter example: ?from=2025-01-01&to=2025-06-30
+func (dc *DeveloperController) GetTrendingHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	params, err := model.NewTrendingHistoryParams(c.Query("from"), c.Query("to"))
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	history, err := dc.tdr.FindHistoryByDeveloperId(c, id, params)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -20,7 +20,7 @@ func NewRepositoryEngagementController(rmr *model.RepositoryMonthlyInsightRepo)
 	}
 }
 
-// Valid query parameter example: ?year=2025&month=10&language=Go&limit=10&created_after=2024-01-02T15:04:05+10:00
+// Valid query parameter example: ?year=2025&month=10&language=Go&limit=10&created_after=2024-01-02T15:04:05+10:00&tag=AI&tag_mode=or
 func (controller *RepositoryEngagementController) List(c *gin.Context) {
 	ts := datetime.StartOfThisMonth()
 	defaultYear, defaultMonth := strconv.Itoa(ts.Year()), strconv.Itoa(int(ts.Month()))
@@ -32,6 +32,10 @@ func (controller *RepositoryEngagementController) List(c *gin.Context) {
 	limitStr := c.DefaultQuery("limit", "10")
 
 	params, err := model.NewListEngagementParams(c.Param("metric"), yearStr, monthStr, languageStr, limitStr, createdAfterStr)
+	if err == nil {
+		err = params.SetTags(c.QueryArray("tag"), c.Query("tag_mode"))
+	}
+
 	if err != nil {
 		slog.Error("params are not valid", slog.Any("error", err))
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
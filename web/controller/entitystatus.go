@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liweiyi88/trendshift-backend/model"
+)
+
+const entityStatusesLimit = 100
+
+type EntityStatusController struct {
+	esr *model.EntityStatusRepo
+	jr  *model.JobRepo
+}
+
+type PurgeEntityRequest struct {
+	Reason string `json:"reason"`
+}
+
+func NewEntityStatusController(esr *model.EntityStatusRepo, jr *model.JobRepo) *EntityStatusController {
+	return &EntityStatusController{
+		esr,
+		jr,
+	}
+}
+
+// Valid query parameter example: ?type=repository&status=dmca_blocked
+func (ec *EntityStatusController) List(c *gin.Context) {
+	entityType, err := model.ParseEntityType(c.Query("type"))
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	status, err := model.ParseEntityStatus(c.Query("status"))
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	statuses, err := ec.esr.FindAll(c, entityType, status, entityStatusesLimit)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
+
+func (ec *EntityStatusController) RestoreRepository(c *gin.Context) {
+	ec.restore(c, model.EntityRepository, model.JobSyncRepository)
+}
+
+func (ec *EntityStatusController) RestoreDeveloper(c *gin.Context) {
+	ec.restore(c, model.EntityDeveloper, model.JobSyncDeveloper)
+}
+
+func (ec *EntityStatusController) PurgeRepository(c *gin.Context) {
+	ec.purge(c, model.EntityRepository)
+}
+
+func (ec *EntityStatusController) PurgeDeveloper(c *gin.Context) {
+	ec.purge(c, model.EntityDeveloper)
+}
+
+// Make an entity active and visible again, it is synced right away so its details are up to date.
+func (ec *EntityStatusController) restore(c *gin.Context, entityType, syncJob string) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	err = ec.esr.Restore(c, entityType, id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	// The entity is restored whether or not the sync is enqueued, the next scheduled sync picks it up anyway.
+	if _, err := ec.jr.Enqueue(c, syncJob, strconv.Itoa(id)); err != nil {
+		slog.Error("failed to enqueue sync of restored entity", slog.String("type", entityType), slog.Int("id", id), slog.Any("error", err))
+	}
+
+	status, err := ec.esr.FindByEntity(c, entityType, id)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Soft delete an entity, it is hidden and never rechecked until it is restored.
+func (ec *EntityStatusController) purge(c *gin.Context, entityType string) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	var request PurgeEntityRequest
+
+	// The reason is optional, so is the body.
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	reason := request.Reason
+	if reason == "" {
+		reason = "deleted by an admin"
+	}
+
+	status, err := ec.esr.Purge(c, entityType, id, reason)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case err != nil:
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+	default:
+		c.JSON(http.StatusOK, status)
+	}
+}
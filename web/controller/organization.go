@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/model/opt"
+)
+
+type OrganizationController struct {
+	or *model.OrganizationRepo
+}
+
+func NewOrganizationController(or *model.OrganizationRepo) *OrganizationController {
+	return &OrganizationController{or}
+}
+
+// Valid query parameter example: ?language=Go&range=30&limit=20
+func (oc *OrganizationController) GetTrendingOrganizations(c *gin.Context) {
+	language, _ := url.QueryUnescape(c.Query("language"))
+	limitQuery, _ := url.QueryUnescape(c.Query("limit"))
+	dateRangeQuery := c.Query("range")
+
+	var limit int
+	var dateRange int
+	var err error
+
+	if dateRangeQuery != "" {
+		dateRange, err = strconv.Atoi(dateRangeQuery)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bad Request"})
+			return
+		}
+	}
+
+	if limitQuery != "" {
+		limit, err = strconv.Atoi(limitQuery)
+
+		if err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+			return
+		}
+	}
+
+	organizations, err := oc.or.FindTrendingOrganizations(
+		c,
+		opt.Language(language),
+		opt.Limit(limit),
+		opt.DateRange(dateRange),
+	)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, organizations)
+}
@@ -6,17 +6,29 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
 	"github.com/liweiyi88/trendshift-backend/model"
 	"github.com/liweiyi88/trendshift-backend/model/opt"
+	"github.com/liweiyi88/trendshift-backend/pageview"
+	"github.com/liweiyi88/trendshift-backend/tracking"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+	"github.com/liweiyi88/trendshift-backend/web/middleware"
 )
 
 type RepositoryController struct {
-	grr *model.GhRepositoryRepo
-	rr  *model.RepositoryMonthlyInsightRepo
+	grr     *model.GhRepositoryRepo
+	rr      *model.RepositoryMonthlyInsightRepo
+	rsr     *model.RepositorySnapshotRepo
+	rcr     *model.RepositoryContributorRepo
+	rsm     *model.RepositorySimilarityRepo
+	trr     *model.TrendingRepositoryRepo
+	jr      *model.JobRepo
+	views   *pageview.Counter
+	tracker *tracking.Tracker
 }
 
 type AttachTagsRequest struct {
@@ -24,24 +36,60 @@ type AttachTagsRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
-func NewRepositoryController(grr *model.GhRepositoryRepo, rr *model.RepositoryMonthlyInsightRepo) *RepositoryController {
+func NewRepositoryController(grr *model.GhRepositoryRepo, rr *model.RepositoryMonthlyInsightRepo, rsr *model.RepositorySnapshotRepo, rcr *model.RepositoryContributorRepo, rsm *model.RepositorySimilarityRepo, trr *model.TrendingRepositoryRepo, jr *model.JobRepo, views *pageview.Counter, tracker *tracking.Tracker) *RepositoryController {
 	return &RepositoryController{
 		grr,
 		rr,
+		rsr,
+		rcr,
+		rsm,
+		trr,
+		jr,
+		views,
+		tracker,
 	}
 }
 
+// Cursor paginated, valid query parameter example: ?sort=stars&order=desc&limit=20&cursor=...&language=Go&license=mit&min_stars=100&max_stars=5000&skipped=false&archived=false&tag=AI&tag=Web&tag_mode=and
+// q=today only returns the repositories on a trending page today.
 func (rc *RepositoryController) List(c *gin.Context) {
-	ghRepositories, err := rc.grr.FindAllWithTags(c, c.Query("q"))
+	params, err := model.NewRepositoryListParams(
+		c.DefaultQuery("sort", "stars"),
+		c.Query("order"),
+		c.Query("limit"),
+		c.Query("cursor"),
+		c.Query("language"),
+		c.Query("license"),
+		c.Query("min_stars"),
+		c.Query("max_stars"),
+		c.Query("skipped"),
+		c.Query("archived"),
+	)
+
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	if err := params.SetTags(c.QueryArray("tag"), c.Query("tag_mode")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	params.TrendingToday = c.Query("q") == "today"
+
+	page, err := rc.grr.FindPageWithTags(c, params)
 	if err != nil {
 		slog.Error(err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
 		return
 	}
 
-	c.JSON(http.StatusOK, ghRepositories)
+	c.JSON(http.StatusOK, page)
 }
 
+// Valid query parameter example: ?language=Go&range=30&limit=20&tag=AI&tag=LLM&tag_mode=or
 func (rc *RepositoryController) GetTrendingRepositories(c *gin.Context) {
 	language, _ := url.QueryUnescape(c.Query("language"))
 	limitQuery, _ := url.QueryUnescape(c.Query("limit"))
@@ -69,11 +117,57 @@ func (rc *RepositoryController) GetTrendingRepositories(c *gin.Context) {
 		}
 	}
 
+	tagMode, err := model.ParseTagMode(c.Query("tag_mode"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
 	repositories, err := rc.grr.FindTrendingRepositories(
 		c,
 		opt.Language(language),
 		opt.Limit(limit),
 		opt.DateRange(dateRange),
+		opt.Tags(c.QueryArray("tag")...),
+		opt.TagMode(tagMode),
+	)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, repositories)
+}
+
+// Repositories trending for the first time, valid query parameter example: ?period=week&language=Go&limit=20
+func (rc *RepositoryController) GetNewTrendingRepositories(c *gin.Context) {
+	language, _ := url.QueryUnescape(c.Query("language"))
+
+	dateRange, err := model.ParseNewEntryPeriod(c.DefaultQuery("period", "week"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	var limit int
+
+	if limitQuery := c.Query("limit"); limitQuery != "" {
+		limit, err = strconv.Atoi(limitQuery)
+
+		if err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+			return
+		}
+	}
+
+	repositories, err := rc.grr.FindNewTrendingRepositories(
+		c,
+		opt.Language(language),
+		opt.Limit(limit),
+		opt.DateRange(dateRange),
 	)
 
 	if err != nil {
@@ -85,6 +179,33 @@ func (rc *RepositoryController) GetTrendingRepositories(c *gin.Context) {
 	c.JSON(http.StatusOK, repositories)
 }
 
+// Valid query parameter example: ?window=7d&language=Go&sort=stars&min_stars=100&min_star_delta=10&limit=20
+func (rc *RepositoryController) GetRisingRepositories(c *gin.Context) {
+	params, err := model.NewRisingRepositoriesParams(
+		c.DefaultQuery("window", "7d"),
+		c.DefaultQuery("sort", "stars"),
+		c.Query("language"),
+		c.Query("min_stars"),
+		c.Query("min_star_delta"),
+		c.DefaultQuery("limit", "20"),
+	)
+
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	repositories, err := rc.grr.FindRisingRepositories(c, params)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, repositories)
+}
+
 func (rc *RepositoryController) Get(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 
@@ -96,17 +217,90 @@ func (rc *RepositoryController) Get(c *gin.Context) {
 
 	repository, err := rc.grr.FindById(c, id)
 
-	if errors.Is(err, sql.ErrNoRows) {
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	if repository.Id == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
 		return
 	}
 
+	rc.respondWithActivities(c, repository)
+}
+
+// Look up a repository by its GitHub full name, e.g. /api/repositories/by-name/liweiyi88/trendshift-backend
+// When the repository is not tracked yet, ?fetch=true responds with 202 Accepted and, for an authenticated user,
+// submits the repository for review like /api/repositories/track does. Only admins have it fetched from GitHub right away.
+func (rc *RepositoryController) GetByName(c *gin.Context) {
+	fullName, err := model.ParseRepositoryFullName(c.Param("owner"), c.Param("repo"))
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	repository, err := rc.grr.FindByName(c, fullName)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		if c.Query("fetch") != "true" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		rc.fetchOrSubmit(c, fullName)
+		return
+	}
+
 	if err != nil {
 		slog.Error(err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
 		return
 	}
 
+	rc.respondWithActivities(c, repository)
+}
+
+func (rc *RepositoryController) fetchOrSubmit(c *gin.Context, fullName string) {
+	claims, ok := middleware.Claims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if model.HasRole(claims.Role, model.RoleAdmin) {
+		if _, err := rc.jr.Enqueue(c, model.JobFetchRepository, fullName); err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"status": "queued"})
+		return
+	}
+
+	submission, err := rc.tracker.Submit(c, fullName, dbutils.NewNullInt64(claims.UserId))
+
+	switch {
+	case errors.Is(err, tracking.ErrNotOnGitHub):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case errors.Is(err, tracking.ErrAlreadyTracked):
+		c.JSON(http.StatusConflict, gin.H{"error": "Repository is already tracked"})
+	case errors.Is(err, tracking.ErrAlreadySubmitted):
+		c.JSON(http.StatusAccepted, gin.H{"status": "submitted"})
+	case err != nil:
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"status": "submitted", "submission": submission})
+	}
+}
+
+func (rc *RepositoryController) respondWithActivities(c *gin.Context, repository model.GhRepository) {
+	id := repository.Id
+
 	activities, err := rc.rr.FindByRepositoryId(c, id)
 	if err != nil {
 		slog.Error(err.Error())
@@ -114,14 +308,159 @@ func (rc *RepositoryController) Get(c *gin.Context) {
 		return
 	}
 
+	contributors, err := rc.rcr.FindByRepositoryId(c, id)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
 	response := model.RepositoryWithActivities{
 		GhRepository:      repository,
 		MonthlyActivities: activities,
+		TopContributors:   contributors,
+	}
+
+	// Page views raise the sync priority of the repository.
+	rc.views.Count(c.ClientIP(), model.EntityRepository, id)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Compare up to 10 repositories side by side, valid query parameter example: ?ids=1,2,3
+func (rc *RepositoryController) Compare(c *gin.Context) {
+	ids, err := model.ParseRepositoryIds(c.Query("ids"))
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	repositories := make([]model.GhRepository, 0, len(ids))
+	activities := make([][]model.RepositoryMonthlyInsight, 0, len(ids))
+
+	for _, id := range ids {
+		repository, err := rc.grr.FindById(c, id)
+		if err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+			return
+		}
+
+		if repository.Id == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		insights, err := rc.rr.FindByRepositoryId(c, id)
+		if err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+			return
+		}
+
+		repositories = append(repositories, repository)
+		activities = append(activities, insights)
+	}
+
+	months, aligned := model.AlignMonthlyActivities(activities)
+
+	response := model.RepositoryComparison{
+		Months:       months,
+		Repositories: make([]model.ComparedRepository, 0, len(repositories)),
+	}
+
+	for i, repository := range repositories {
+		response.Repositories = append(response.Repositories, model.ComparedRepository{
+			GhRepository:      repository,
+			Trending:          model.SummariseTrendings(repository.Trendings),
+			MonthlyActivities: aligned[i],
+		})
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// Returns the stars and forks history of a repository, valid query parameter example: ?range=90
+func (rc *RepositoryController) GetHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	since, err := model.ParseHistorySince(c.Query("range"), time.Now())
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	snapshots, err := rc.rsr.FindByRepositoryId(c, id, since)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshots)
+}
+
+// Returns the trending streaks and best ranks of a repository, valid query parameter example: ?from=2025-01-01&to=2025-06-30
+func (rc *RepositoryController) GetTrendingHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	params, err := model.NewTrendingHistoryParams(c.Query("from"), c.Query("to"))
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	history, err := rc.trr.FindHistoryByRepositoryId(c, id, params)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// Returns the precomputed related repositories, valid query parameter example: ?limit=5
+func (rc *RepositoryController) GetRelated(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	related, err := rc.rsm.FindRelated(c, id, min(limit, 50))
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, related)
+}
+
 func (rc *RepositoryController) SaveTags(c *gin.Context) {
 	repositoryId, err := strconv.Atoi(c.Param("id"))
 
@@ -2,7 +2,6 @@ package controller
 
 import (
 	"net/http"
-	"strconv"
 
 	"log/slog"
 
@@ -20,21 +19,117 @@ func NewStatsController(sr *model.StatsRepo) *StatsController {
 	}
 }
 
+func newTopicStatsParams(c *gin.Context, defaultRange, defaultLimit string) (*model.TopicStatsParams, bool) {
+	params, err := model.NewTopicStatsParams(
+		c.DefaultQuery("range", defaultRange),
+		c.Query("interval"),
+		c.Query("level"),
+		c.Query("language"),
+		c.DefaultQuery("limit", defaultLimit),
+	)
+
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad Request"})
+		return nil, false
+	}
+
+	return params, true
+}
+
+// Valid query parameter example: ?range=90&interval=week&level=1&language=Go
 func (sc *StatsController) GetTrendingTopicsStats(c *gin.Context) {
-	dateRange := c.Query("range")
+	params, ok := newTopicStatsParams(c, "", "")
+	if !ok {
+		return
+	}
+
+	stats, err := sc.sr.FindTrendingTopicsStats(c, params)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
 
-	var r int
-	var err error
+	c.JSON(http.StatusOK, stats)
+}
+
+// Valid query parameter example: ?range=90&interval=month&level=1&language=Go
+func (sc *StatsController) GetTopicShares(c *gin.Context) {
+	params, ok := newTopicStatsParams(c, "90", "")
+	if !ok {
+		return
+	}
+
+	shares, err := sc.sr.FindTopicShares(c, params)
 
-	if dateRange != "" {
-		r, err = strconv.Atoi(dateRange)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Bad Request"})
-			return
-		}
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
+
+// Compare the last range days with the range days before, valid query parameter example: ?range=7&level=1&language=Go&limit=10
+func (sc *StatsController) GetTopicMomentum(c *gin.Context) {
+	params, ok := newTopicStatsParams(c, "7", "20")
+	if !ok {
+		return
+	}
+
+	if params.Range == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad Request"})
+		return
+	}
+
+	momentum, err := sc.sr.FindTopicMomentum(c, params)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, momentum)
+}
+
+// Valid query parameter example: ?range=30&language=Go&limit=5&level=1
+func (sc *StatsController) GetTopicTopRepositories(c *gin.Context) {
+	params, ok := newTopicStatsParams(c, "30", "5")
+	if !ok {
+		return
+	}
+
+	repositories, err := sc.sr.FindTopicTopRepositories(c, params)
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, repositories)
+}
+
+// Valid query parameter example: ?range=365&interval=month&language=Go&limit=10
+func (sc *StatsController) GetLanguageStats(c *gin.Context) {
+	params, err := model.NewLanguageStatsParams(
+		c.DefaultQuery("range", "180"),
+		c.DefaultQuery("interval", model.StatsIntervalMonth),
+		c.Query("language"),
+		c.DefaultQuery("limit", "10"),
+	)
+
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad Request"})
+		return
 	}
 
-	stats, err := sc.sr.FindTrendingTopicsStats(c, r)
+	stats, err := sc.sr.FindLanguageStats(c, params)
 
 	if err != nil {
 		slog.Error(err.Error())
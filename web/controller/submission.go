@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/tracking"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+	"github.com/liweiyi88/trendshift-backend/web/middleware"
+)
+
+const submissionsLimit = 100
+
+type RepositorySubmissionController struct {
+	tracker *tracking.Tracker
+	rsr     *model.RepositorySubmissionRepo
+}
+
+type TrackRepositoryRequest struct {
+	Name string `json:"name" binding:"required"` // owner/name
+}
+
+type RejectSubmissionRequest struct {
+	Note string `json:"note"`
+}
+
+func NewRepositorySubmissionController(tracker *tracking.Tracker, rsr *model.RepositorySubmissionRepo) *RepositorySubmissionController {
+	return &RepositorySubmissionController{
+		tracker,
+		rsr,
+	}
+}
+
+// Submit a repository to track, it is pending until an admin approves it.
+func (sc *RepositorySubmissionController) Track(c *gin.Context) {
+	var request TrackRepositoryRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fullName, err := model.ParseRepositoryName(request.Name)
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	submission, err := sc.tracker.Submit(c, fullName, currentUserId(c))
+
+	switch {
+	case errors.Is(err, tracking.ErrNotOnGitHub):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Repository not found on GitHub"})
+	case errors.Is(err, tracking.ErrAlreadyTracked):
+		c.JSON(http.StatusConflict, gin.H{"error": "Repository is already tracked"})
+	case errors.Is(err, tracking.ErrAlreadySubmitted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Repository is already submitted"})
+	case err != nil:
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+	default:
+		c.JSON(http.StatusCreated, submission)
+	}
+}
+
+// Valid query parameter example: ?status=pending
+func (sc *RepositorySubmissionController) List(c *gin.Context) {
+	status, err := model.ParseSubmissionStatus(c.Query("status"))
+	if err != nil {
+		slog.Error("params are not valid", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	submissions, err := sc.rsr.FindAll(c, status, submissionsLimit)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, submissions)
+}
+
+func (sc *RepositorySubmissionController) Approve(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	submission, err := sc.tracker.Approve(c, id, currentUserId(c))
+	sc.respondWithReview(c, submission, err)
+}
+
+func (sc *RepositorySubmissionController) Reject(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	var request RejectSubmissionRequest
+
+	// The note is optional, so is the body.
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	submission, err := sc.tracker.Reject(c, id, currentUserId(c), request.Note)
+	sc.respondWithReview(c, submission, err)
+}
+
+func (sc *RepositorySubmissionController) respondWithReview(c *gin.Context, submission model.RepositorySubmission, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case errors.Is(err, model.ErrSubmissionReviewed):
+		c.JSON(http.StatusConflict, gin.H{"error": "Submission is already reviewed"})
+	case errors.Is(err, tracking.ErrNotOnGitHub):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Repository not found on GitHub"})
+	case err != nil:
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+	default:
+		c.JSON(http.StatusOK, submission)
+	}
+}
+
+func currentUserId(c *gin.Context) dbutils.NullInt64 {
+	claims, ok := middleware.Claims(c)
+	if !ok {
+		return dbutils.NullInt64{}
+	}
+
+	return dbutils.NewNullInt64(claims.UserId)
+}
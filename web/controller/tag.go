@@ -1,26 +1,64 @@
 package controller
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
 	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/utils/dbutils"
+)
+
+const maxBulkTagRepositories = 1000
+
+// Longest tag icon and description, names and icons are stored in varchar(255) columns.
+const (
+	maxTagFieldLength       = 255
+	maxTagDescriptionLength = 1000
 )
 
 type TagController struct {
-	tr *model.TagRepo
+	tr  *model.TagRepo
+	grr *model.GhRepositoryRepo
 }
 
 type CreateTagRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type CreateTagAliasRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type SetTagParentRequest struct {
+	ParentId dbutils.NullInt64 `json:"parent_id"`
+}
+
+type UpdateTagRequest struct {
+	Name        string             `json:"name" binding:"required,max=255"`
+	Description dbutils.NullString `json:"description"`
+	Color       dbutils.NullString `json:"color"`
+	Icon        dbutils.NullString `json:"icon"`
 }
 
-func NewTagController(tr *model.TagRepo) *TagController {
+type BulkTagRepositoriesRequest struct {
+	RepositoryIds []int `json:"repository_ids" binding:"required,min=1"`
+}
+
+type MergeTagRequest struct {
+	TargetId int `json:"target_id" binding:"required"`
+}
+
+func NewTagController(tr *model.TagRepo, grr *model.GhRepositoryRepo) *TagController {
 	return &TagController{
-		tr: tr,
+		tr:  tr,
+		grr: grr,
 	}
 }
 
@@ -67,3 +105,287 @@ func (tc *TagController) Save(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, tag)
 }
+
+// Find the tag of the :id path parameter, it writes the error response and returns false if the tag can not be found.
+func (tc *TagController) findTag(c *gin.Context) (model.Tag, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return model.Tag{}, false
+	}
+
+	tag, err := tc.tr.FindById(c, id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return tag, false
+	}
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return tag, false
+	}
+
+	return tag, true
+}
+
+func (tc *TagController) SaveAlias(c *gin.Context) {
+	tag, ok := tc.findTag(c)
+	if !ok {
+		return
+	}
+
+	var request CreateTagAliasRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alias := strings.TrimSpace(request.Name)
+
+	// An alias can not shadow another tag or alias.
+	if _, err := tc.tr.FindByName(c, strings.ToLower(alias)); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tag name already exists"})
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	if err := tc.tr.SaveAlias(c, tag, alias); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	tag.Aliases = append(tag.Aliases, alias)
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (tc *TagController) SetParent(c *gin.Context) {
+	tag, ok := tc.findTag(c)
+	if !ok {
+		return
+	}
+
+	var request SetTagParentRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.ParentId.Valid {
+		if _, err := tc.tr.FindById(c, int(request.ParentId.Int64)); errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent tag not found"})
+			return
+		} else if err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+			return
+		}
+	}
+
+	if err := tc.tr.SetParent(c, tag, request.ParentId); err != nil {
+		if errors.Is(err, model.ErrTagCycle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	tag.ParentId = request.ParentId
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Merge the :id tag into the target tag.
+func (tc *TagController) Merge(c *gin.Context) {
+	source, ok := tc.findTag(c)
+	if !ok {
+		return
+	}
+
+	var request MergeTagRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := tc.tr.FindById(c, request.TargetId)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target tag not found"})
+		return
+	}
+
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	if err := tc.tr.Merge(c, source, target); err != nil {
+		if errors.Is(err, model.ErrTagCycle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Can not merge a tag into itself or into one of its descendants"})
+			return
+		}
+
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+// Rename a tag and set its description, color and icon.
+func (tc *TagController) Update(c *gin.Context) {
+	tag, ok := tc.findTag(c)
+	if !ok {
+		return
+	}
+
+	var request UpdateTagRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(request.Name)
+
+	if name == "" ||
+		(request.Color.Valid && !model.IsValidTagColor(request.Color.String)) ||
+		utf8.RuneCountInString(request.Description.String) > maxTagDescriptionLength ||
+		utf8.RuneCountInString(request.Icon.String) > maxTagFieldLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request"})
+		return
+	}
+
+	// The new name can not be used by another tag or alias.
+	if existing, err := tc.tr.FindByName(c, strings.ToLower(name)); err == nil && existing.Id != tag.Id {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tag name already exists"})
+		return
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	tag.Name = name
+	tag.Description = request.Description
+	tag.Color = request.Color
+	tag.Icon = request.Icon
+
+	if err := tc.tr.Update(c, tag); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (tc *TagController) Delete(c *gin.Context) {
+	tag, ok := tc.findTag(c)
+	if !ok {
+		return
+	}
+
+	if err := tc.tr.Delete(c, tag); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Bind the repository ids of a bulk request, duplicated ids are removed.
+func bindBulkTagRepositories(c *gin.Context) ([]int, bool) {
+	var request BulkTagRepositoriesRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	ids := make([]int, 0, len(request.RepositoryIds))
+	seen := make(map[int]bool, len(request.RepositoryIds))
+
+	for _, id := range request.RepositoryIds {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) > maxBulkTagRepositories {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many repositories"})
+		return nil, false
+	}
+
+	return ids, true
+}
+
+// Assign the :id tag to all the given repositories in a single transaction.
+func (tc *TagController) AssignRepositories(c *gin.Context) {
+	tag, ok := tc.findTag(c)
+	if !ok {
+		return
+	}
+
+	ids, ok := bindBulkTagRepositories(c)
+	if !ok {
+		return
+	}
+
+	if err := tc.grr.AssignTag(c, tag, ids); err != nil {
+		if errors.Is(err, model.ErrRepositoryNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Repository not found"})
+			return
+		}
+
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag": tag, "repository_ids": ids})
+}
+
+// Remove the :id tag from all the given repositories in a single transaction.
+func (tc *TagController) UnassignRepositories(c *gin.Context) {
+	tag, ok := tc.findTag(c)
+	if !ok {
+		return
+	}
+
+	ids, ok := bindBulkTagRepositories(c)
+	if !ok {
+		return
+	}
+
+	if err := tc.grr.UnassignTag(c, tag, ids); err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag": tag, "repository_ids": ids})
+}
@@ -11,11 +11,37 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/liweiyi88/trendshift-backend/config"
 	"github.com/liweiyi88/trendshift-backend/jwttoken"
+	"github.com/liweiyi88/trendshift-backend/model"
 )
 
+const claimsKey = "claims"
+
 func JwtAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		err := verifyToken(c)
+		claims, err := verifyToken(c)
+
+		if err != nil {
+			slog.Error("authentication failed", slog.Any("error", err))
+			c.String(http.StatusUnauthorized, "Unauthorized")
+			c.Abort()
+			return
+		}
+
+		c.Set(claimsKey, claims)
+		c.Next()
+	}
+}
+
+// Authenticate the user when a token is passed, anonymous requests are let through without claims.
+// Handlers of public routes use it to allow more to authenticated users.
+func OptionalJwtAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Header.Get("Authorization") == "" {
+			c.Next()
+			return
+		}
+
+		claims, err := verifyToken(c)
 
 		if err != nil {
 			slog.Error("authentication failed", slog.Any("error", err))
@@ -24,17 +50,46 @@ func JwtAuth() gin.HandlerFunc {
 			return
 		}
 
+		c.Set(claimsKey, claims)
+		c.Next()
+	}
+}
+
+// Only let users with one of the roles through, it must be used after JwtAuth.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := Claims(c)
+
+		if !ok || !model.HasRole(claims.Role, roles...) {
+			slog.Error("authorization failed", slog.Any("roles", roles))
+			c.String(http.StatusForbidden, "Forbidden")
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
 
-func verifyToken(c *gin.Context) error {
+// Returns the claims of the authenticated user set by JwtAuth.
+func Claims(c *gin.Context) (*jwttoken.AppClaim, bool) {
+	value, ok := c.Get(claimsKey)
+	if !ok {
+		return nil, false
+	}
+
+	claims, ok := value.(*jwttoken.AppClaim)
+
+	return claims, ok
+}
+
+func verifyToken(c *gin.Context) (*jwttoken.AppClaim, error) {
 	authHeader := c.Request.Header.Get("Authorization")
 
 	bearerString := strings.Split(authHeader, " ")
 
 	if len(bearerString) != 2 {
-		return errors.New("incorrectly formatted authorization header")
+		return nil, errors.New("incorrectly formatted authorization header")
 	}
 
 	tokenString := bearerString[1]
@@ -42,14 +97,14 @@ func verifyToken(c *gin.Context) error {
 	token, err := jwttoken.NewTokenService(config.SignIngKey).Verify(tokenString)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, ok := token.Claims.(*jwttoken.AppClaim)
+	claims, ok := token.Claims.(*jwttoken.AppClaim)
 
 	if ok && token.Valid {
-		return nil
+		return claims, nil
 	} else {
-		return fmt.Errorf("invalid token string: %v", tokenString)
+		return nil, fmt.Errorf("invalid token string: %v", tokenString)
 	}
 }
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimiter counts the requests of each client in fixed windows, the counts are dropped when a window ends
+// so memory is bounded by the clients seen within a window.
+type rateLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	window      time.Duration
+	windowStart time.Time
+	counts      map[string]int
+}
+
+func (rl *rateLimiter) allow(client string, now time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if now.Sub(rl.windowStart) >= rl.window {
+		rl.windowStart = now.Truncate(rl.window)
+		rl.counts = make(map[string]int)
+	}
+
+	if rl.counts[client] >= rl.limit {
+		return false
+	}
+
+	rl.counts[client]++
+	return true
+}
+
+// Let each client, identified by its IP, through at most limit times per window.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	limiter := &rateLimiter{
+		limit:  limit,
+		window: window,
+		counts: make(map[string]int),
+	}
+
+	return func(c *gin.Context) {
+		if !limiter.allow(c.ClientIP(), time.Now()) {
+			slog.Warn("rate limit exceeded", slog.String("client", c.ClientIP()), slog.String("path", c.FullPath()))
+			c.Header("Retry-After", strconv.Itoa(int(window.Seconds())))
+			c.String(http.StatusTooManyRequests, "Too Many Requests")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
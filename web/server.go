@@ -16,37 +16,77 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/liweiyi88/trendshift-backend/config"
 	"github.com/liweiyi88/trendshift-backend/database"
+	"github.com/liweiyi88/trendshift-backend/github"
 	"github.com/liweiyi88/trendshift-backend/global"
+	"github.com/liweiyi88/trendshift-backend/model"
+	"github.com/liweiyi88/trendshift-backend/pageview"
+	"github.com/liweiyi88/trendshift-backend/queue"
+	"github.com/liweiyi88/trendshift-backend/search"
+	"github.com/liweiyi88/trendshift-backend/tracking"
+	"github.com/liweiyi88/trendshift-backend/trending"
 	"github.com/liweiyi88/trendshift-backend/web/controller"
 	"github.com/liweiyi88/trendshift-backend/web/middleware"
 )
 
+// Workers fetching the repositories and developers looked up before they are tracked.
+var onDemandQueueOptions = queue.Options{
+	Workers:      2,
+	PollInterval: queue.DefaultOptions.PollInterval,
+	LockTimeout:  queue.DefaultOptions.LockTimeout,
+}
+
+// Requests per client and minute to the lookup routes.
+const lookupRequestsPerMinute = 30
+
 type Controllers struct {
-	developerController  *controller.DeveloperController
-	repositoryController *controller.RepositoryController
-	tagController        *controller.TagController
-	securityController   *controller.SecurityController
-	statsController      *controller.StatsController
-	searchController     *controller.SearchController
-	engagementController *controller.RepositoryEngagementController
+	developerController    *controller.DeveloperController
+	repositoryController   *controller.RepositoryController
+	tagController          *controller.TagController
+	securityController     *controller.SecurityController
+	statsController        *controller.StatsController
+	searchController       *controller.SearchController
+	engagementController   *controller.RepositoryEngagementController
+	organizationController *controller.OrganizationController
+	submissionController   *controller.RepositorySubmissionController
+	entityStatusController *controller.EntityStatusController
 }
 
-func initControllers(repositories *global.Repositories) *Controllers {
+func initControllers(repositories *global.Repositories, tracker *tracking.Tracker, views *pageview.Counter) *Controllers {
 	return &Controllers{
-		developerController:  controller.NewDeveloperController(repositories.DeveloperRepo),
-		repositoryController: controller.NewRepositoryController(repositories.GhRepositoryRepo, repositories.RepositoryMonthlyInsightRepo),
-		tagController:        controller.NewTagController(repositories.TagRepo),
-		securityController:   controller.NewSecurityController(repositories.UserRepo),
-		statsController:      controller.NewStatsController(repositories.StatsRepo),
-		searchController:     controller.NewSearchController(),
-		engagementController: controller.NewRepositoryEngagementController(repositories.RepositoryMonthlyInsightRepo),
+		developerController:    controller.NewDeveloperController(repositories.DeveloperRepo, repositories.DeveloperSnapshotRepo, repositories.DeveloperProfileRepo, repositories.RepositoryContributorRepo, repositories.TrendingDeveloperRepo, repositories.JobRepo, views),
+		repositoryController:   controller.NewRepositoryController(repositories.GhRepositoryRepo, repositories.RepositoryMonthlyInsightRepo, repositories.RepositorySnapshotRepo, repositories.RepositoryContributorRepo, repositories.RepositorySimilarityRepo, repositories.TrendingRepositoryRepo, repositories.JobRepo, views, tracker),
+		tagController:          controller.NewTagController(repositories.TagRepo, repositories.GhRepositoryRepo),
+		securityController:     controller.NewSecurityController(repositories.UserRepo),
+		statsController:        controller.NewStatsController(repositories.StatsRepo),
+		searchController:       controller.NewSearchController(),
+		engagementController:   controller.NewRepositoryEngagementController(repositories.RepositoryMonthlyInsightRepo),
+		organizationController: controller.NewOrganizationController(repositories.OrganizationRepo),
+		submissionController:   controller.NewRepositorySubmissionController(tracker, repositories.RepositorySubmissionRepo),
+		entityStatusController: controller.NewEntityStatusController(repositories.EntityStatusRepo, repositories.JobRepo),
 	}
 }
 
 func setupRouter(ctx context.Context) (*gin.Engine, *sql.DB) {
 	db := database.GetInstance(ctx)
 	repositories := global.InitRepositories(db)
-	controllers := initControllers(repositories)
+
+	gh := github.NewClient(github.NewTokenPool(config.GitHubTokens))
+	githubFetcher := trending.NewGithubFetcher(gh, search.NewSearch(), *repositories)
+
+	onDemandQueue := queue.New(repositories.JobRepo, onDemandQueueOptions)
+	githubFetcher.Register(onDemandQueue)
+
+	go func() {
+		if err := onDemandQueue.Work(ctx); err != nil {
+			slog.Error("on-demand fetch queue stopped", slog.Any("error", err))
+		}
+	}()
+
+	views := pageview.NewCounter(repositories.PageViewRepo)
+	go views.Run(ctx)
+
+	tracker := tracking.NewTracker(gh, githubFetcher, *repositories)
+	controllers := initControllers(repositories, tracker, views)
 
 	gin.SetMode(config.GinMode)
 	router := gin.Default()
@@ -58,6 +98,11 @@ func setupRouter(ctx context.Context) (*gin.Engine, *sql.DB) {
 
 	router.UseRawPath = true
 
+	// Clients are rate limited and their page views counted by IP, a forwarded IP is only trusted from our proxies.
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		log.Fatalf("invalid trusted proxies: %v", err)
+	}
+
 	router.GET("/ping", func(c *gin.Context) {
 		c.String(http.StatusOK, "pong")
 	})
@@ -66,19 +111,60 @@ func setupRouter(ctx context.Context) (*gin.Engine, *sql.DB) {
 
 	router.POST("/api/search", controllers.searchController.Search)
 	router.GET("/api/trending-developers", controllers.developerController.GetTrendingDevelopers)
+	router.GET("/api/trending-developers/new", controllers.developerController.GetNewTrendingDevelopers)
 	router.GET("/api/trending-repositories", controllers.repositoryController.GetTrendingRepositories)
+	router.GET("/api/trending-repositories/new", controllers.repositoryController.GetNewTrendingRepositories)
+	router.GET("/api/trending-organizations", controllers.organizationController.GetTrendingOrganizations)
+	router.GET("/api/developers", controllers.developerController.List)
 	router.GET("/api/developers/:id", controllers.developerController.Get)
+	router.GET("/api/developers/:id/history", controllers.developerController.GetHistory)
+	router.GET("/api/developers/:id/trending-history", controllers.developerController.GetTrendingHistory)
 	router.GET("/api/repositories", controllers.repositoryController.List)
+	router.GET("/api/repositories/rising", controllers.repositoryController.GetRisingRepositories)
+	router.GET("/api/repositories/compare", controllers.repositoryController.Compare)
 	router.GET("/api/repositories/:id", controllers.repositoryController.Get)
+	router.GET("/api/repositories/:id/history", controllers.repositoryController.GetHistory)
+	router.GET("/api/repositories/:id/trending-history", controllers.repositoryController.GetTrendingHistory)
+	router.GET("/api/repositories/:id/related", controllers.repositoryController.GetRelated)
 	router.GET("/api/repositories/engagement/monthly/:metric", controllers.engagementController.List)
 	router.GET("/api/tags", controllers.tagController.List)
 	router.GET("/api/stats/trending-topics", controllers.statsController.GetTrendingTopicsStats)
+	router.GET("/api/stats/topics/share", controllers.statsController.GetTopicShares)
+	router.GET("/api/stats/topics/momentum", controllers.statsController.GetTopicMomentum)
+	router.GET("/api/stats/topics/top-repositories", controllers.statsController.GetTopicTopRepositories)
+	router.GET("/api/stats/languages", controllers.statsController.GetLanguageStats)
+
+	// Lookups by GitHub name may fetch from GitHub for authenticated users, they are throttled per client.
+	lookup := router.Group("/api")
+	lookup.Use(middleware.RateLimit(lookupRequestsPerMinute, time.Minute), middleware.OptionalJwtAuth())
+	lookup.GET("/developers/by-login/:login", controllers.developerController.GetByLogin)
+	lookup.GET("/repositories/by-name/:owner/:repo", controllers.repositoryController.GetByName)
 
 	// Protected routes.
 	auth := router.Group("/api")
 	auth.Use(middleware.JwtAuth())
 	auth.POST("/tags", controllers.tagController.Save)
 	auth.PUT("/repositories/:id/tags", controllers.repositoryController.SaveTags)
+	auth.POST("/repositories/track", controllers.submissionController.Track)
+
+	// Admin routes.
+	admin := auth.Group("")
+	admin.Use(middleware.RequireRole(model.RoleAdmin))
+	admin.PUT("/tags/:id", controllers.tagController.Update)
+	admin.DELETE("/tags/:id", controllers.tagController.Delete)
+	admin.POST("/tags/:id/repositories", controllers.tagController.AssignRepositories)
+	admin.DELETE("/tags/:id/repositories", controllers.tagController.UnassignRepositories)
+	admin.POST("/tags/:id/aliases", controllers.tagController.SaveAlias)
+	admin.PUT("/tags/:id/parent", controllers.tagController.SetParent)
+	admin.POST("/tags/:id/merge", controllers.tagController.Merge)
+	admin.GET("/repository-submissions", controllers.submissionController.List)
+	admin.POST("/repository-submissions/:id/approve", controllers.submissionController.Approve)
+	admin.POST("/repository-submissions/:id/reject", controllers.submissionController.Reject)
+	admin.GET("/entity-statuses", controllers.entityStatusController.List)
+	admin.POST("/repositories/:id/restore", controllers.entityStatusController.RestoreRepository)
+	admin.POST("/repositories/:id/purge", controllers.entityStatusController.PurgeRepository)
+	admin.POST("/developers/:id/restore", controllers.entityStatusController.RestoreDeveloper)
+	admin.POST("/developers/:id/purge", controllers.entityStatusController.PurgeDeveloper)
 
 	return router, db
 }